@@ -1,7 +1,15 @@
 package main
 
-import "github.com/fajritsaniy/golang-SHM/delivery"
+import (
+	"fmt"
+	"os"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/cli"
+)
 
 func main() {
-	delivery.NewServer().Run()
+	if err := cli.Run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
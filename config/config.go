@@ -2,17 +2,43 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
 	"github.com/joho/godotenv"
 )
 
 type ApiConfig struct {
 	ApiPort string
 	ApiHost string
+	// ShutdownTimeout is how long in-flight requests get to finish on SIGTERM
+	ShutdownTimeout time.Duration
+	// MetricsToken has to be sent as a bearer token to /metrics. Only DEV may
+	// leave it empty and scrape without one.
+	MetricsToken string
+	// RequestTimeout bounds every request, RouteTimeouts overrides it per
+	// "METHOD /route". Zero disables the deadline.
+	RequestTimeout time.Duration
+	RouteTimeouts  map[string]time.Duration
+	// DefaultLocale answers clients whose Accept-Language names no locale
+	// the API speaks
+	DefaultLocale string
+	// LegacyRoutes keeps serving the v1 routes at their old unversioned
+	// paths, marked deprecated since LegacyRoutesDeprecatedAt and going
+	// away at LegacyRoutesSunset
+	LegacyRoutes             bool
+	LegacyRoutesDeprecatedAt time.Time
+	LegacyRoutesSunset       time.Time
+	// EmployeeCollectionDeprecatedAt and VehicleImagePathDeprecatedAt date
+	// the v1 paths /employee and /vehicles/image/:id, which later versions
+	// replaced
+	EmployeeCollectionDeprecatedAt time.Time
+	VehicleImagePathDeprecatedAt   time.Time
 }
 type DbConfig struct {
 	Host     string
@@ -24,16 +50,65 @@ type DbConfig struct {
 
 type FileConfig struct {
 	LogFilePath    string
+	LogLevel       string
 	Env            string
 	UploadLocation string
+	// StorageDriver keeps uploads in UploadLocation (local), in memory or in
+	// the S3 bucket
+	StorageDriver string
+	S3            S3Config
+	// ImageURLSecret signs the image URLs handed out with vehicles, they
+	// work without a token for ImageURLLifeTime
+	ImageURLSecret   string
+	ImageURLLifeTime time.Duration
+	// uploaded images are rejected above ImageMaxSize bytes or
+	// ImageMaxPixels pixels
+	ImageMaxSize   int64
+	ImageMaxPixels int
+}
+
+// S3Config points at an S3 compatible object store, AWS or e.g. MinIO.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses objects as endpoint/bucket/key, which MinIO needs,
+	// instead of bucket.endpoint/key
+	PathStyle bool
 }
 
 type TokenConfig struct {
-	ApplicationName      string
-	JwtSignatureKey      string
-	JwtSigningMethod     *jwt.SigningMethodHMAC
-	AccessTokenLifeTime  time.Duration
-	RefreshTokenLifeTime time.Duration
+	ApplicationName       string
+	JwtSignatureKey       string
+	JwtSigningMethod      *jwt.SigningMethodHMAC
+	AccessTokenLifeTime   time.Duration
+	RefreshTokenLifeTime  time.Duration
+	PasswordResetLifeTime time.Duration
+}
+
+type NotifierConfig struct {
+	Driver   string
+	FilePath string
+}
+
+type LoginConfig struct {
+	MaxAttempts        int
+	LockoutDuration    time.Duration
+	MaxLockoutDuration time.Duration
+	IPMaxAttempts      int
+	IPWindow           time.Duration
+}
+
+type TracingConfig struct {
+	// Exporter is none, stdout, file or otlp
+	Exporter     string
+	FilePath     string
+	OTLPEndpoint string
+	OTLPInsecure bool
+	ServiceName  string
+	SampleRatio  float64
 }
 
 type Config struct {
@@ -41,6 +116,9 @@ type Config struct {
 	ApiConfig
 	FileConfig
 	TokenConfig
+	NotifierConfig
+	LoginConfig
+	TracingConfig
 }
 
 func (c *Config) ReadConfigFile() error {
@@ -57,15 +135,92 @@ func (c *Config) ReadConfigFile() error {
 		Password: os.Getenv("DB_PASSWORD"),
 	}
 
+	shutdownTimeout, err := envInt("SHUTDOWN_TIMEOUT", 15)
+	if err != nil {
+		return err
+	}
+	requestTimeout, err := envInt("REQUEST_TIMEOUT", 30)
+	if err != nil {
+		return err
+	}
+	routeTimeouts, err := parseRouteTimeouts(os.Getenv("ROUTE_TIMEOUTS"))
+	if err != nil {
+		return err
+	}
+	defaultLocale := os.Getenv("DEFAULT_LOCALE")
+	if defaultLocale == "" {
+		defaultLocale = i18n.English
+	}
+	if !i18n.IsSupported(defaultLocale) {
+		return fmt.Errorf("unsupported default locale %q, expected one of %s", defaultLocale, strings.Join(i18n.Supported, ", "))
+	}
+	legacyRoutesDeprecatedAt, err := envDate("LEGACY_ROUTES_DEPRECATED_AT")
+	if err != nil {
+		return err
+	}
+	legacyRoutesSunset, err := envDate("LEGACY_ROUTES_SUNSET")
+	if err != nil {
+		return err
+	}
+	employeeCollectionDeprecatedAt, err := envDate("EMPLOYEE_COLLECTION_DEPRECATED_AT")
+	if err != nil {
+		return err
+	}
+	vehicleImagePathDeprecatedAt, err := envDate("VEHICLE_IMAGE_PATH_DEPRECATED_AT")
+	if err != nil {
+		return err
+	}
 	c.ApiConfig = ApiConfig{
-		ApiHost: os.Getenv("API_HOST"),
-		ApiPort: os.Getenv("API_PORT"),
+		ApiHost:         os.Getenv("API_HOST"),
+		ApiPort:         os.Getenv("API_PORT"),
+		ShutdownTimeout: time.Duration(shutdownTimeout) * time.Second,
+		MetricsToken:    os.Getenv("METRICS_TOKEN"),
+		RequestTimeout:  time.Duration(requestTimeout) * time.Second,
+		RouteTimeouts:   routeTimeouts,
+		DefaultLocale:   defaultLocale,
+		// on until explicitly turned off
+		LegacyRoutes:             os.Getenv("LEGACY_ROUTES") != "false",
+		LegacyRoutesDeprecatedAt: legacyRoutesDeprecatedAt,
+		LegacyRoutesSunset:       legacyRoutesSunset,
+
+		EmployeeCollectionDeprecatedAt: employeeCollectionDeprecatedAt,
+		VehicleImagePathDeprecatedAt:   vehicleImagePathDeprecatedAt,
 	}
 
+	imageURLExpire, err := envInt("IMAGE_URL_EXPIRE", 60)
+	if err != nil {
+		return err
+	}
+	imageMaxSize, err := envInt("IMAGE_MAX_SIZE", 10)
+	if err != nil {
+		return err
+	}
+	imageMaxPixels, err := envInt("IMAGE_MAX_PIXELS", 25)
+	if err != nil {
+		return err
+	}
 	c.FileConfig = FileConfig{
 		Env:            os.Getenv("ENV"),
 		LogFilePath:    os.Getenv("REQUEST_FILE_PATH"),
+		LogLevel:       os.Getenv("LOG_LEVEL"),
 		UploadLocation: os.Getenv("UPLOAD_LOCATION"),
+		StorageDriver:  os.Getenv("STORAGE_DRIVER"),
+		S3: S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") != "false",
+		},
+		ImageURLSecret:   os.Getenv("IMAGE_URL_SECRET"),
+		ImageURLLifeTime: time.Duration(imageURLExpire) * time.Minute,
+		ImageMaxSize:     int64(imageMaxSize) << 20,
+		ImageMaxPixels:   imageMaxPixels * 1_000_000,
+	}
+	// the sales and revenue series are not public
+	if c.MetricsToken == "" && c.Env != "DEV" {
+		return errors.New("METRICS_TOKEN is required outside DEV")
 	}
 
 	tokenExpire, err := strconv.Atoi(os.Getenv("TOKEN_EXPIRE"))
@@ -73,23 +228,152 @@ func (c *Config) ReadConfigFile() error {
 	if err != nil {
 		return errors.New("failed to convert token expire")
 	}
+	// refresh tokens default to 7 days
+	refreshTokenExpire := 7 * 24 * 60
+	if os.Getenv("REFRESH_TOKEN_EXPIRE") != "" {
+		refreshTokenExpire, err = strconv.Atoi(os.Getenv("REFRESH_TOKEN_EXPIRE"))
+		if err != nil {
+			return errors.New("failed to convert refresh token expire")
+		}
+	}
+	// password reset tokens default to 30 minutes
+	passwordResetExpire := 30
+	if os.Getenv("PASSWORD_RESET_EXPIRE") != "" {
+		passwordResetExpire, err = strconv.Atoi(os.Getenv("PASSWORD_RESET_EXPIRE"))
+		if err != nil {
+			return errors.New("failed to convert password reset expire")
+		}
+	}
 	c.TokenConfig = TokenConfig{
-		ApplicationName:      os.Getenv("TOKEN_APP_NAME"),
-		JwtSignatureKey:      os.Getenv("TOKEN_SECRET"),
-		JwtSigningMethod:     jwt.SigningMethodHS256,
-		AccessTokenLifeTime:  accessTokenLifeTime,
-		RefreshTokenLifeTime: accessTokenLifeTime,
+		ApplicationName:       os.Getenv("TOKEN_APP_NAME"),
+		JwtSignatureKey:       os.Getenv("TOKEN_SECRET"),
+		JwtSigningMethod:      jwt.SigningMethodHS256,
+		AccessTokenLifeTime:   accessTokenLifeTime,
+		RefreshTokenLifeTime:  time.Duration(refreshTokenExpire) * time.Minute,
+		PasswordResetLifeTime: time.Duration(passwordResetExpire) * time.Minute,
 	}
 
-	if c.DbConfig.Host == "" || c.DbConfig.Port == "" || c.DbConfig.Name == "" ||
-		c.DbConfig.User == "" || c.DbConfig.Password == "" || c.ApiConfig.ApiHost == "" ||
-		c.ApiConfig.ApiPort == "" || c.FileConfig.Env == "" {
-		return errors.New("missing required environment variables")
+	// .env files written before signed image URLs have no secret of their own
+	if c.ImageURLSecret == "" {
+		c.ImageURLSecret = c.JwtSignatureKey
+	}
+
+	c.NotifierConfig = NotifierConfig{
+		Driver:   os.Getenv("NOTIFIER"),
+		FilePath: os.Getenv("NOTIFIER_FILE_PATH"),
+	}
+
+	// login protection defaults: lock after 5 failures for 15 minutes, doubling
+	// up to a day, and throttle an IP after 20 failures in 15 minutes
+	maxAttempts, err := envInt("LOGIN_MAX_ATTEMPTS", 5)
+	if err != nil {
+		return err
+	}
+	lockoutDuration, err := envInt("LOGIN_LOCKOUT_DURATION", 15)
+	if err != nil {
+		return err
+	}
+	maxLockoutDuration, err := envInt("LOGIN_MAX_LOCKOUT_DURATION", 24*60)
+	if err != nil {
+		return err
+	}
+	ipMaxAttempts, err := envInt("LOGIN_IP_MAX_ATTEMPTS", 20)
+	if err != nil {
+		return err
+	}
+	ipWindow, err := envInt("LOGIN_IP_WINDOW", 15)
+	if err != nil {
+		return err
+	}
+	c.LoginConfig = LoginConfig{
+		MaxAttempts:        maxAttempts,
+		LockoutDuration:    time.Duration(lockoutDuration) * time.Minute,
+		MaxLockoutDuration: time.Duration(maxLockoutDuration) * time.Minute,
+		IPMaxAttempts:      ipMaxAttempts,
+		IPWindow:           time.Duration(ipWindow) * time.Minute,
+	}
+
+	// tracing is off unless an exporter is chosen, and samples every request
+	sampleRatio := 1.0
+	if os.Getenv("TRACING_SAMPLE_RATIO") != "" {
+		sampleRatio, err = strconv.ParseFloat(os.Getenv("TRACING_SAMPLE_RATIO"), 64)
+		if err != nil || sampleRatio < 0 || sampleRatio > 1 {
+			return errors.New("failed to convert tracing sample ratio, expected 0 to 1")
+		}
+	}
+	c.TracingConfig = TracingConfig{
+		Exporter:     os.Getenv("TRACING_EXPORTER"),
+		FilePath:     os.Getenv("TRACING_FILE_PATH"),
+		OTLPEndpoint: os.Getenv("TRACING_OTLP_ENDPOINT"),
+		OTLPInsecure: os.Getenv("TRACING_OTLP_INSECURE") == "true",
+		ServiceName:  os.Getenv("TRACING_SERVICE_NAME"),
+		SampleRatio:  sampleRatio,
+	}
+	if c.TracingConfig.ServiceName == "" {
+		c.TracingConfig.ServiceName = "golang-shm"
+	}
+	if c.TracingConfig.Exporter == "file" && c.TracingConfig.FilePath == "" {
+		c.TracingConfig.FilePath = "TRACES.txt"
+	}
+
+	var missing []string
+	for _, key := range []string{"DB_HOST", "DB_PORT", "DB_NAME", "DB_USER", "DB_PASSWORD", "API_HOST", "API_PORT", "ENV"} {
+		if os.Getenv(key) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
 	}
 
 	return nil
 }
 
+func envInt(key string, fallback int) (int, error) {
+	if os.Getenv(key) == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert %s", strings.ToLower(key))
+	}
+	return value, nil
+}
+
+// envDate reads a date written as 2006-01-02, the zero time when unset.
+func envDate(key string) (time.Time, error) {
+	if os.Getenv(key) == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(time.DateOnly, os.Getenv(key))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to convert %s, expected YYYY-MM-DD", strings.ToLower(key))
+	}
+	return date, nil
+}
+
+// parseRouteTimeouts reads "METHOD /route=seconds" pairs separated by commas,
+// e.g. "POST /vehicles=120,GET /transactions=10". Routes are written the way
+// they are registered, with :params; without a version prefix they apply to
+// every version.
+func parseRouteTimeouts(value string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		route, seconds, found := strings.Cut(entry, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		timeout, err := strconv.Atoi(strings.TrimSpace(seconds))
+		if !found || !hasPath || err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid route timeout %q, expected METHOD /route=seconds", entry)
+		}
+		timeouts[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = time.Duration(timeout) * time.Second
+	}
+	return timeouts, nil
+}
+
 func NewConfig() (*Config, error) {
 	cfg := &Config{}
 	err := cfg.ReadConfigFile()
@@ -1,17 +1,36 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
+
 	"github.com/fajritsaniy/golang-SHM/delivery/api/response"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
 	"github.com/fajritsaniy/golang-SHM/model/dto"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
 	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/mitchellh/mapstructure"
 )
 
 type BaseApi struct{}
 
+// ParseRequestBody binds the JSON body into payload and runs its Validate
+// method when it has one. A body that cannot be decoded is a bad request, a
+// value of the wrong type or one that breaks a rule is reported per field.
 func (b *BaseApi) ParseRequestBody(c *gin.Context, payload interface{}) error {
 	if err := c.ShouldBindJSON(payload); err != nil {
-		return err
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return apperror.Validation(apperror.CodeValidation, "error.validation_failed", map[string]string{typeErr.Field: "validation.type_mismatch"}).
+				With(i18n.Args{"type": typeErr.Type.String()})
+		}
+		return apperror.BadRequest(apperror.CodeInvalidBody, "error.invalid_body").Wrap(err)
+	}
+	if validatable, ok := payload.(validation.Validatable); ok {
+		if err := validatable.Validate(); err != nil {
+			return apperror.FromValidation(err)
+		}
 	}
 	return nil
 }
@@ -36,10 +55,13 @@ func (b *BaseApi) NewSuccessPageResponse(c *gin.Context, data []interface{}, res
 	response.SendPageResponse(c, data, responseType, paging)
 }
 
-func (b *BaseApi) NewErrorErrorResponse(c *gin.Context, code int, errorMessage string) {
-	response.SendErrorResponse(c, code, errorMessage)
+func (b *BaseApi) NewSuccessFacetedPageResponse(c *gin.Context, data []interface{}, responseType string, paging dto.Paging, facets interface{}) {
+	response.SendFacetedPageResponse(c, data, responseType, paging, facets)
 }
 
-func (b *BaseApi) NewSuccessFileResponse(c *gin.Context, fileName string, responseType string) {
-	response.SendFileResponse(c, fileName, responseType)
+// NewErrorResponse hands err to ErrorMiddleware, which renders it once the
+// handler returns.
+func (b *BaseApi) NewErrorResponse(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
 }
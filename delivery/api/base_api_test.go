@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api/response"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BaseApiTestSuite struct {
+	suite.Suite
+	api BaseApi
+}
+
+func (suite *BaseApiTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+}
+
+// post parses body into a vehicle and answers with the error the way the
+// error middleware does.
+func (suite *BaseApiTestSuite) post(body string) (int, response.ErrorResponse) {
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/vehicles", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var vehicle model.Vehicle
+	if err := suite.api.ParseRequestBody(c, &vehicle); err != nil {
+		response.SendErrorResponse(c, err)
+	}
+	var errorResponse response.ErrorResponse
+	if recorder.Body.Len() > 0 {
+		assert.NoError(suite.T(), json.Unmarshal(recorder.Body.Bytes(), &errorResponse))
+	}
+	return recorder.Code, errorResponse
+}
+
+func (suite *BaseApiTestSuite) TestParseRequestBodyTypeMismatchFail() {
+	code, body := suite.post(`{"brandId": "b1", "model": "Avanza", "productionYear": "2020"}`)
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, code)
+	assert.Equal(suite.T(), map[string]string{"productionYear": "must be a int"}, body.Error.Fields)
+}
+
+func (suite *BaseApiTestSuite) TestParseRequestBodyMalformedFail() {
+	code, _ := suite.post(`{"brandId": `)
+	assert.Equal(suite.T(), http.StatusBadRequest, code)
+}
+
+func TestBaseApiTestSuite(t *testing.T) {
+	suite.Run(t, new(BaseApiTestSuite))
+}
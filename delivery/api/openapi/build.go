@@ -0,0 +1,371 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api"
+	"github.com/fajritsaniy/golang-SHM/delivery/api/response"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	bearerAuth   = "bearerAuth"
+	metricsToken = "metricsToken"
+	jsonType     = "application/json"
+)
+
+// route documents one gin route.
+type route struct {
+	tag         string
+	summary     string
+	description string
+	// security names the scheme guarding the route, empty for public routes
+	security string
+	roles    []string
+	query    []*Parameter
+	body     any
+	// optionalBody is a JSON body the route accepts but does not need
+	optionalBody any
+	form         *Schema
+	// status defaults to 200
+	status int
+	reply  reply
+	// root routes are served outside the versions, e.g. the health checks
+	root bool
+	// since and until bound the versions serving the route, zero for the
+	// first and the last
+	since api.Version
+	until api.Version
+	// deprecated tells clients what to use instead
+	deprecated string
+	// signed routes also take the expires and signature of a signed URL in
+	// place of the token
+	signed bool
+}
+
+// servedIn reports whether the route belongs in version, where version 0 is
+// the root: the root routes and the unversioned aliases of the v1 routes.
+func (r route) servedIn(version api.Version) bool {
+	if r.root {
+		return version == 0
+	}
+	if version == 0 {
+		return r.since <= api.V1
+	}
+	return r.since <= version && (r.until == 0 || version <= r.until)
+}
+
+type replyKind int
+
+const (
+	replyNone replyKind = iota
+	replySingle
+	replyPaged
+	replyFaceted
+	replyRaw
+	replyText
+	replyBinary
+)
+
+type reply struct {
+	kind      replyKind
+	data      any
+	facets    any
+	mediaType string
+}
+
+// single is data wrapped in a response.SingleResponse.
+func single(data any) reply {
+	return reply{kind: replySingle, data: data}
+}
+
+// paged is a list of data wrapped in a response.PagedResponse.
+func paged(data any) reply {
+	return reply{kind: replyPaged, data: data}
+}
+
+// faceted is a list of data with its facets wrapped in a
+// response.FacetedPagedResponse.
+func faceted(data any, facets any) reply {
+	return reply{kind: replyFaceted, data: data, facets: facets}
+}
+
+// raw is a JSON body sent as is.
+func raw(body any) reply {
+	return reply{kind: replyRaw, data: body}
+}
+
+func text(mediaType string) reply {
+	return reply{kind: replyText, mediaType: mediaType}
+}
+
+// binary is a file of mediaType, e.g. image/*.
+func binary(mediaType string) reply {
+	return reply{kind: replyBinary, mediaType: mediaType}
+}
+
+// Build documents routes, usually engine.Routes() once every controller is
+// registered. Routes are looked up in the catalog without their version
+// prefix, the unversioned aliases of v1 routes are documented as deprecated.
+// The returned error names the routes missing from the catalog and the
+// catalog entries no route matches; the document is complete apart from
+// those.
+func Build(routes gin.RoutesInfo) (*Document, error) {
+	s := newSchemas(enums)
+	document := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "Showroom Management API",
+			Description: "Messages are rendered in the language negotiated from Accept-Language.",
+			Version:     "1.0",
+		},
+		Tags:  tags,
+		Paths: map[string]PathItem{},
+	}
+
+	documented := make(map[string]bool, len(catalog))
+	var missing []string
+	for _, info := range routes {
+		version, path := api.SplitVersion(info.Path)
+		key := info.Method + " " + path
+		entry, ok := catalog[key]
+		if !ok || !entry.servedIn(version) {
+			missing = append(missing, info.Method+" "+info.Path)
+			continue
+		}
+		documented[key] = true
+		specPath := specPath(info.Path)
+		if document.Paths[specPath] == nil {
+			document.Paths[specPath] = PathItem{}
+		}
+		document.Paths[specPath][strings.ToLower(info.Method)] = entry.operation(info.Method, path, version, s)
+	}
+	var unrouted []string
+	for key := range catalog {
+		if !documented[key] {
+			unrouted = append(unrouted, key)
+		}
+	}
+
+	document.Components = Components{
+		Responses:       errorResponses(s),
+		SecuritySchemes: securitySchemes,
+		Schemas:         s.components,
+	}
+
+	var err error
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		err = errors.Join(err, fmt.Errorf("routes missing from the API docs: %s", strings.Join(missing, ", ")))
+	}
+	if len(unrouted) > 0 {
+		sort.Strings(unrouted)
+		err = errors.Join(err, fmt.Errorf("documented routes that are not registered: %s", strings.Join(unrouted, ", ")))
+	}
+	return document, err
+}
+
+var securitySchemes = map[string]*SecurityScheme{
+	bearerAuth: {
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+		Description:  "Access token from /login or /token/refresh.",
+	},
+	metricsToken: {
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "Static scrape token, configured with METRICS_TOKEN.",
+	},
+}
+
+func errorResponses(s *schemas) map[string]*Response {
+	errorResponse := func(description string) *Response {
+		return &Response{Description: description, Content: jsonContent(s.of(response.ErrorResponse{}))}
+	}
+	return map[string]*Response{
+		"BadRequest":       errorResponse("The body could not be read."),
+		"ValidationFailed": errorResponse("Some values are invalid, error.fields tells which."),
+		"Unauthorized":     errorResponse("The token is missing, invalid or revoked."),
+		"Forbidden":        errorResponse("The role of the caller is not allowed, the password has to be changed first, or a signed URL is invalid or expired."),
+		"NotFound":         errorResponse("No record has this ID."),
+		"Error":            errorResponse("Any other error, error.code identifies it."),
+	}
+}
+
+func errorRef(name string) *Response {
+	return &Response{Ref: "#/components/responses/" + name}
+}
+
+// operation documents the route at path within version.
+func (r route) operation(method string, path string, version api.Version, s *schemas) *Operation {
+	operation := &Operation{
+		Tags:        []string{r.tag},
+		Summary:     r.summary,
+		Description: r.description,
+		OperationID: operationID(method, path),
+		Responses:   map[string]*Response{"default": errorRef("Error")},
+		Deprecated:  r.deprecated != "",
+	}
+	if len(r.roles) > 0 {
+		roles := "Roles: " + strings.Join(r.roles, ", ") + "."
+		operation.Description = strings.TrimSpace(roles + " " + operation.Description)
+	}
+	switch {
+	case version > 0:
+		operation.OperationID += strings.ToUpper(version.String())
+	case !r.root:
+		operation.OperationID += "Legacy"
+		operation.Deprecated = true
+		operation.Description = strings.TrimSpace(operation.Description + " Deprecated alias of " + api.V1.Prefix() + path + ".")
+	}
+	if r.deprecated != "" {
+		operation.Description = strings.TrimSpace(operation.Description + " Deprecated: " + r.deprecated)
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			operation.Parameters = append(operation.Parameters, &Parameter{
+				Name:     segment[1:],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+			operation.Responses["404"] = errorRef("NotFound")
+		}
+	}
+	operation.Parameters = append(operation.Parameters, r.query...)
+	if r.signed {
+		operation.Parameters = append(operation.Parameters, signedQuery...)
+	}
+	operation.Parameters = append(operation.Parameters, &Parameter{
+		Name:        "Accept-Language",
+		In:          "header",
+		Description: "Language of the messages in the response.",
+		Schema:      &Schema{Type: "string", Enum: languages()},
+	})
+	if len(r.query) > 0 {
+		operation.Responses["422"] = errorRef("ValidationFailed")
+	}
+
+	switch {
+	case r.body != nil:
+		operation.RequestBody = &RequestBody{Required: true, Content: jsonContent(s.of(r.body))}
+	case r.optionalBody != nil:
+		operation.RequestBody = &RequestBody{Content: jsonContent(s.of(r.optionalBody))}
+	case r.form != nil:
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"multipart/form-data": {Schema: r.form}},
+		}
+	}
+	if operation.RequestBody != nil {
+		operation.Responses["400"] = errorRef("BadRequest")
+		operation.Responses["422"] = errorRef("ValidationFailed")
+	}
+
+	if r.security != "" {
+		operation.Security = []map[string][]string{{r.security: {}}}
+		operation.Responses["401"] = errorRef("Unauthorized")
+		if r.security == bearerAuth {
+			operation.Responses["403"] = errorRef("Forbidden")
+		}
+		if r.signed {
+			// no scheme describes the query, it is documented as parameters
+			operation.Security = append(operation.Security, map[string][]string{})
+		}
+	}
+
+	status := r.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := r.reply.response(status, s)
+	if operation.Deprecated {
+		success.Headers = deprecationHeaders
+	}
+	operation.Responses[strconv.Itoa(status)] = success
+	return operation
+}
+
+var deprecationHeaders = map[string]*Header{
+	"Deprecation": {Description: "When the route was deprecated, as @unix-seconds.", Schema: &Schema{Type: "string"}},
+	"Sunset":      {Description: "When the route goes away, if planned.", Schema: &Schema{Type: "string"}},
+	"Link":        {Description: "The successor-version of the route.", Schema: &Schema{Type: "string"}},
+}
+
+func (r reply) response(status int, s *schemas) *Response {
+	success := &Response{Description: http.StatusText(status)}
+	switch r.kind {
+	case replySingle:
+		success.Content = jsonContent(envelope(s.of(response.SingleResponse{}), s.of(r.data)))
+	case replyPaged:
+		success.Content = jsonContent(envelope(s.of(response.PagedResponse{}), &Schema{Type: "array", Items: s.of(r.data)}))
+	case replyFaceted:
+		page := envelope(s.of(response.FacetedPagedResponse{}), &Schema{Type: "array", Items: s.of(r.data)})
+		page.AllOf[1].Properties["facets"] = s.of(r.facets)
+		success.Content = jsonContent(page)
+	case replyRaw:
+		success.Content = jsonContent(s.of(r.data))
+	case replyText:
+		success.Content = map[string]*MediaType{r.mediaType: {Schema: &Schema{Type: "string"}}}
+	case replyBinary:
+		success.Content = map[string]*MediaType{r.mediaType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+	}
+	return success
+}
+
+// envelope narrows the data of a response envelope to data.
+func envelope(wrapper *Schema, data *Schema) *Schema {
+	return &Schema{AllOf: []*Schema{
+		wrapper,
+		{Type: "object", Properties: map[string]*Schema{"data": data}},
+	}}
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{jsonType: {Schema: schema}}
+}
+
+func languages() []any {
+	languages := make([]any, len(i18n.Supported))
+	for i, locale := range i18n.Supported {
+		languages[i] = locale
+	}
+	return languages
+}
+
+// specPath turns gin parameters (:id, *path) into OpenAPI ones ({id}).
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operationID derives an ID such as getVehiclesById from the route.
+func operationID(method string, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			id += "By"
+			segment = segment[1:]
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '.' || r == '_' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
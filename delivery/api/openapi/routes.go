@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api"
+	"github.com/fajritsaniy/golang-SHM/delivery/api/response"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+)
+
+const (
+	tagAuth         = "Auth"
+	tagUsers        = "Users"
+	tagVehicles     = "Vehicles"
+	tagBrands       = "Brands"
+	tagCustomers    = "Customers"
+	tagEmployees    = "Employees"
+	tagTransactions = "Transactions"
+	tagAudit        = "Audit"
+	tagOperations   = "Operations"
+)
+
+var tags = []Tag{
+	{Name: tagAuth, Description: "Logging in and managing the own password."},
+	{Name: tagUsers, Description: "User administration."},
+	{Name: tagVehicles},
+	{Name: tagBrands},
+	{Name: tagCustomers},
+	{Name: tagEmployees},
+	{Name: tagTransactions},
+	{Name: tagAudit, Description: "Changes made to the stored records."},
+	{Name: tagOperations, Description: "Health checks, metrics and these docs."},
+}
+
+var (
+	admin      = []string{model.RoleAdmin}
+	management = []string{model.RoleAdmin, model.RoleManager}
+	staff      = []string{model.RoleAdmin, model.RoleManager, model.RoleSales}
+	customer   = []string{model.RoleCustomer}
+)
+
+// enums lists the allowed values of string fields, keyed "Type.jsonField".
+var enums = map[string][]any{
+	"Vehicle.status":      {model.VehicleStatusNew, model.VehicleStatusUsed},
+	"Transaction.type":    {model.TransactionTypeOnline, model.TransactionTypeOffline},
+	"UserCredential.role": {model.RoleAdmin, model.RoleManager, model.RoleSales, model.RoleCustomer},
+	"AuditLog.action":     {model.AuditActionCreate, model.AuditActionUpdate, model.AuditActionDelete},
+	"SecurityEvent.event": {model.SecurityEventLockout, model.SecurityEventUnlock, model.SecurityEventIPThrottle, model.SecurityEventPasswordSet},
+	"HealthReport.status": {dto.HealthStatusUp, dto.HealthStatusDown},
+}
+
+// pagingQuery are the parameters read by common.ValidateRequestQueryParams.
+var pagingQuery = []*Parameter{
+	{Name: "page", In: "query", Description: "Page to return, starting at 1.", Schema: &Schema{Type: "integer", Default: 1, Minimum: minimum(1)}},
+	{Name: "limit", In: "query", Description: "Rows per page.", Schema: &Schema{Type: "integer", Default: 5, Minimum: minimum(1)}},
+	{Name: "order", In: "query", Description: "Column to order by, id when empty.", Schema: &Schema{Type: "string"}},
+	{Name: "sort", In: "query", Description: "Sort direction.", Schema: &Schema{Type: "string", Default: "ASC", Enum: []any{"ASC", "DESC"}}},
+}
+
+var auditQuery = withQuery(pagingQuery,
+	&Parameter{Name: "entity", In: "query", Description: "Table of the changed records, e.g. mst_vehicle.", Schema: &Schema{Type: "string"}},
+	&Parameter{Name: "entityId", In: "query", Description: "ID of the changed record.", Schema: &Schema{Type: "string"}},
+	&Parameter{Name: "actor", In: "query", Description: "User who made the change.", Schema: &Schema{Type: "string"}},
+	&Parameter{Name: "from", In: "query", Description: "Earliest change, an RFC 3339 timestamp or a date (2006-01-02).", Schema: &Schema{Type: "string"}},
+	&Parameter{Name: "to", In: "query", Description: "Latest change, an RFC 3339 timestamp or a date (2006-01-02).", Schema: &Schema{Type: "string"}},
+)
+
+var vehicleQuery = withQuery(pagingQuery,
+	&Parameter{Name: "q", In: "query", Description: "Words to find in the brand name, model or colour, each of them has to match. Without an order the best matches come first.", Schema: &Schema{Type: "string"}},
+	&Parameter{Name: "brandId", In: "query", Description: "Brand of the vehicles.", Schema: &Schema{Type: "string"}},
+	&Parameter{Name: "status", In: "query", Description: "New (baru) or used (bekas) vehicles.", Schema: &Schema{Type: "string", Enum: []any{"baru", "bekas"}}},
+	&Parameter{Name: "automatic", In: "query", Description: "Automatic (true) or manual (false) transmission.", Schema: &Schema{Type: "boolean"}},
+	&Parameter{Name: "color", In: "query", Description: "Colour, ignoring case.", Schema: &Schema{Type: "string"}},
+	&Parameter{Name: "minYear", In: "query", Description: "Earliest production year.", Schema: &Schema{Type: "integer", Minimum: minimum(1)}},
+	&Parameter{Name: "maxYear", In: "query", Description: "Latest production year.", Schema: &Schema{Type: "integer", Minimum: minimum(1)}},
+	&Parameter{Name: "minPrice", In: "query", Description: "Lowest sale price.", Schema: &Schema{Type: "integer", Minimum: minimum(1)}},
+	&Parameter{Name: "maxPrice", In: "query", Description: "Highest sale price.", Schema: &Schema{Type: "integer", Minimum: minimum(1)}},
+	&Parameter{Name: "inStock", In: "query", Description: "Only vehicles in stock.", Schema: &Schema{Type: "boolean"}},
+)
+
+// signedQuery authorizes a request in place of the token, taken from a
+// signed URL such as Vehicle.urlPath.
+var signedQuery = []*Parameter{
+	{Name: "expires", In: "query", Description: "Expiry of the signed URL, in unix seconds.", Schema: &Schema{Type: "integer"}},
+	{Name: "signature", In: "query", Description: "Signature of the signed URL.", Schema: &Schema{Type: "string"}},
+}
+
+var vehicleForm = &Schema{
+	Type:     "object",
+	Required: []string{"vehicle", "image"},
+	Properties: map[string]*Schema{
+		"vehicle": {Type: "string", Description: "The vehicle as JSON."},
+		"image":   {Type: "string", Format: "binary", Description: "Picture of the vehicle, " + imageUpload},
+	},
+}
+
+var vehicleImagesForm = &Schema{
+	Type:     "object",
+	Required: []string{"images"},
+	Properties: map[string]*Schema{
+		"images":   {Type: "array", Items: &Schema{Type: "string", Format: "binary"}, Description: "Pictures to append to the gallery, each " + imageUpload},
+		"captions": {Type: "array", Items: &Schema{Type: "string"}, Description: "Captions of the pictures, in the same order."},
+	},
+}
+
+// imageUpload describes the checks on an uploaded picture.
+const imageUpload = "a JPEG, PNG or WebP file within the configured size limits. Its metadata (EXIF, XMP) is removed and thumbnail, medium and large renditions are rendered from it."
+
+const imageDescription = "Supports conditional requests (ETag, Last-Modified) and byte ranges."
+
+const pendingPasswordChange = "Allowed while a password change is pending."
+
+// catalog documents every route, keyed "METHOD /gin/path" without the version
+// prefix. Build fails for routes without an entry and for entries without a
+// route.
+var catalog = map[string]route{
+	"POST /login":           {tag: tagAuth, summary: "Log in", body: dto.LoginRequest{}, status: http.StatusCreated, reply: raw(response.TokenResponse{})},
+	"POST /token/refresh":   {tag: tagAuth, summary: "Exchange a refresh token for a new token pair", body: dto.RefreshTokenRequest{}, status: http.StatusCreated, reply: raw(response.TokenResponse{})},
+	"POST /logout":          {tag: tagAuth, summary: "Revoke the access token and, if given, the refresh token", description: pendingPasswordChange, security: bearerAuth, optionalBody: dto.RefreshTokenRequest{}, reply: raw(response.MessageResponse{})},
+	"POST /password/change": {tag: tagAuth, summary: "Change the own password", description: pendingPasswordChange, security: bearerAuth, body: dto.ChangePasswordRequest{}, reply: raw(response.TokenResponse{})},
+	"POST /password/forgot": {tag: tagAuth, summary: "Send a password reset link", description: "Answers the same whether or not the user exists.", body: dto.ForgotPasswordRequest{}, status: http.StatusAccepted, reply: raw(response.MessageResponse{})},
+	"POST /password/reset":  {tag: tagAuth, summary: "Set a new password with a reset token", body: dto.ResetPasswordRequest{}, reply: raw(response.MessageResponse{})},
+	"POST /register":        {tag: tagAuth, summary: "Register a customer account", description: "Always creates a new customer, a request with an id is rejected.", body: model.UserCredential{}, status: http.StatusCreated, reply: raw(response.MessageResponse{})},
+
+	"POST /activation":           {tag: tagUsers, summary: "Activate or deactivate a user", security: bearerAuth, roles: admin, body: model.UserCredential{}, status: http.StatusCreated, reply: raw(response.MessageResponse{})},
+	"PUT /users/:id/username":    {tag: tagUsers, summary: "Change the username of a user", description: "Administrators may rename any user, everyone else only their own account. Passwords change through /password/change.", security: bearerAuth, body: dto.UpdateUsernameRequest{}, reply: raw(response.MessageResponse{})},
+	"PUT /users/role":            {tag: tagUsers, summary: "Change the role of a user", security: bearerAuth, roles: admin, body: model.UserCredential{}, reply: raw(response.MessageResponse{})},
+	"POST /users/unlock":         {tag: tagUsers, summary: "Unlock a locked out user", security: bearerAuth, roles: admin, body: model.UserCredential{}, reply: raw(response.MessageResponse{})},
+	"GET /users/security-events": {tag: tagUsers, summary: "List lockouts, unlocks and throttled logins", security: bearerAuth, roles: admin, query: pagingQuery, reply: paged(model.SecurityEvent{})},
+
+	"GET /vehicles":                             {tag: tagVehicles, summary: "List and search vehicles", description: "Facets count the matching vehicles per brand, status, transmission and colour. Each facet applies every filter but its own.", query: vehicleQuery, reply: faceted(model.Vehicle{}, dto.VehicleFacets{})},
+	"POST /vehicles":                            {tag: tagVehicles, summary: "Add a vehicle with its image", security: bearerAuth, roles: management, form: vehicleForm, reply: single(model.Vehicle{})},
+	"PUT /vehicles":                             {tag: tagVehicles, summary: "Update a vehicle", description: "The version must be the one last read, a stale version is rejected with 409.", security: bearerAuth, roles: management, body: model.Vehicle{}, reply: single(model.Vehicle{})},
+	"GET /vehicles/:id":                         {tag: tagVehicles, summary: "Get a vehicle", reply: single(model.Vehicle{})},
+	"GET /vehicles/:id/image":                   {tag: tagVehicles, summary: "Get the primary image of a vehicle", description: imageDescription, security: bearerAuth, signed: true, reply: binary("image/*")},
+	"GET /vehicles/image/:id":                   {tag: tagVehicles, summary: "Get the primary image of a vehicle", description: imageDescription, security: bearerAuth, reply: binary("image/*"), until: api.V1, deprecated: "moved to /vehicles/{id}/image."},
+	"GET /vehicles/:id/images":                  {tag: tagVehicles, summary: "List the gallery of a vehicle", reply: single([]model.VehicleImage{})},
+	"POST /vehicles/:id/images":                 {tag: tagVehicles, summary: "Add images to the gallery of a vehicle", description: "The first image of a vehicle becomes its primary image.", security: bearerAuth, roles: management, form: vehicleImagesForm, reply: single([]model.VehicleImage{})},
+	"PUT /vehicles/:id/images/order":            {tag: tagVehicles, summary: "Reorder the gallery of a vehicle", description: "Lists every image ID of the vehicle in the new order.", security: bearerAuth, roles: management, body: dto.VehicleImageOrderRequest{}, reply: single([]model.VehicleImage{})},
+	"GET /vehicles/:id/images/:imageId":         {tag: tagVehicles, summary: "Get an image of a vehicle", description: imageDescription, security: bearerAuth, signed: true, reply: binary("image/*")},
+	"GET /vehicles/:id/images/:imageId/:size":   {tag: tagVehicles, summary: "Get a rendition of a vehicle image", description: "Size is thumbnail (160 px), medium (640 px) or large (1280 px) on the longer side. Images smaller than the size are served as uploaded. " + imageDescription, security: bearerAuth, signed: true, reply: binary("image/*")},
+	"PUT /vehicles/:id/images/:imageId/primary": {tag: tagVehicles, summary: "Make an image the primary image of a vehicle", security: bearerAuth, roles: management, reply: single([]model.VehicleImage{})},
+	"DELETE /vehicles/:id/images/:imageId":      {tag: tagVehicles, summary: "Delete an image of a vehicle", description: "The next image in order takes over as primary.", security: bearerAuth, roles: management, status: http.StatusNoContent},
+	"DELETE /vehicles/:id":                      {tag: tagVehicles, summary: "Delete a vehicle", security: bearerAuth, roles: management, status: http.StatusNoContent},
+
+	"GET /brands":        {tag: tagBrands, summary: "List brands", reply: paged(model.Brand{})},
+	"GET /brands/:id":    {tag: tagBrands, summary: "Get a brand", reply: single(model.Brand{})},
+	"POST /brands":       {tag: tagBrands, summary: "Add a brand", security: bearerAuth, roles: management, body: model.Brand{}, reply: single(model.Brand{})},
+	"PUT /brands":        {tag: tagBrands, summary: "Update a brand", security: bearerAuth, roles: management, body: model.Brand{}, reply: single(model.Brand{})},
+	"DELETE /brands/:id": {tag: tagBrands, summary: "Delete a brand", security: bearerAuth, roles: admin, status: http.StatusNoContent},
+
+	"GET /customers":             {tag: tagCustomers, summary: "List customers", security: bearerAuth, roles: staff, reply: paged(model.Customer{})},
+	"GET /customers/me":          {tag: tagCustomers, summary: "Get the customer of the caller", security: bearerAuth, roles: customer, reply: single(model.Customer{})},
+	"GET /customers/me/vehicles": {tag: tagCustomers, summary: "List the vehicles of the caller", security: bearerAuth, roles: customer, reply: paged(model.Vehicle{})},
+	"GET /customers/:id":         {tag: tagCustomers, summary: "Get a customer", security: bearerAuth, roles: staff, reply: single(model.Customer{})},
+	"POST /customers":            {tag: tagCustomers, summary: "Add a customer", security: bearerAuth, roles: staff, body: model.Customer{}, reply: single(model.Customer{})},
+	"PUT /customers":             {tag: tagCustomers, summary: "Update a customer", security: bearerAuth, roles: staff, body: model.Customer{}, reply: single(model.Customer{})},
+	"DELETE /customers/:id":      {tag: tagCustomers, summary: "Delete a customer", security: bearerAuth, roles: management, status: http.StatusNoContent},
+
+	"GET /employee":         {tag: tagEmployees, summary: "List employees", security: bearerAuth, roles: management, until: api.V1, deprecated: "moved to /employees in v2.", reply: paged(model.Employee{})},
+	"GET /employees":        {tag: tagEmployees, summary: "List employees", security: bearerAuth, roles: management, since: api.V2, reply: paged(model.Employee{})},
+	"POST /employees":       {tag: tagEmployees, summary: "Add an employee", security: bearerAuth, roles: admin, since: api.V2, body: model.Employee{}, reply: single(model.Employee{})},
+	"PUT /employees":        {tag: tagEmployees, summary: "Update an employee", security: bearerAuth, roles: admin, since: api.V2, body: model.Employee{}, reply: single(model.Employee{})},
+	"GET /employees/me":     {tag: tagEmployees, summary: "Get the employee of the caller", security: bearerAuth, roles: staff, reply: single(model.Employee{})},
+	"GET /employees/:id":    {tag: tagEmployees, summary: "Get an employee", security: bearerAuth, roles: management, reply: single(model.Employee{})},
+	"POST /employee":        {tag: tagEmployees, summary: "Add an employee", security: bearerAuth, roles: admin, body: model.Employee{}, until: api.V1, deprecated: "moved to /employees in v2.", reply: single(model.Employee{})},
+	"PUT /employee":         {tag: tagEmployees, summary: "Update an employee", security: bearerAuth, roles: admin, body: model.Employee{}, until: api.V1, deprecated: "moved to /employees in v2.", reply: single(model.Employee{})},
+	"DELETE /employees/:id": {tag: tagEmployees, summary: "Delete an employee", security: bearerAuth, roles: admin, status: http.StatusNoContent},
+
+	"GET /transactions":     {tag: tagTransactions, summary: "List transactions", security: bearerAuth, roles: management, reply: paged(model.Transaction{})},
+	"GET /transactions/:id": {tag: tagTransactions, summary: "Get a transaction", security: bearerAuth, roles: staff, reply: single(model.Transaction{})},
+	"POST /transactions":    {tag: tagTransactions, summary: "Sell a vehicle", description: "The seller is the employee of the caller, the stock of the vehicle goes down by qty.", security: bearerAuth, roles: staff, body: model.Transaction{}, reply: single(model.Transaction{})},
+
+	"GET /audit": {tag: tagAudit, summary: "List the audit trail, newest first", security: bearerAuth, roles: admin, query: auditQuery, reply: paged(model.AuditLog{})},
+
+	"GET /healthz":      {tag: tagOperations, root: true, summary: "Liveness probe", reply: single(dto.HealthReport{})},
+	"GET /readyz":       {tag: tagOperations, root: true, summary: "Readiness probe", description: "Answers 503 with the failing checks while a dependency is down or the server is draining.", reply: single(dto.HealthReport{})},
+	"GET /metrics":      {tag: tagOperations, root: true, summary: "Prometheus metrics", description: "Needs the scrape token configured with METRICS_TOKEN, which only DEV may leave empty.", security: metricsToken, reply: text("text/plain")},
+	"GET /openapi.json": {tag: tagOperations, root: true, summary: "This document", reply: raw(map[string]any{})},
+	"GET /docs":         {tag: tagOperations, root: true, summary: "Browsable API docs", reply: text("text/html")},
+}
+
+func withQuery(query []*Parameter, extra ...*Parameter) []*Parameter {
+	return append(append([]*Parameter{}, query...), extra...)
+}
+
+func minimum(value float64) *float64 {
+	return &value
+}
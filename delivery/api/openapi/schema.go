@@ -0,0 +1,158 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemas reflects Go types the way encoding/json marshals them. Named
+// structs become components and are referenced, so recursive types such as
+// Employee.Manager terminate.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	// enums holds the allowed values of "Type.jsonField"
+	enums map[string][]any
+}
+
+func newSchemas(enums map[string][]any) *schemas {
+	return &schemas{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+		enums:      enums,
+	}
+}
+
+// of returns the schema of the type of value, nil for a nil value.
+func (s *schemas) of(value any) *Schema {
+	if value == nil {
+		return nil
+	}
+	return s.schemaOf(reflect.TypeOf(value))
+}
+
+func (s *schemas) schemaOf(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		schema := s.schemaOf(t.Elem())
+		if schema.Ref != "" {
+			// siblings of a $ref are ignored, so the reference is wrapped
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case deletedAtType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: true}
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		// a custom encoding such as model.JSON can hold any value
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return s.component(t)
+	}
+	// interface{} and anything encoding/json cannot tell upfront
+	return &Schema{}
+}
+
+func (s *schemas) component(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return s.object(t)
+	}
+	name, ok := s.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := s.components[name]; taken {
+			pkg := path.Base(t.PkgPath())
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+		s.names[t] = name
+		// registered before the fields are reflected, for recursive types
+		schema := &Schema{}
+		s.components[name] = schema
+		*schema = *s.object(t)
+	}
+	return ref(name)
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(schema, t, t.Name(), false)
+	return schema
+}
+
+// addFields adds the fields of t to schema. Fields of embedded structs are
+// promoted unless the outer struct has a field of the same name.
+func (s *schemas) addFields(schema *Schema, t reflect.Type, owner string, embedded bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				s.addFields(schema, fieldType, owner, true)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, exists := schema.Properties[name]; exists && embedded {
+			continue
+		}
+		property := s.schemaOf(field.Type)
+		if values, ok := s.enums[owner+"."+name]; ok {
+			property.Enum = values
+		}
+		schema.Properties[name] = property
+	}
+}
@@ -3,6 +3,7 @@ package response
 import (
 	"net/http"
 
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
 	"github.com/fajritsaniy/golang-SHM/model/dto"
 	"github.com/gin-gonic/gin"
 )
@@ -28,19 +29,60 @@ func SendPageResponse(c *gin.Context, data []interface{}, responseType string, p
 	})
 }
 
-func SendErrorResponse(c *gin.Context, code int, errorMessage string) {
-	c.AbortWithStatusJSON(code, &Status{
-		Code:        code,
-		Description: errorMessage,
+func SendFacetedPageResponse(c *gin.Context, data []interface{}, responseType string, paging dto.Paging, facets interface{}) {
+	c.JSON(http.StatusOK, &FacetedPagedResponse{
+		PagedResponse: PagedResponse{
+			Status: Status{
+				Code:        http.StatusOK,
+				Description: responseType,
+			},
+			Data:   data,
+			Paging: paging,
+		},
+		Facets: facets,
 	})
 }
 
-func SendFileResponse(c *gin.Context, fileName string, responseType string) {
-	c.JSON(http.StatusOK, &FileResponse{
+// SendErrorResponse renders err with the status of its kind, in the locale of
+// the request. Errors that are not an *apperror.Error are answered as
+// internal errors without details.
+func SendErrorResponse(c *gin.Context, err error) {
+	appErr := apperror.From(err)
+	code := StatusCode(appErr.Kind)
+	locale := Locale(c)
+	c.AbortWithStatusJSON(code, &ErrorResponse{
 		Status: Status{
-			Code:        http.StatusOK,
-			Description: responseType,
+			Code:        code,
+			Description: appErr.Text(locale),
+		},
+		Error: ErrorDetail{
+			Code:   appErr.Code,
+			Fields: appErr.FieldTexts(locale),
 		},
-		FileName: fileName,
 	})
 }
+
+func StatusCode(kind apperror.Kind) int {
+	switch kind {
+	case apperror.KindBadRequest:
+		return http.StatusBadRequest
+	case apperror.KindNotFound:
+		return http.StatusNotFound
+	case apperror.KindConflict:
+		return http.StatusConflict
+	case apperror.KindValidation:
+		return http.StatusUnprocessableEntity
+	case apperror.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperror.KindForbidden:
+		return http.StatusForbidden
+	case apperror.KindLocked:
+		return http.StatusLocked
+	case apperror.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	case apperror.KindTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
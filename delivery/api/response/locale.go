@@ -0,0 +1,54 @@
+package response
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// NegotiateLocale picks the supported locale the Accept-Language header
+// weighs highest. Regional variants match their language, id-ID is id.
+// fallback is used when the client accepts none of them.
+func NegotiateLocale(acceptLanguage string, fallback string) string {
+	type candidate struct {
+		locale string
+		weight float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		language, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if weight > 0 && i18n.IsSupported(language) {
+			candidates = append(candidates, candidate{locale: language, weight: weight})
+		}
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].weight > candidates[j].weight
+	})
+	return candidates[0].locale
+}
+
+// Locale is the locale negotiated for the request, see
+// middleware.LocaleMiddleware.
+func Locale(c *gin.Context) string {
+	return i18n.FromContext(c.Request.Context())
+}
+
+// T renders a message of the catalog in the locale of the request.
+func T(c *gin.Context, key string, args i18n.Args) string {
+	return i18n.T(Locale(c), key, args)
+}
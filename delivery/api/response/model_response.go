@@ -18,7 +18,32 @@ type PagedResponse struct {
 	Paging dto.Paging    `json:"paging,omitempty"`
 }
 
-type FileResponse struct {
-	Status   Status `json:"status"`
-	FileName string `json:"fileName"`
+// FacetedPagedResponse is a page with the counts per filter value of the
+// whole result.
+type FacetedPagedResponse struct {
+	PagedResponse
+	Facets interface{} `json:"facets"`
+}
+
+type ErrorDetail struct {
+	Code   string            `json:"code"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+type ErrorResponse struct {
+	Status Status      `json:"status"`
+	Error  ErrorDetail `json:"error"`
+}
+
+// MessageResponse answers actions that have no data to return.
+type MessageResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type TokenResponse struct {
+	Code         int    `json:"code"`
+	Message      string `json:"message,omitempty"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
 }
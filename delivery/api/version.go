@@ -0,0 +1,43 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version is a major version of the API. Versions are served side by side
+// under their own prefix; controllers register their routes on every version
+// and pick the handlers of the version they are given.
+type Version int
+
+const (
+	V1 Version = iota + 1
+	V2
+)
+
+// Versions lists the served versions, oldest first.
+var Versions = []Version{V1, V2}
+
+func (v Version) String() string {
+	return "v" + strconv.Itoa(int(v))
+}
+
+// Prefix is the path the routes of v are grouped under, e.g. /api/v1.
+func (v Version) Prefix() string {
+	return "/api/" + v.String()
+}
+
+// SplitVersion splits a route into its version and the path within it. A
+// route outside every version prefix returns 0 and the route unchanged.
+func SplitVersion(route string) (Version, string) {
+	for _, version := range Versions {
+		prefix := version.Prefix()
+		if route == prefix {
+			return version, "/"
+		}
+		if strings.HasPrefix(route, prefix+"/") {
+			return version, strings.TrimPrefix(route, prefix)
+		}
+	}
+	return 0, route
+}
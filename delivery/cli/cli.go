@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/fajritsaniy/golang-SHM/config"
+	"github.com/fajritsaniy/golang-SHM/delivery"
+	"github.com/fajritsaniy/golang-SHM/manager"
+	"github.com/fajritsaniy/golang-SHM/migration"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"gorm.io/gorm"
+)
+
+const usage = `usage: golang-SHM <command> [arguments]
+
+commands:
+  serve [-migrate]                     start the HTTP API (default)
+  migrate up|down [N]|status|create    manage the database schema
+  seed [-dir fixtures]                 load brands, vehicles and demo customers
+  user create|activate|deactivate|reset-password
+                                       manage user accounts
+  config check                         validate the configuration and connections
+
+run "<command> -h" for the flags of a command`
+
+// actor is recorded in the audit trail and security events for changes made
+// from the command line.
+const actor = "cli"
+
+type command struct {
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+// Run executes the command in args (os.Args without the program name).
+func Run(args []string, stdin io.Reader, stdout io.Writer) error {
+	cmd := &command{stdin: stdin, stdout: stdout}
+	if len(args) == 0 {
+		return cmd.serve(nil)
+	}
+
+	switch args[0] {
+	case "serve":
+		return cmd.serve(args[1:])
+	case "migrate":
+		return migration.Run(args[1:], cmd.connect, stdout)
+	case "seed":
+		return cmd.seed(args[1:])
+	case "user":
+		return cmd.user(args[1:])
+	case "config":
+		return cmd.config(args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprintln(stdout, usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+func (c *command) flags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stdout)
+	return flags
+}
+
+// infra loads the configuration and opens the database the same way the
+// server does.
+func (c *command) infra() (*config.Config, manager.InfraManager, error) {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	infraManager, err := manager.NewInfraManager(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, infraManager, nil
+}
+
+func (c *command) connect() (*gorm.DB, error) {
+	_, infraManager, err := c.infra()
+	if err != nil {
+		return nil, err
+	}
+	return infraManager.Conn(), nil
+}
+
+func (c *command) useCases() (manager.UseCaseManager, error) {
+	_, infraManager, err := c.infra()
+	if err != nil {
+		return nil, err
+	}
+	repoManager := manager.NewRepositoryManager(infraManager)
+	return manager.NewUseCaseManager(infraManager, repoManager), nil
+}
+
+func (c *command) context() context.Context {
+	return model.WithPrincipal(context.Background(), model.Principal{
+		Username: actor,
+		Role:     model.RoleAdmin,
+	})
+}
+
+func (c *command) serve(args []string) error {
+	flags := c.flags("serve")
+	migrate := flags.Bool("migrate", false, "apply pending migrations before starting")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, infraManager, err := c.infra()
+	if err != nil {
+		return err
+	}
+	if *migrate {
+		if err := infraManager.Migrate(); err != nil {
+			return errors.Join(err, infraManager.Close())
+		}
+	}
+	return delivery.NewServer(cfg, infraManager).Run()
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fajritsaniy/golang-SHM/config"
+	"github.com/fajritsaniy/golang-SHM/manager"
+	"github.com/fajritsaniy/golang-SHM/migration"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/utils/storage"
+	"gorm.io/gorm"
+)
+
+type check struct {
+	name string
+	run  func() error
+}
+
+func (c *command) config(args []string) error {
+	if len(args) != 1 || args[0] != "check" {
+		return fmt.Errorf("usage: config check")
+	}
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		fmt.Fprintf(c.stdout, "FAIL  configuration: %v\n", err)
+		return fmt.Errorf("configuration check failed")
+	}
+	fmt.Fprintf(c.stdout, "ok    configuration loaded (env %s, api %s:%s)\n", cfg.Env, cfg.ApiHost, cfg.ApiPort)
+
+	// the migration check reuses the connection opened by the database check
+	var db *gorm.DB
+	checks := []check{
+		{"token", func() error { return checkToken(cfg.TokenConfig) }},
+		{"notifier", func() error { return checkNotifier(cfg.NotifierConfig) }},
+		{"storage", func() error { return checkStorage(cfg.FileConfig) }},
+		{"database", func() (err error) {
+			db, err = openDatabase(cfg)
+			return err
+		}},
+		{"migrations", func() error {
+			if db == nil {
+				return fmt.Errorf("skipped, no database connection")
+			}
+			return checkMigrations(db)
+		}},
+	}
+	failed := false
+	for _, check := range checks {
+		if err := check.run(); err != nil {
+			failed = true
+			fmt.Fprintf(c.stdout, "FAIL  %s: %v\n", check.name, err)
+			continue
+		}
+		fmt.Fprintf(c.stdout, "ok    %s\n", check.name)
+	}
+	if failed {
+		return fmt.Errorf("configuration check failed")
+	}
+	return nil
+}
+
+func checkToken(cfg config.TokenConfig) error {
+	if cfg.JwtSignatureKey == "" {
+		return fmt.Errorf("TOKEN_SECRET is empty")
+	}
+	if cfg.AccessTokenLifeTime <= 0 || cfg.RefreshTokenLifeTime <= 0 || cfg.PasswordResetLifeTime <= 0 {
+		return fmt.Errorf("token lifetimes must be positive")
+	}
+	return nil
+}
+
+func checkNotifier(cfg config.NotifierConfig) error {
+	switch cfg.Driver {
+	case "", "log":
+		return nil
+	case "file":
+		if cfg.FilePath == "" {
+			return fmt.Errorf("NOTIFIER_FILE_PATH is required for the file notifier")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown notifier %q", cfg.Driver)
+	}
+}
+
+func checkStorage(cfg config.FileConfig) error {
+	store, err := storage.New(cfg)
+	if err != nil {
+		return err
+	}
+	return store.Ping(context.Background())
+}
+
+func openDatabase(cfg *config.Config) (*gorm.DB, error) {
+	infraManager, err := manager.NewInfraManager(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := infraManager.Ping(context.Background()); err != nil {
+		return nil, err
+	}
+	return infraManager.Conn(), nil
+}
+
+func checkMigrations(db *gorm.DB) error {
+	statuses, err := migration.NewMigrator(db, migration.Files()).Status()
+	if err != nil {
+		return err
+	}
+	pending := 0
+	for _, status := range statuses {
+		switch {
+		case status.Missing:
+			return fmt.Errorf("%s was applied but its file is missing", status.Migration)
+		case status.Modified:
+			return fmt.Errorf("%s was changed after it was applied", status.Migration)
+		case !status.Applied:
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d pending, run migrate up", pending)
+	}
+	return migration.CheckModels(migration.Files(), model.Models()...)
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+)
+
+// vehicleFixture names the brand instead of referencing its generated ID.
+type vehicleFixture struct {
+	model.Vehicle
+	BrandName string `json:"brandName"`
+}
+
+type seedResult struct {
+	created int
+	skipped int
+}
+
+func (r seedResult) String() string {
+	return fmt.Sprintf("%d created, %d already present", r.created, r.skipped)
+}
+
+// seed loads brands.json, vehicles.json and customers.json from the fixture
+// directory. Rows that already exist are skipped, so it can be run again.
+func (c *command) seed(args []string) error {
+	flags := c.flags("seed")
+	dir := flags.String("dir", "fixtures", "directory with the fixture files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var brands []model.Brand
+	var vehicles []vehicleFixture
+	var customers []model.Customer
+	for name, target := range map[string]any{
+		"brands.json":    &brands,
+		"vehicles.json":  &vehicles,
+		"customers.json": &customers,
+	} {
+		if err := readFixture(filepath.Join(*dir, name), target); err != nil {
+			return err
+		}
+	}
+
+	ucManager, err := c.useCases()
+	if err != nil {
+		return err
+	}
+	ctx := c.context()
+
+	var result seedResult
+	brandUseCase := ucManager.BrandUseCase()
+	for i := range brands {
+		if exists, _ := brandUseCase.IsNameExists(ctx, brands[i].Name, ""); exists {
+			result.skipped++
+			continue
+		}
+		if err := brandUseCase.SaveData(ctx, &brands[i]); err != nil {
+			return fmt.Errorf("brand %s: %w", brands[i].Name, err)
+		}
+		result.created++
+	}
+	fmt.Fprintf(c.stdout, "brands: %s\n", result)
+
+	result = seedResult{}
+	vehicleUseCase := ucManager.VehicleUseCase()
+	for i := range vehicles {
+		fixture := &vehicles[i]
+		found, err := brandUseCase.SearchBy(ctx, map[string]interface{}{"name": fixture.BrandName})
+		if err != nil || len(found) == 0 {
+			return fmt.Errorf("vehicle %s: brand %s not found", fixture.Model, fixture.BrandName)
+		}
+		fixture.BrandID = found[0].ID
+		existing, err := vehicleUseCase.SearchBy(ctx, map[string]interface{}{
+			"brand_id":        fixture.BrandID,
+			"model":           fixture.Model,
+			"production_year": fixture.ProductionYear,
+			"color":           fixture.Color,
+		})
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			result.skipped++
+			continue
+		}
+		if err := vehicleUseCase.SaveData(ctx, &fixture.Vehicle); err != nil {
+			return fmt.Errorf("vehicle %s %s: %w", fixture.BrandName, fixture.Model, err)
+		}
+		result.created++
+	}
+	fmt.Fprintf(c.stdout, "vehicles: %s\n", result)
+
+	result = seedResult{}
+	customerUseCase := ucManager.CustomerUseCase()
+	for i := range customers {
+		if _, err := customerUseCase.FindByEmail(ctx, customers[i].Email); err == nil {
+			result.skipped++
+			continue
+		}
+		if err := customerUseCase.SaveData(ctx, &customers[i]); err != nil {
+			return fmt.Errorf("customer %s: %w", customers[i].Email, err)
+		}
+		result.created++
+	}
+	fmt.Fprintf(c.stdout, "customers: %s\n", result)
+	return nil
+}
+
+// readFixture decodes a JSON array file. A missing file is not an error, it
+// just seeds nothing of that kind.
+func readFixture(path string, target any) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, target); err != nil {
+		return fmt.Errorf("invalid fixture %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+)
+
+const userUsage = `usage: user <command> [flags]
+
+commands:
+  create -username NAME [-role admin] [-password-stdin]
+  activate USERNAME
+  deactivate USERNAME
+  reset-password USERNAME [-password-stdin]
+
+without -password-stdin a random password is generated and printed, and the
+user must change it on the first login`
+
+func (c *command) user(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(userUsage)
+	}
+	switch args[0] {
+	case "create":
+		return c.createUser(args[1:])
+	case "activate":
+		return c.setUserActive(args[1:], true)
+	case "deactivate":
+		return c.setUserActive(args[1:], false)
+	case "reset-password":
+		return c.resetPassword(args[1:])
+	default:
+		return fmt.Errorf("unknown user command %q\n%s", args[0], userUsage)
+	}
+}
+
+func (c *command) createUser(args []string) error {
+	flags := c.flags("user create")
+	username := flags.String("username", "", "login name of the new user")
+	role := flags.String("role", model.RoleAdmin, "admin, manager, sales or customer")
+	fromStdin := flags.Bool("password-stdin", false, "read the password from standard input")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return fmt.Errorf("-username is required")
+	}
+
+	password, generated, err := c.password(*fromStdin)
+	if err != nil {
+		return err
+	}
+	ucManager, err := c.useCases()
+	if err != nil {
+		return err
+	}
+	user := &model.UserCredential{
+		UserName:           *username,
+		Password:           password,
+		Role:               *role,
+		IsActive:           true,
+		MustChangePassword: generated,
+	}
+	if err := ucManager.AuthUseCase().CreateUser(c.context(), user); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "created %s user %s\n", user.Role, user.UserName)
+	if generated {
+		fmt.Fprintf(c.stdout, "temporary password: %s\n", password)
+	}
+	return nil
+}
+
+func (c *command) setUserActive(args []string, active bool) error {
+	if len(args) != 1 {
+		return fmt.Errorf("a username is required")
+	}
+	ucManager, err := c.useCases()
+	if err != nil {
+		return err
+	}
+	if err := ucManager.AuthUseCase().SetUserActive(c.context(), args[0], active); err != nil {
+		return err
+	}
+
+	state := "activated"
+	if !active {
+		state = "deactivated"
+	}
+	fmt.Fprintf(c.stdout, "%s user %s\n", state, args[0])
+	return nil
+}
+
+func (c *command) resetPassword(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("a username is required")
+	}
+	username := args[0]
+	flags := c.flags("user reset-password")
+	fromStdin := flags.Bool("password-stdin", false, "read the password from standard input")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	password, generated, err := c.password(*fromStdin)
+	if err != nil {
+		return err
+	}
+	ucManager, err := c.useCases()
+	if err != nil {
+		return err
+	}
+	if err := ucManager.AuthUseCase().SetPassword(c.context(), username, password); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "password of %s was reset, it must be changed on the next login\n", username)
+	if generated {
+		fmt.Fprintf(c.stdout, "temporary password: %s\n", password)
+	}
+	return nil
+}
+
+// password reads the first line of stdin, or generates a random password
+// when fromStdin is false. Passwords are never taken as arguments so they do
+// not end up in the shell history.
+func (c *command) password(fromStdin bool) (string, bool, error) {
+	if fromStdin {
+		line, err := bufio.NewReader(c.stdin).ReadString('\n')
+		password := strings.TrimRight(line, "\r\n")
+		if password == "" {
+			if err != nil {
+				return "", false, fmt.Errorf("failed to read password: %v", err)
+			}
+			return "", false, fmt.Errorf("password is empty")
+		}
+		return password, false, nil
+	}
+
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", false, err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), true, nil
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api"
+	"github.com/fajritsaniy/golang-SHM/delivery/middleware"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+	"github.com/fajritsaniy/golang-SHM/usecase"
+	"github.com/fajritsaniy/golang-SHM/utils/common"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	router  gin.IRouter
+	usecase usecase.AuditUseCase
+	api.BaseApi
+}
+
+// parseAuditTime accepts a full RFC 3339 timestamp or a plain date.
+func parseAuditTime(field string, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed, nil
+		}
+	}
+	return nil, apperror.Validation(apperror.CodeValidation, "validation.invalid_date", map[string]string{field: "validation.invalid_date"}).
+		With(i18n.Args{"value": value})
+}
+
+func (a *AuditController) listHandler(c *gin.Context) {
+	requestQueryParams, err := common.ValidateRequestQueryParams(c)
+	if err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	from, err := parseAuditTime("from", c.Query("from"))
+	if err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	to, err := parseAuditTime("to", c.Query("to"))
+	if err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	filter := dto.AuditLogFilter{
+		Entity:   c.Query("entity"),
+		EntityID: c.Query("entityId"),
+		Actor:    c.Query("actor"),
+		From:     from,
+		To:       to,
+	}
+
+	logs, paging, err := a.usecase.Pagination(c.Request.Context(), filter, requestQueryParams)
+	if err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+
+	var logInterface []interface{}
+	for _, log := range logs {
+		logInterface = append(logInterface, log)
+	}
+	a.NewSuccessPageResponse(c, logInterface, "OK", paging)
+}
+
+func NewAuditController(r gin.IRouter, usecase usecase.AuditUseCase, authMiddleware middleware.AuthTokenMiddleware) *AuditController {
+	controller := AuditController{
+		router:  r,
+		usecase: usecase,
+	}
+	r.GET("/audit", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin), controller.listHandler)
+	return &controller
+}
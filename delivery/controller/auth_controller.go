@@ -1,89 +1,267 @@
 package controller
 
 import (
-	"fmt"
 	"net/http"
 
+	"github.com/fajritsaniy/golang-SHM/delivery/api"
+	"github.com/fajritsaniy/golang-SHM/delivery/api/response"
+	"github.com/fajritsaniy/golang-SHM/delivery/middleware"
 	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
 	"github.com/fajritsaniy/golang-SHM/usecase"
+	"github.com/fajritsaniy/golang-SHM/utils/common"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
 	"github.com/gin-gonic/gin"
 )
 
 type AuthController struct {
-	router  *gin.Engine
+	router  gin.IRouter
 	usecase usecase.AuthenticationUseCase
+	api.BaseApi
 }
 
 func (a *AuthController) loginHandler(c *gin.Context) {
-	var payload model.UserCredential
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+	var payload dto.LoginRequest
+	if err := a.ParseRequestBody(c, &payload); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	tokens, err := a.usecase.Login(c.Request.Context(), payload.Username, payload.Password, c.ClientIP())
+	if err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, &response.TokenResponse{
+		Code:         http.StatusCreated,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+func (a *AuthController) refreshTokenHandler(c *gin.Context) {
+	var payload dto.RefreshTokenRequest
+	if err := a.ParseRequestBody(c, &payload); err != nil {
+		a.NewErrorResponse(c, err)
 		return
 	}
-	fmt.Println(payload.UserName, payload.Password)
-	token, err := a.usecase.Login(payload.UserName, payload.Password)
+	tokens, err := a.usecase.RefreshToken(c.Request.Context(), payload.RefreshToken)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		a.NewErrorResponse(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{
-		"code":  http.StatusCreated,
-		"token": token,
+	c.JSON(http.StatusCreated, &response.TokenResponse{
+		Code:         http.StatusCreated,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	})
+}
+
+func (a *AuthController) logoutHandler(c *gin.Context) {
+	// the refresh token is optional, without it only the access token is revoked
+	var payload dto.RefreshTokenRequest
+	if c.Request.ContentLength > 0 {
+		if err := a.ParseRequestBody(c, &payload); err != nil {
+			a.NewErrorResponse(c, err)
+			return
+		}
+	}
+	principal, _ := middleware.CurrentPrincipal(c)
+	err := a.usecase.Logout(c.Request.Context(), principal, payload.RefreshToken)
+	if err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, &response.MessageResponse{
+		Code:    http.StatusOK,
+		Message: response.T(c, "auth.logged_out", nil),
 	})
 }
 
 func (a *AuthController) registerHandler(c *gin.Context) {
 	var payload model.UserCredential
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+	if err := a.ParseRequestBody(c, &payload); err != nil {
+		a.NewErrorResponse(c, err)
 		return
 	}
-	err := a.usecase.Register(&payload)
+	err := a.usecase.Register(c.Request.Context(), &payload)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		a.NewErrorResponse(c, err)
 		return
 	}
 
-	successMessage := fmt.Sprintf("%s has been registered.", payload.UserName)
+	successMessage := response.T(c, "auth.registered", i18n.Args{"username": payload.UserName})
 
-	c.JSON(http.StatusCreated, gin.H{
-		"code":    http.StatusCreated,
-		"message": successMessage,
+	c.JSON(http.StatusCreated, &response.MessageResponse{
+		Code:    http.StatusCreated,
+		Message: successMessage,
+	})
+}
+
+func (a *AuthController) updateUsernameHandler(c *gin.Context) {
+	var payload dto.UpdateUsernameRequest
+	if err := a.ParseRequestBody(c, &payload); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	principal, _ := middleware.CurrentPrincipal(c)
+	if err := a.usecase.UpdateUsername(c.Request.Context(), principal, c.Param("id"), payload); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, &response.MessageResponse{
+		Code:    http.StatusOK,
+		Message: response.T(c, "auth.username_updated", nil),
 	})
 }
 
 func (a *AuthController) userActivationHandler(c *gin.Context) {
 	var payload model.UserCredential
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"err": err.Error()})
+	if err := a.ParseRequestBody(c, &payload); err != nil {
+		a.NewErrorResponse(c, err)
 		return
 	}
-	status, err := a.usecase.UserActivation(&payload)
+	status, err := a.usecase.UserActivation(c.Request.Context(), &payload)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"err": err.Error()})
+		a.NewErrorResponse(c, err)
 		return
 	}
 
 	var successMessage string
 	if status {
-		successMessage = fmt.Sprintf("%s has been activated.", payload.UserName)
+		successMessage = response.T(c, "auth.activated", i18n.Args{"username": payload.UserName})
 	} else {
-		successMessage = fmt.Sprintf("%s has been disabled.", payload.UserName)
+		successMessage = response.T(c, "auth.deactivated", i18n.Args{"username": payload.UserName})
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"code":    http.StatusCreated,
-		"message": successMessage,
+	c.JSON(http.StatusCreated, &response.MessageResponse{
+		Code:    http.StatusCreated,
+		Message: successMessage,
+	})
+}
+
+func (a *AuthController) changePasswordHandler(c *gin.Context) {
+	var payload dto.ChangePasswordRequest
+	if err := a.ParseRequestBody(c, &payload); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	principal, _ := middleware.CurrentPrincipal(c)
+	tokens, err := a.usecase.ChangePassword(c.Request.Context(), principal, payload)
+	if err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, &response.TokenResponse{
+		Code:         http.StatusOK,
+		Message:      response.T(c, "auth.password_changed", nil),
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	})
 }
 
-func NewAuthController(r *gin.Engine, usecase usecase.AuthenticationUseCase) *AuthController {
+func (a *AuthController) forgotPasswordHandler(c *gin.Context) {
+	var payload dto.ForgotPasswordRequest
+	if err := a.ParseRequestBody(c, &payload); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	if err := a.usecase.ForgotPassword(c.Request.Context(), payload.Username); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	// same answer whether or not the user exists
+	c.JSON(http.StatusAccepted, &response.MessageResponse{
+		Code:    http.StatusAccepted,
+		Message: response.T(c, "auth.password_reset_requested", nil),
+	})
+}
+
+func (a *AuthController) resetPasswordHandler(c *gin.Context) {
+	var payload dto.ResetPasswordRequest
+	if err := a.ParseRequestBody(c, &payload); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	if err := a.usecase.ResetPassword(c.Request.Context(), payload); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, &response.MessageResponse{
+		Code:    http.StatusOK,
+		Message: response.T(c, "auth.password_reset", nil),
+	})
+}
+
+func (a *AuthController) changeRoleHandler(c *gin.Context) {
+	var payload model.UserCredential
+	if err := a.ParseRequestBody(c, &payload); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	if err := a.usecase.ChangeRole(c.Request.Context(), &payload); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &response.MessageResponse{
+		Code:    http.StatusOK,
+		Message: response.T(c, "auth.role_changed", i18n.Args{"username": payload.UserName, "role": payload.Role}),
+	})
+}
+
+func (a *AuthController) unlockHandler(c *gin.Context) {
+	var payload model.UserCredential
+	if err := a.ParseRequestBody(c, &payload); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	principal, _ := middleware.CurrentPrincipal(c)
+	if err := a.usecase.UnlockUser(c.Request.Context(), principal, payload.UserName); err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &response.MessageResponse{
+		Code:    http.StatusOK,
+		Message: response.T(c, "auth.unlocked", i18n.Args{"username": payload.UserName}),
+	})
+}
+
+func (a *AuthController) securityEventsHandler(c *gin.Context) {
+	requestQueryParams, err := common.ValidateRequestQueryParams(c)
+	if err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+	events, paging, err := a.usecase.SecurityEvents(c.Request.Context(), requestQueryParams)
+	if err != nil {
+		a.NewErrorResponse(c, err)
+		return
+	}
+
+	var eventInterface []interface{}
+	for _, event := range events {
+		eventInterface = append(eventInterface, event)
+	}
+	a.NewSuccessPageResponse(c, eventInterface, "OK", paging)
+}
+
+func NewAuthController(r gin.IRouter, usecase usecase.AuthenticationUseCase, authMiddleware middleware.AuthTokenMiddleware) *AuthController {
 	controller := AuthController{
 		router:  r,
 		usecase: usecase,
 	}
 	r.POST("/login", controller.loginHandler)
+	r.POST("/token/refresh", controller.refreshTokenHandler)
+	r.POST("/logout", authMiddleware.RequireTokenWithoutPasswordCheck(), controller.logoutHandler)
+	r.POST("/password/change", authMiddleware.RequireTokenWithoutPasswordCheck(), controller.changePasswordHandler)
+	r.POST("/password/forgot", controller.forgotPasswordHandler)
+	r.POST("/password/reset", controller.resetPasswordHandler)
 	r.POST("/register", controller.registerHandler)
-	r.POST("/activation", controller.userActivationHandler)
+	r.POST("/activation", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin), controller.userActivationHandler)
+	r.PUT("/users/:id/username", authMiddleware.RequireToken(), controller.updateUsernameHandler)
+	r.PUT("/users/role", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin), controller.changeRoleHandler)
+	r.POST("/users/unlock", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin), controller.unlockHandler)
+	r.GET("/users/security-events", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin), controller.securityEventsHandler)
 	return &controller
 }
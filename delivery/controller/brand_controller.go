@@ -12,7 +12,7 @@ import (
 )
 
 type BrandController struct {
-	router         *gin.Engine
+	router         gin.IRouter
 	usecase        usecase.BrandUseCase
 	authMiddleware middleware.AuthTokenMiddleware
 	api.BaseApi
@@ -20,22 +20,22 @@ type BrandController struct {
 
 func (b *BrandController) createUpdateHandler(c *gin.Context) {
 	var payload model.Brand
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		b.NewErrorErrorResponse(c, http.StatusBadRequest, err.Error())
+	if err := b.ParseRequestBody(c, &payload); err != nil {
+		b.NewErrorResponse(c, err)
 		return
 	}
-	if err := b.usecase.SaveData(&payload); err != nil {
-		b.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+	if err := b.usecase.SaveData(c.Request.Context(), &payload); err != nil {
+		b.NewErrorResponse(c, err)
 		return
 	}
 	b.NewSuccessSingleResponse(c, payload, "OK")
 }
 
 func (b *BrandController) listHandler(c *gin.Context) {
-	vehicles, err := b.usecase.FindAll()
+	vehicles, err := b.usecase.FindAll(c.Request.Context())
 
 	if err != nil {
-		b.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		b.NewErrorResponse(c, err)
 		return
 	}
 	var brandInterface []interface{}
@@ -47,9 +47,9 @@ func (b *BrandController) listHandler(c *gin.Context) {
 
 func (b *BrandController) getByIDHandler(c *gin.Context) {
 	id := c.Param("id")
-	vehicle, err := b.usecase.FindById(id)
+	vehicle, err := b.usecase.FindById(c.Request.Context(), id)
 	if err != nil {
-		b.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		b.NewErrorResponse(c, err)
 		return
 	}
 	b.NewSuccessSingleResponse(c, vehicle, "OK")
@@ -57,15 +57,15 @@ func (b *BrandController) getByIDHandler(c *gin.Context) {
 
 func (b *BrandController) deleteHandler(c *gin.Context) {
 	id := c.Param("id")
-	err := b.usecase.DeleteData(id)
+	err := b.usecase.DeleteData(c.Request.Context(), id)
 	if err != nil {
-		b.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		b.NewErrorResponse(c, err)
 		return
 	}
 	c.String(http.StatusNoContent, "")
 }
 
-func NewBrandController(r *gin.Engine, usecase usecase.BrandUseCase, authMiddleware middleware.AuthTokenMiddleware) *BrandController {
+func NewBrandController(r gin.IRouter, usecase usecase.BrandUseCase, authMiddleware middleware.AuthTokenMiddleware) *BrandController {
 	controller := BrandController{
 		router:         r,
 		usecase:        usecase,
@@ -75,8 +75,8 @@ func NewBrandController(r *gin.Engine, usecase usecase.BrandUseCase, authMiddlew
 	const brandsEndpoint = "/brands"
 	r.GET(brandsEndpoint, controller.listHandler)
 	r.GET("/brands/:id", controller.getByIDHandler)
-	r.POST(brandsEndpoint, authMiddleware.RequireToken(), controller.createUpdateHandler)
-	r.PUT(brandsEndpoint, authMiddleware.RequireToken(), controller.createUpdateHandler)
-	r.DELETE("/brands/:id", authMiddleware.RequireToken(), controller.deleteHandler)
+	r.POST(brandsEndpoint, authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.createUpdateHandler)
+	r.PUT(brandsEndpoint, authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.createUpdateHandler)
+	r.DELETE("/brands/:id", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin), controller.deleteHandler)
 	return &controller
 }
@@ -12,29 +12,29 @@ import (
 )
 
 type CustomerController struct {
-	router  *gin.Engine
+	router  gin.IRouter
 	usecase usecase.CustomerUseCase
 	api.BaseApi
 }
 
 func (cc *CustomerController) createUpdateHandler(c *gin.Context) {
 	var payload model.Customer
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		cc.NewErrorErrorResponse(c, http.StatusBadRequest, err.Error())
+	if err := cc.ParseRequestBody(c, &payload); err != nil {
+		cc.NewErrorResponse(c, err)
 		return
 	}
-	if err := cc.usecase.SaveData(&payload); err != nil {
-		cc.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+	if err := cc.usecase.SaveData(c.Request.Context(), &payload); err != nil {
+		cc.NewErrorResponse(c, err)
 		return
 	}
 	cc.NewSuccessSingleResponse(c, payload, "OK")
 }
 
 func (cc *CustomerController) listHandler(c *gin.Context) {
-	customers, err := cc.usecase.FindAll()
+	customers, err := cc.usecase.FindAll(c.Request.Context())
 
 	if err != nil {
-		cc.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		cc.NewErrorResponse(c, err)
 		return
 	}
 	var customerInterface []interface{}
@@ -46,35 +46,61 @@ func (cc *CustomerController) listHandler(c *gin.Context) {
 
 func (cc *CustomerController) getByIDHandler(c *gin.Context) {
 	id := c.Param("id")
-	vehicle, err := cc.usecase.FindById(id)
+	vehicle, err := cc.usecase.FindById(c.Request.Context(), id)
 	if err != nil {
-		cc.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		cc.NewErrorResponse(c, err)
 		return
 	}
 	cc.NewSuccessSingleResponse(c, vehicle, "OK")
 }
 
+func (cc *CustomerController) meHandler(c *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	customer, err := cc.usecase.FindByPrincipal(c.Request.Context(), principal)
+	if err != nil {
+		cc.NewErrorResponse(c, err)
+		return
+	}
+	cc.NewSuccessSingleResponse(c, customer, "OK")
+}
+
+func (cc *CustomerController) myVehiclesHandler(c *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	customer, err := cc.usecase.FindByPrincipal(c.Request.Context(), principal)
+	if err != nil {
+		cc.NewErrorResponse(c, err)
+		return
+	}
+	var vehicleInterface []interface{}
+	for _, v := range customer.Vehicles {
+		vehicleInterface = append(vehicleInterface, v)
+	}
+	cc.NewSuccessPageResponse(c, vehicleInterface, "OK", dto.Paging{})
+}
+
 func (cc *CustomerController) deleteHandler(c *gin.Context) {
 	id := c.Param("id")
-	err := cc.usecase.DeleteData(id)
+	err := cc.usecase.DeleteData(c.Request.Context(), id)
 	if err != nil {
-		cc.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		cc.NewErrorResponse(c, err)
 		return
 	}
 	c.String(http.StatusNoContent, "")
 }
 
-func NewCustomerController(r *gin.Engine, usecase usecase.CustomerUseCase, authMiddleware middleware.AuthTokenMiddleware) *CustomerController {
+func NewCustomerController(r gin.IRouter, usecase usecase.CustomerUseCase, authMiddleware middleware.AuthTokenMiddleware) *CustomerController {
 	controller := CustomerController{
 		router:  r,
 		usecase: usecase,
 	}
 
 	const customerEndpoint = "/customers"
-	r.GET(customerEndpoint, authMiddleware.RequireToken(), controller.listHandler)
-	r.GET("/customers/:id", authMiddleware.RequireToken(), controller.getByIDHandler)
-	r.POST(customerEndpoint, authMiddleware.RequireToken(), controller.createUpdateHandler)
-	r.PUT(customerEndpoint, authMiddleware.RequireToken(), controller.createUpdateHandler)
-	r.DELETE("/customers/:id", authMiddleware.RequireToken(), controller.deleteHandler)
+	r.GET(customerEndpoint, authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager, model.RoleSales), controller.listHandler)
+	r.GET("/customers/me", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleCustomer), controller.meHandler)
+	r.GET("/customers/me/vehicles", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleCustomer), controller.myVehiclesHandler)
+	r.GET("/customers/:id", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager, model.RoleSales), controller.getByIDHandler)
+	r.POST(customerEndpoint, authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager, model.RoleSales), controller.createUpdateHandler)
+	r.PUT(customerEndpoint, authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager, model.RoleSales), controller.createUpdateHandler)
+	r.DELETE("/customers/:id", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.deleteHandler)
 	return &controller
 }
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// docsPage renders /openapi.json with Swagger UI, loaded from a CDN so the
+// binary does not have to embed it.
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Showroom Management API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5.17.14/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui", persistAuthorization: true });
+  </script>
+</body>
+</html>`
+
+type DocsController struct {
+	router   *gin.Engine
+	document *openapi.Document
+}
+
+func (d *DocsController) specHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, d.document)
+}
+
+func (d *DocsController) docsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(docsPage))
+}
+
+// NewDocsController serves the OpenAPI document of the routes registered on r
+// so far, so it has to be created after every other controller. The error
+// names routes the document is missing, the docs are served regardless.
+func NewDocsController(r *gin.Engine) (*DocsController, error) {
+	controller := DocsController{
+		router: r,
+	}
+	r.GET("/openapi.json", controller.specHandler)
+	r.GET("/docs", controller.docsHandler)
+	document, err := openapi.Build(r.Routes())
+	controller.document = document
+	return &controller, err
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/fajritsaniy/golang-SHM/delivery/api"
 	"github.com/fajritsaniy/golang-SHM/delivery/middleware"
@@ -12,29 +13,29 @@ import (
 )
 
 type EmployeeController struct {
-	router  *gin.Engine
+	router  gin.IRouter
 	usecase usecase.EmployeeUseCase
 	api.BaseApi
 }
 
 func (e *EmployeeController) createUpdateHandler(c *gin.Context) {
 	var payload model.Employee
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		e.NewErrorErrorResponse(c, http.StatusBadRequest, err.Error())
+	if err := e.ParseRequestBody(c, &payload); err != nil {
+		e.NewErrorResponse(c, err)
 		return
 	}
-	if err := e.usecase.SaveData(&payload); err != nil {
-		e.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+	if err := e.usecase.SaveData(c.Request.Context(), &payload); err != nil {
+		e.NewErrorResponse(c, err)
 		return
 	}
 	e.NewSuccessSingleResponse(c, payload, "OK")
 }
 
 func (e *EmployeeController) listHandler(c *gin.Context) {
-	employees, err := e.usecase.FindAll()
+	employees, err := e.usecase.FindAll(c.Request.Context())
 
 	if err != nil {
-		e.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		e.NewErrorResponse(c, err)
 		return
 	}
 	var employeeInterface []interface{}
@@ -46,9 +47,19 @@ func (e *EmployeeController) listHandler(c *gin.Context) {
 
 func (e *EmployeeController) getByIDHandler(c *gin.Context) {
 	id := c.Param("id")
-	employee, err := e.usecase.FindById(id)
+	employee, err := e.usecase.FindById(c.Request.Context(), id)
 	if err != nil {
-		e.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		e.NewErrorResponse(c, err)
+		return
+	}
+	e.NewSuccessSingleResponse(c, employee, "OK")
+}
+
+func (e *EmployeeController) meHandler(c *gin.Context) {
+	principal, _ := middleware.CurrentPrincipal(c)
+	employee, err := e.usecase.FindByPrincipal(c.Request.Context(), principal)
+	if err != nil {
+		e.NewErrorResponse(c, err)
 		return
 	}
 	e.NewSuccessSingleResponse(c, employee, "OK")
@@ -56,25 +67,41 @@ func (e *EmployeeController) getByIDHandler(c *gin.Context) {
 
 func (e *EmployeeController) deleteHandler(c *gin.Context) {
 	id := c.Param("id")
-	err := e.usecase.DeleteData(id)
+	err := e.usecase.DeleteData(c.Request.Context(), id)
 	if err != nil {
-		e.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		e.NewErrorResponse(c, err)
 		return
 	}
 	c.String(http.StatusNoContent, "")
 }
 
-func NewEmployeeController(r *gin.Engine, usecase usecase.EmployeeUseCase, authMiddleware middleware.AuthTokenMiddleware) *EmployeeController {
+// NewEmployeeController registers the employee routes of version. Up to v1
+// the collection lives at /employee, deprecated since collectionDeprecatedAt;
+// v2 moves it next to the other employee routes at /employees.
+func NewEmployeeController(r gin.IRouter, version api.Version, usecase usecase.EmployeeUseCase, authMiddleware middleware.AuthTokenMiddleware, collectionDeprecatedAt time.Time) *EmployeeController {
 	controller := EmployeeController{
 		router:  r,
 		usecase: usecase,
 	}
 
-	const employeeEndpoint = "/employee"
-	r.GET(employeeEndpoint, authMiddleware.RequireToken(), controller.listHandler)
-	r.GET("/employees/:id", authMiddleware.RequireToken(), controller.getByIDHandler)
-	r.POST(employeeEndpoint, authMiddleware.RequireToken(), controller.createUpdateHandler)
-	r.PUT(employeeEndpoint, authMiddleware.RequireToken(), controller.createUpdateHandler)
-	r.DELETE("/employees/:id", authMiddleware.RequireToken(), controller.deleteHandler)
+	employeeEndpoint := "/employees"
+	var collectionMiddleware []gin.HandlerFunc
+	if version < api.V2 {
+		employeeEndpoint = "/employee"
+		collectionMiddleware = append(collectionMiddleware, middleware.DeprecationMiddleware(middleware.Deprecation{
+			Since:     collectionDeprecatedAt,
+			Successor: middleware.SuccessorPath(api.V2.Prefix() + "/employees"),
+		}))
+	}
+	collection := func(handlers ...gin.HandlerFunc) []gin.HandlerFunc {
+		return append(append([]gin.HandlerFunc{}, collectionMiddleware...), handlers...)
+	}
+
+	r.GET(employeeEndpoint, collection(authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.listHandler)...)
+	r.GET("/employees/me", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager, model.RoleSales), controller.meHandler)
+	r.GET("/employees/:id", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.getByIDHandler)
+	r.POST(employeeEndpoint, collection(authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin), controller.createUpdateHandler)...)
+	r.PUT(employeeEndpoint, collection(authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin), controller.createUpdateHandler)...)
+	r.DELETE("/employees/:id", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin), controller.deleteHandler)
 	return &controller
 }
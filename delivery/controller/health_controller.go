@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api/response"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+	"github.com/fajritsaniy/golang-SHM/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	router  *gin.Engine
+	usecase usecase.HealthUseCase
+}
+
+// livenessHandler only proves the process is serving requests, it never
+// touches a dependency.
+func (h *HealthController) livenessHandler(c *gin.Context) {
+	response.SendSingleResponse(c, dto.HealthReport{Status: dto.HealthStatusUp}, dto.HealthStatusUp)
+}
+
+func (h *HealthController) readinessHandler(c *gin.Context) {
+	report := h.usecase.Readiness(c.Request.Context())
+	code := http.StatusOK
+	if report.Status != dto.HealthStatusUp {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, &response.SingleResponse{
+		Status: response.Status{
+			Code:        code,
+			Description: report.Status,
+		},
+		Data: report,
+	})
+}
+
+func NewHealthController(r *gin.Engine, usecase usecase.HealthUseCase) *HealthController {
+	controller := HealthController{
+		router:  r,
+		usecase: usecase,
+	}
+	r.GET("/healthz", controller.livenessHandler)
+	r.GET("/readyz", controller.readinessHandler)
+	return &controller
+}
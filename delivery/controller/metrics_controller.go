@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"crypto/subtle"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/utils/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type MetricsController struct {
+	router *gin.Engine
+	token  string
+	api.BaseApi
+}
+
+// requireScrapeToken protects /metrics with a static bearer token. The config
+// only leaves the token empty in DEV, where scraping needs none.
+func (m *MetricsController) requireScrapeToken(c *gin.Context) {
+	if m.token == "" {
+		c.Next()
+		return
+	}
+	expected := []byte("Bearer " + m.token)
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), expected) != 1 {
+		m.NewErrorResponse(c, apperror.Unauthorized(apperror.CodeUnauthorized, "error.unauthorized"))
+		return
+	}
+	c.Next()
+}
+
+func NewMetricsController(r *gin.Engine, token string) *MetricsController {
+	controller := MetricsController{
+		router: r,
+		token:  token,
+	}
+	handler := promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})
+	r.GET("/metrics", controller.requireScrapeToken, gin.WrapH(handler))
+	return &controller
+}
@@ -1,8 +1,6 @@
 package controller
 
 import (
-	"net/http"
-
 	"github.com/fajritsaniy/golang-SHM/delivery/api"
 	"github.com/fajritsaniy/golang-SHM/delivery/middleware"
 	"github.com/fajritsaniy/golang-SHM/model"
@@ -12,29 +10,30 @@ import (
 )
 
 type TransactionController struct {
-	router  *gin.Engine
+	router  gin.IRouter
 	usecase usecase.TransactionUseCase
 	api.BaseApi
 }
 
 func (e *TransactionController) createHandler(c *gin.Context) {
 	var payload model.Transaction
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		e.NewErrorErrorResponse(c, http.StatusBadRequest, err.Error())
+	if err := e.ParseRequestBody(c, &payload); err != nil {
+		e.NewErrorResponse(c, err)
 		return
 	}
-	if err := e.usecase.RegisterNewTransaction(&payload); err != nil {
-		e.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+	principal, _ := middleware.CurrentPrincipal(c)
+	if err := e.usecase.RegisterNewTransaction(c.Request.Context(), principal, &payload); err != nil {
+		e.NewErrorResponse(c, err)
 		return
 	}
 	e.NewSuccessSingleResponse(c, payload, "OK")
 }
 
 func (e *TransactionController) listHandler(c *gin.Context) {
-	transactions, err := e.usecase.FindAllTransaction()
+	transactions, err := e.usecase.FindAllTransaction(c.Request.Context())
 
 	if err != nil {
-		e.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		e.NewErrorResponse(c, err)
 		return
 	}
 
@@ -47,21 +46,21 @@ func (e *TransactionController) listHandler(c *gin.Context) {
 
 func (e *TransactionController) getByIDHandler(c *gin.Context) {
 	id := c.Param("id")
-	transaction, err := e.usecase.FindByTransaction(id)
+	transaction, err := e.usecase.FindByTransaction(c.Request.Context(), id)
 	if err != nil {
-		e.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		e.NewErrorResponse(c, err)
 		return
 	}
 	e.NewSuccessSingleResponse(c, transaction, "OK")
 }
 
-func NewTransactionController(r *gin.Engine, usecase usecase.TransactionUseCase, authMiddleware middleware.AuthTokenMiddleware) *TransactionController {
+func NewTransactionController(r gin.IRouter, usecase usecase.TransactionUseCase, authMiddleware middleware.AuthTokenMiddleware) *TransactionController {
 	controller := TransactionController{
 		router:  r,
 		usecase: usecase,
 	}
-	r.GET("/transactions", authMiddleware.RequireToken(), controller.listHandler)
-	r.GET("/transactions/:id", authMiddleware.RequireToken(), controller.getByIDHandler)
-	r.POST("/transactions", authMiddleware.RequireToken(), controller.createHandler)
+	r.GET("/transactions", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.listHandler)
+	r.GET("/transactions/:id", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager, model.RoleSales), controller.getByIDHandler)
+	r.POST("/transactions", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager, model.RoleSales), controller.createHandler)
 	return &controller
 }
@@ -2,123 +2,190 @@ package controller
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/fajritsaniy/golang-SHM/delivery/middleware"
 
 	"github.com/fajritsaniy/golang-SHM/delivery/api"
 	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
 	"github.com/fajritsaniy/golang-SHM/usecase"
 	"github.com/fajritsaniy/golang-SHM/utils/common"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/fajritsaniy/golang-SHM/utils/logger"
+	"github.com/fajritsaniy/golang-SHM/utils/security"
 	"github.com/gin-gonic/gin"
 )
 
 type VehicleController struct {
-	router  *gin.Engine
+	router  gin.IRouter
+	version api.Version
 	usecase usecase.VehicleUseCase
+	signer  security.URLSigner
 	api.BaseApi
 }
 
+func (v *VehicleController) withImageURL(vehicle *model.Vehicle) {
+	imageURLs{version: v.version, signer: v.signer}.signVehicle(vehicle)
+}
+
 func (v *VehicleController) createHandler(c *gin.Context) {
 	vehicle := c.PostForm("vehicle")
-	file, fileHeader, err := c.Request.FormFile("image")
+	file, _, err := c.Request.FormFile("image")
 	if err != nil {
-		v.NewErrorErrorResponse(c, http.StatusBadRequest, "Failed Get File")
-	}
-	log.Println(fileHeader.Filename)
-	fileName := strings.Split(fileHeader.Filename, ".")
-	if len(fileName) != 2 {
-		v.NewErrorErrorResponse(c, http.StatusBadRequest, "Unrecognized file extension")
+		v.NewErrorResponse(c, apperror.Validation(apperror.CodeValidation, "vehicle.image_required", map[string]string{"image": "vehicle.image_required"}))
+		return
 	}
+	defer file.Close()
 	var payload model.Vehicle
 	err = json.Unmarshal([]byte(vehicle), &payload)
 	if err != nil {
-		log.Println("failed to unmarshal")
+		logger.FromContext(c.Request.Context()).WithError(err).Debug("invalid vehicle form field")
+		v.NewErrorResponse(c, apperror.BadRequest(apperror.CodeInvalidBody, "vehicle.invalid_form_data"))
+		return
 	}
-	if err := v.usecase.UploadImage(&payload, file, fileName[1]); err != nil {
-		v.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+	if err := v.usecase.UploadImage(c.Request.Context(), &payload, file); err != nil {
+		v.NewErrorResponse(c, err)
 		return
 	}
+	v.withImageURL(&payload)
 	v.NewSuccessSingleResponse(c, payload, "OK")
 }
 
 func (v *VehicleController) updateHandler(c *gin.Context) {
 	var payload model.Vehicle
-	if err := c.ShouldBindJSON(&payload); err != nil {
-		v.NewErrorErrorResponse(c, http.StatusBadRequest, err.Error())
+	if err := v.ParseRequestBody(c, &payload); err != nil {
+		v.NewErrorResponse(c, err)
 		return
 	}
-	if err := v.usecase.SaveData(&payload); err != nil {
-		v.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+	if err := v.usecase.SaveData(c.Request.Context(), &payload); err != nil {
+		v.NewErrorResponse(c, err)
 		return
 	}
+	v.withImageURL(&payload)
 	v.NewSuccessSingleResponse(c, payload, "OK")
 }
 
+// parseVehicleFilter reads the filters of GET /vehicles, numbers and flags
+// that do not parse are reported per parameter.
+func parseVehicleFilter(c *gin.Context) (dto.VehicleFilter, error) {
+	filter := dto.VehicleFilter{
+		BrandID: c.Query("brandId"),
+		Status:  c.Query("status"),
+		Color:   strings.TrimSpace(c.Query("color")),
+	}
+	numbers := []struct {
+		name   string
+		target *int
+	}{
+		{"minYear", &filter.MinYear},
+		{"maxYear", &filter.MaxYear},
+		{"minPrice", &filter.MinPrice},
+		{"maxPrice", &filter.MaxPrice},
+	}
+	for _, number := range numbers {
+		name := number.name
+		if c.Query(name) == "" {
+			continue
+		}
+		value, err := strconv.Atoi(c.Query(name))
+		if err != nil || value <= 0 {
+			return dto.VehicleFilter{}, apperror.Validation(apperror.CodeValidation, "error.validation_failed", map[string]string{name: "validation.positive_number"})
+		}
+		*number.target = value
+	}
+	flags := []struct {
+		name string
+		set  func(bool)
+	}{
+		{"automatic", func(value bool) { filter.IsAutomatic = &value }},
+		{"inStock", func(value bool) { filter.InStock = value }},
+	}
+	for _, flag := range flags {
+		name := flag.name
+		if c.Query(name) == "" {
+			continue
+		}
+		value, err := strconv.ParseBool(c.Query(name))
+		if err != nil {
+			return dto.VehicleFilter{}, apperror.Validation(apperror.CodeValidation, "error.validation_failed", map[string]string{name: "validation.type_mismatch"}).
+				With(i18n.Args{"type": "boolean"})
+		}
+		flag.set(value)
+	}
+	return filter, nil
+}
+
 func (v *VehicleController) listHandler(c *gin.Context) {
 	requestQueryParams, err := common.ValidateRequestQueryParams(c)
 	if err != nil {
-		v.NewErrorErrorResponse(c, http.StatusBadRequest, err.Error())
+		v.NewErrorResponse(c, err)
+		return
+	}
+	filter, err := parseVehicleFilter(c)
+	if err != nil {
+		v.NewErrorResponse(c, err)
 		return
 	}
 
-	vehicles, paging, err := v.usecase.Paging(requestQueryParams)
+	vehicles, paging, err := v.usecase.Paging(c.Request.Context(), filter, requestQueryParams)
+	if err != nil {
+		v.NewErrorResponse(c, err)
+		return
+	}
+	facets, err := v.usecase.Facets(c.Request.Context(), filter, requestQueryParams.QueryParams.Query)
 	if err != nil {
-		v.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		v.NewErrorResponse(c, err)
 		return
 	}
 
 	var vehicleInterface []interface{}
-	for _, v := range vehicles {
-		vehicleInterface = append(vehicleInterface, v)
+	for _, vehicle := range vehicles {
+		v.withImageURL(&vehicle)
+		vehicleInterface = append(vehicleInterface, vehicle)
 	}
-	v.NewSuccessPageResponse(c, vehicleInterface, "OK", paging)
+	v.NewSuccessFacetedPageResponse(c, vehicleInterface, "OK", paging, facets)
 }
 
 func (v *VehicleController) getByIDHandler(c *gin.Context) {
 	id := c.Param("id")
-	vehicle, err := v.usecase.FindById(id)
+	vehicle, err := v.usecase.FindById(c.Request.Context(), id)
 	if err != nil {
-		v.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		v.NewErrorResponse(c, err)
 		return
 	}
+	v.withImageURL(vehicle)
 	v.NewSuccessSingleResponse(c, vehicle, "OK")
 }
 
-func (v *VehicleController) getImageByIDHandler(c *gin.Context) {
-	id := c.Param("id")
-	vehicle, err := v.usecase.FindById(id)
-	if err != nil {
-		v.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-	v.NewSuccessFileResponse(c, vehicle.ImgPath, "OK")
-}
-
 func (v *VehicleController) deleteHandler(c *gin.Context) {
 	id := c.Param("id")
-	err := v.usecase.DeleteData(id)
+	err := v.usecase.DeleteData(c.Request.Context(), id)
 	if err != nil {
-		v.NewErrorErrorResponse(c, http.StatusInternalServerError, err.Error())
+		v.NewErrorResponse(c, err)
 		return
 	}
 	c.String(http.StatusNoContent, "")
 }
 
-func NewVehicleController(r *gin.Engine, usecase usecase.VehicleUseCase, authMiddleware middleware.AuthTokenMiddleware) *VehicleController {
+// NewVehicleController registers the vehicle routes of version, whose
+// image links point into the same version.
+func NewVehicleController(r gin.IRouter, version api.Version, usecase usecase.VehicleUseCase, authMiddleware middleware.AuthTokenMiddleware, signer security.URLSigner) *VehicleController {
 	controller := VehicleController{
 		router:  r,
+		version: version,
 		usecase: usecase,
+		signer:  signer,
 	}
 
 	const vehicleEndpoint = "/vehicles"
 	r.GET(vehicleEndpoint, controller.listHandler)
-	r.POST(vehicleEndpoint, authMiddleware.RequireToken(), controller.createHandler)
-	r.PUT(vehicleEndpoint, authMiddleware.RequireToken(), controller.updateHandler)
+	r.POST(vehicleEndpoint, authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.createHandler)
+	r.PUT(vehicleEndpoint, authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.updateHandler)
 	r.GET("/vehicles/:id", controller.getByIDHandler)
-	r.GET("/vehicles/image/:id", authMiddleware.RequireToken(), controller.getImageByIDHandler)
-	r.DELETE("/vehicles/:id", authMiddleware.RequireToken(), controller.deleteHandler)
+	r.DELETE("/vehicles/:id", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.deleteHandler)
 	return &controller
 }
@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api"
+	"github.com/fajritsaniy/golang-SHM/delivery/middleware"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+	"github.com/fajritsaniy/golang-SHM/usecase"
+	"github.com/fajritsaniy/golang-SHM/utils/imaging"
+	"github.com/fajritsaniy/golang-SHM/utils/security"
+	"github.com/fajritsaniy/golang-SHM/utils/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// imageCacheControl lets browsers reuse an image for a few minutes, then
+// revalidate it with its ETag.
+const imageCacheControl = "private, max-age=300"
+
+type VehicleImageController struct {
+	router  gin.IRouter
+	version api.Version
+	usecase usecase.VehicleImageUseCase
+	signer  security.URLSigner
+	api.BaseApi
+}
+
+// imageURLs signs a link for every image of the version images are served
+// in.
+type imageURLs struct {
+	version api.Version
+	signer  security.URLSigner
+}
+
+// sign links the original and every rendition size. Sizes without a
+// rendition serve the original, which is smaller than them.
+func (u imageURLs) sign(image *model.VehicleImage) {
+	path := "/vehicles/" + image.VehicleID + "/images/" + image.ID
+	image.UrlPath = u.signed(path)
+	image.RenditionUrls = make(map[string]string, len(imaging.Sizes))
+	for _, size := range imaging.Sizes {
+		image.RenditionUrls[size.Name] = u.signed(path + "/" + size.Name)
+	}
+}
+
+func (u imageURLs) signed(path string) string {
+	return u.version.Prefix() + path + "?" + u.signer.Sign(path).Encode()
+}
+
+func (u imageURLs) signAll(images []model.VehicleImage) {
+	for i := range images {
+		u.sign(&images[i])
+	}
+}
+
+// signVehicle also points the UrlPath of vehicle at its primary image.
+func (u imageURLs) signVehicle(vehicle *model.Vehicle) {
+	u.signAll(vehicle.Images)
+	if vehicle.PrimaryImage != nil {
+		u.sign(vehicle.PrimaryImage)
+		vehicle.UrlPath = vehicle.PrimaryImage.UrlPath
+		vehicle.RenditionUrls = vehicle.PrimaryImage.RenditionUrls
+	}
+}
+
+func (v *VehicleImageController) urls() imageURLs {
+	return imageURLs{version: v.version, signer: v.signer}
+}
+
+func (v *VehicleImageController) listHandler(c *gin.Context) {
+	images, err := v.usecase.FindAll(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		v.NewErrorResponse(c, err)
+		return
+	}
+	v.urls().signAll(images)
+	v.NewSuccessSingleResponse(c, images, "OK")
+}
+
+// uploadHandler takes the files as repeated "images" fields and optional
+// "captions" fields in the same order.
+func (v *VehicleImageController) uploadHandler(c *gin.Context) {
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["images"]) == 0 {
+		v.NewErrorResponse(c, apperror.Validation(apperror.CodeValidation, "vehicle.image_required", map[string]string{"images": "vehicle.image_required"}))
+		return
+	}
+	captions := form.Value["captions"]
+	uploads := make([]usecase.VehicleImageUpload, 0, len(form.File["images"]))
+	files := make([]multipart.File, 0, len(form.File["images"]))
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+	for i, fileHeader := range form.File["images"] {
+		file, err := fileHeader.Open()
+		if err != nil {
+			v.NewErrorResponse(c, err)
+			return
+		}
+		upload := usecase.VehicleImageUpload{File: file}
+		if i < len(captions) {
+			upload.Caption = captions[i]
+		}
+		uploads = append(uploads, upload)
+		files = append(files, file)
+	}
+
+	images, err := v.usecase.Upload(c.Request.Context(), c.Param("id"), uploads)
+	if err != nil {
+		v.NewErrorResponse(c, err)
+		return
+	}
+	v.urls().signAll(images)
+	v.NewSuccessSingleResponse(c, images, "OK")
+}
+
+func (v *VehicleImageController) reorderHandler(c *gin.Context) {
+	var payload dto.VehicleImageOrderRequest
+	if err := v.ParseRequestBody(c, &payload); err != nil {
+		v.NewErrorResponse(c, err)
+		return
+	}
+	images, err := v.usecase.Reorder(c.Request.Context(), c.Param("id"), payload.ImageIDs)
+	if err != nil {
+		v.NewErrorResponse(c, err)
+		return
+	}
+	v.urls().signAll(images)
+	v.NewSuccessSingleResponse(c, images, "OK")
+}
+
+func (v *VehicleImageController) setPrimaryHandler(c *gin.Context) {
+	images, err := v.usecase.SetPrimary(c.Request.Context(), c.Param("id"), c.Param("imageId"))
+	if err != nil {
+		v.NewErrorResponse(c, err)
+		return
+	}
+	v.urls().signAll(images)
+	v.NewSuccessSingleResponse(c, images, "OK")
+}
+
+func (v *VehicleImageController) deleteHandler(c *gin.Context) {
+	if err := v.usecase.Delete(c.Request.Context(), c.Param("id"), c.Param("imageId")); err != nil {
+		v.NewErrorResponse(c, err)
+		return
+	}
+	c.String(http.StatusNoContent, "")
+}
+
+func (v *VehicleImageController) imageHandler(c *gin.Context) {
+	image, err := v.usecase.Open(c.Request.Context(), c.Param("id"), c.Param("imageId"), c.Param("size"))
+	v.serveImage(c, image, err)
+}
+
+func (v *VehicleImageController) primaryImageHandler(c *gin.Context) {
+	image, err := v.usecase.OpenPrimary(c.Request.Context(), c.Param("id"))
+	v.serveImage(c, image, err)
+}
+
+// serveImage streams image. http.ServeContent answers conditional and range
+// requests from the ETag and modification time.
+func (v *VehicleImageController) serveImage(c *gin.Context, image *storage.Object, err error) {
+	if err != nil {
+		v.NewErrorResponse(c, err)
+		return
+	}
+	defer image.Body.Close()
+
+	c.Header("Content-Type", image.ContentType)
+	c.Header("Cache-Control", imageCacheControl)
+	if image.ETag != "" {
+		c.Header("ETag", image.ETag)
+	}
+	http.ServeContent(c.Writer, c.Request, "", image.ModTime, image.Body)
+}
+
+// NewVehicleImageController registers the gallery routes of version. The
+// images are served to a bearer token or a signed URL. Up to v1 the primary
+// image is also served at /vehicles/image/:id, which used to answer with
+// the file name and is deprecated since pathDeprecatedAt.
+func NewVehicleImageController(r gin.IRouter, version api.Version, usecase usecase.VehicleImageUseCase, authMiddleware middleware.AuthTokenMiddleware, signer security.URLSigner, pathDeprecatedAt time.Time) *VehicleImageController {
+	controller := VehicleImageController{
+		router:  r,
+		version: version,
+		usecase: usecase,
+		signer:  signer,
+	}
+
+	signedOrToken := middleware.SignedURLMiddleware(signer, authMiddleware.RequireToken())
+	r.GET("/vehicles/:id/image", signedOrToken, controller.primaryImageHandler)
+	r.GET("/vehicles/:id/images", controller.listHandler)
+	r.POST("/vehicles/:id/images", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.uploadHandler)
+	r.PUT("/vehicles/:id/images/order", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.reorderHandler)
+	r.GET("/vehicles/:id/images/:imageId", signedOrToken, controller.imageHandler)
+	r.GET("/vehicles/:id/images/:imageId/:size", signedOrToken, controller.imageHandler)
+	r.PUT("/vehicles/:id/images/:imageId/primary", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.setPrimaryHandler)
+	r.DELETE("/vehicles/:id/images/:imageId", authMiddleware.RequireToken(), authMiddleware.RequireRole(model.RoleAdmin, model.RoleManager), controller.deleteHandler)
+	if version < api.V2 {
+		r.GET("/vehicles/image/:id", middleware.DeprecationMiddleware(middleware.Deprecation{
+			Since: pathDeprecatedAt,
+			Successor: func(c *gin.Context) string {
+				return version.Prefix() + "/vehicles/" + c.Param("id") + "/image"
+			},
+		}), authMiddleware.RequireToken(), controller.primaryImageHandler)
+	}
+	return &controller
+}
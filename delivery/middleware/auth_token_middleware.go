@@ -1,11 +1,22 @@
 package middleware
 
 import (
-	"fmt"
 	"strings"
 
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/utils/logger"
 	"github.com/fajritsaniy/golang-SHM/utils/security"
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const principalKey = "principal"
+
+var (
+	errUnauthorized           = apperror.Unauthorized(apperror.CodeUnauthorized, "error.unauthorized")
+	errForbidden              = apperror.Forbidden(apperror.CodeForbidden, "error.forbidden")
+	errPasswordChangeRequired = apperror.Forbidden("PASSWORD_CHANGE_REQUIRED", "error.password_change_required")
 )
 
 type authHeader struct {
@@ -13,6 +24,10 @@ type authHeader struct {
 }
 type AuthTokenMiddleware interface {
 	RequireToken() gin.HandlerFunc
+	// RequireTokenWithoutPasswordCheck also accepts users who still have to
+	// change their password; only the password change and logout use it.
+	RequireTokenWithoutPasswordCheck() gin.HandlerFunc
+	RequireRole(roles ...string) gin.HandlerFunc
 }
 type authTokenMiddleware struct {
 	tokenService security.AccessToken
@@ -25,41 +40,70 @@ func NewTokenValidator(tokenService security.AccessToken) AuthTokenMiddleware {
 }
 
 func (a *authTokenMiddleware) RequireToken() gin.HandlerFunc {
+	return a.requireToken(true)
+}
+
+func (a *authTokenMiddleware) RequireTokenWithoutPasswordCheck() gin.HandlerFunc {
+	return a.requireToken(false)
+}
+
+func (a *authTokenMiddleware) requireToken(checkPassword bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		h := authHeader{}
 		if err := c.ShouldBindHeader(&h); err != nil {
-			c.JSON(401, gin.H{
-				"message": "Unauthorized",
-			})
-			c.Abort()
+			abortWithError(c, errUnauthorized)
 			return
 		}
 		tokenString := strings.Replace(h.AuthorizationHeader, "Bearer ", "", -1)
 		if tokenString == "" {
-			c.JSON(401, gin.H{
-				"message": "Unauthorized",
-			})
-			c.Abort()
+			abortWithError(c, errUnauthorized)
 			return
 		}
-		token, err := a.tokenService.VerifyAccessToken(tokenString)
-		fmt.Println("err:", err)
+		token, err := a.tokenService.VerifyAccessToken(c.Request.Context(), tokenString)
 		if err != nil {
-			c.JSON(401, gin.H{
-				"message": "Unauthorized",
-			})
-			c.Abort()
+			logger.FromContext(c.Request.Context()).WithError(err).Debug("access token rejected")
+			abortWithError(c, errUnauthorized)
 			return
 		}
-		fmt.Println(token)
 		if token != nil {
+			principal := security.PrincipalFromClaims(token)
+			if checkPassword && principal.MustChangePassword {
+				abortWithError(c, errPasswordChangeRequired)
+				return
+			}
+			c.Set(principalKey, principal)
+			ctx := model.WithPrincipal(c.Request.Context(), principal)
+			ctx = logger.WithFields(ctx, logrus.Fields{logger.FieldUser: principal.Username})
+			c.Request = c.Request.WithContext(ctx)
 			c.Next()
 		} else {
-			c.JSON(401, gin.H{
-				"message": "Unauthorized",
-			})
-			c.Abort()
+			abortWithError(c, errUnauthorized)
+			return
+		}
+	}
+}
+
+// CurrentPrincipal returns the caller RequireToken authenticated for this
+// request. The second value is false on routes without RequireToken.
+func CurrentPrincipal(c *gin.Context) (model.Principal, bool) {
+	value, exists := c.Get(principalKey)
+	principal, ok := value.(model.Principal)
+	return principal, exists && ok
+}
+
+// RequireRole must be chained after RequireToken. It only lets the request
+// through when the role carried in the verified token is one of roles.
+func (a *authTokenMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			abortWithError(c, errUnauthorized)
+			return
+		}
+		if principal.HasRole(roles...) {
+			c.Next()
 			return
 		}
+		abortWithError(c, errForbidden)
 	}
 }
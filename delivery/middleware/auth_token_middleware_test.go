@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthTokenMiddlewareTestSuite struct {
+	suite.Suite
+	middleware AuthTokenMiddleware
+}
+
+func (suite *AuthTokenMiddlewareTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	// RequireRole only reads the principal, no token is verified
+	suite.middleware = NewTokenValidator(nil)
+}
+
+// serve runs RequireRole(roles) for a caller signed in as principal, or for
+// nobody when principal is nil.
+func (suite *AuthTokenMiddlewareTestSuite) serve(principal *model.Principal, roles ...string) int {
+	engine := gin.New()
+	engine.Use(ErrorMiddleware())
+	engine.GET("/", func(c *gin.Context) {
+		if principal != nil {
+			c.Set(principalKey, *principal)
+		}
+	}, suite.middleware.RequireRole(roles...), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	return recorder.Code
+}
+
+func (suite *AuthTokenMiddlewareTestSuite) TestRequireRoleAllowedSuccess() {
+	code := suite.serve(&model.Principal{Role: model.RoleManager}, model.RoleAdmin, model.RoleManager)
+	assert.Equal(suite.T(), http.StatusNoContent, code)
+}
+
+func (suite *AuthTokenMiddlewareTestSuite) TestRequireRoleForbiddenFail() {
+	code := suite.serve(&model.Principal{Role: model.RoleSales}, model.RoleAdmin)
+	assert.Equal(suite.T(), http.StatusForbidden, code)
+}
+
+func (suite *AuthTokenMiddlewareTestSuite) TestRequireRoleWithoutUserFail() {
+	code := suite.serve(nil, model.RoleAdmin)
+	assert.Equal(suite.T(), http.StatusUnauthorized, code)
+}
+
+func TestAuthTokenMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthTokenMiddlewareTestSuite))
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/utils/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecation describes a route that clients should move away from.
+type Deprecation struct {
+	// Since is sent as the Deprecation header (RFC 9745). Without it the
+	// header is the bare "true" of the earlier drafts.
+	Since time.Time
+	// Sunset is when the route goes away (RFC 8594), zero when not planned.
+	Sunset time.Time
+	// Successor returns the route replacing the requested one, sent as a
+	// successor-version link.
+	Successor func(c *gin.Context) string
+}
+
+// DeprecationMiddleware marks the responses of a route as deprecated and
+// counts its calls, so the sunset can wait for the last client to move.
+func DeprecationMiddleware(deprecation Deprecation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if deprecation.Since.IsZero() {
+			c.Header("Deprecation", "true")
+		} else {
+			c.Header("Deprecation", "@"+strconv.FormatInt(deprecation.Since.Unix(), 10))
+		}
+		if !deprecation.Sunset.IsZero() {
+			c.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if deprecation.Successor != nil {
+			if successor := deprecation.Successor(c); successor != "" {
+				c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+			}
+		}
+		metrics.DeprecatedRequestsTotal.WithLabelValues(c.Request.Method, c.FullPath()).Inc()
+		c.Next()
+	}
+}
+
+// SuccessorPath is a Successor that always points to path.
+func SuccessorPath(path string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		return path
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/fajritsaniy/golang-SHM/delivery/api/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorMiddleware renders the last error a handler or middleware attached
+// with c.Error, so every failure leaves in the same envelope. It has to run
+// inside the access log and metrics middlewares, which read the status it
+// writes.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if err := c.Errors.Last(); err != nil && !c.Writer.Written() {
+			response.SendErrorResponse(c, err.Err)
+		}
+	}
+}
+
+// abortWithError stops the chain and leaves err to ErrorMiddleware.
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
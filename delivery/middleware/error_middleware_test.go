@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api/response"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorMiddlewareTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ErrorMiddlewareTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve answers a request whose handler fails with err.
+func (suite *ErrorMiddlewareTestSuite) serve(err error) (int, response.ErrorResponse) {
+	engine := gin.New()
+	engine.Use(ErrorMiddleware())
+	engine.GET("/", func(c *gin.Context) {
+		abortWithError(c, err)
+	})
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	var body response.ErrorResponse
+	assert.NoError(suite.T(), json.Unmarshal(recorder.Body.Bytes(), &body))
+	return recorder.Code, body
+}
+
+func (suite *ErrorMiddlewareTestSuite) TestErrorMiddlewareKindsSuccess() {
+	cases := []struct {
+		err         error
+		status      int
+		code        string
+		description string
+	}{
+		{apperror.BadRequest(apperror.CodeInvalidBody, "error.invalid_body"), http.StatusBadRequest, apperror.CodeInvalidBody, "invalid request body"},
+		{apperror.NotFound("BRAND_NOT_FOUND", "record.not_found"), http.StatusNotFound, "BRAND_NOT_FOUND", "record not found"},
+		{apperror.Conflict("BRAND_ALREADY_EXISTS", "record.already_exists"), http.StatusConflict, "BRAND_ALREADY_EXISTS", "record already exists"},
+		{apperror.Validation(apperror.CodeValidation, "error.validation_failed", nil), http.StatusUnprocessableEntity, apperror.CodeValidation, "validation failed"},
+		{apperror.Unauthorized(apperror.CodeUnauthorized, "error.unauthorized"), http.StatusUnauthorized, apperror.CodeUnauthorized, "unauthorized"},
+		{apperror.Forbidden(apperror.CodeForbidden, "error.forbidden"), http.StatusForbidden, apperror.CodeForbidden, "forbidden"},
+		{apperror.New(apperror.KindLocked, "ACCOUNT_LOCKED", "auth.account_locked"), http.StatusLocked, "ACCOUNT_LOCKED", "account is temporarily locked"},
+		{apperror.New(apperror.KindTooManyRequests, "TOO_MANY_LOGIN_ATTEMPTS", "auth.too_many_login_attempts"), http.StatusTooManyRequests, "TOO_MANY_LOGIN_ATTEMPTS", "too many failed login attempts, try again later"},
+		{apperror.New(apperror.KindTimeout, apperror.CodeTimeout, "error.timeout"), http.StatusGatewayTimeout, apperror.CodeTimeout, "request timed out"},
+		{apperror.Internal(errors.New("disk full")), http.StatusInternalServerError, apperror.CodeInternal, "internal server error"},
+	}
+	for _, tc := range cases {
+		status, body := suite.serve(tc.err)
+		assert.Equal(suite.T(), tc.status, status, tc.code)
+		assert.Equal(suite.T(), tc.status, body.Status.Code, tc.code)
+		assert.Equal(suite.T(), tc.code, body.Error.Code, tc.code)
+		assert.Equal(suite.T(), tc.description, body.Status.Description, tc.code)
+	}
+}
+
+func (suite *ErrorMiddlewareTestSuite) TestErrorMiddlewareFieldsSuccess() {
+	status, body := suite.serve(apperror.Validation(apperror.CodeValidation, "error.validation_failed", map[string]string{
+		"name": "error.validation_failed",
+	}))
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, status)
+	assert.Equal(suite.T(), map[string]string{"name": "validation failed"}, body.Error.Fields)
+}
+
+func (suite *ErrorMiddlewareTestSuite) TestErrorMiddlewarePlainErrorFail() {
+	// the cause of an unexpected error is logged, never sent
+	status, body := suite.serve(errors.New("pq: password authentication failed"))
+	assert.Equal(suite.T(), http.StatusInternalServerError, status)
+	assert.Equal(suite.T(), apperror.CodeInternal, body.Error.Code)
+	assert.Equal(suite.T(), "internal server error", body.Status.Description)
+}
+
+func TestErrorMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(ErrorMiddlewareTestSuite))
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/fajritsaniy/golang-SHM/delivery/api/response"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// LocaleMiddleware negotiates the locale of the request from Accept-Language
+// and stores it on the request context, where error responses and use cases
+// writing notifications find it.
+func LocaleMiddleware(fallback string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := response.NegotiateLocale(c.GetHeader("Accept-Language"), fallback)
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
@@ -1,47 +1,44 @@
 package middleware
 
 import (
-	"os"
 	"time"
 
-	"github.com/fajritsaniy/golang-SHM/config"
-	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/utils/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
+// LogRequestMiddleware writes one access log line per request. It must run
+// after RequestIDMiddleware so the line carries the request fields.
 func LogRequestMiddleware(log *logrus.Logger) gin.HandlerFunc {
-	cfg, err := config.NewConfig()
-	file, err := os.OpenFile(cfg.LogFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Set up logrus
-	log.SetOutput(file)
-
 	return func(c *gin.Context) {
 		startTime := time.Now()
 
 		c.Next()
 
-		endTime := time.Since(startTime)
-		entryLog := model.RequestLog{
-			EndTime:      endTime,
-			StatusCode:   c.Writer.Status(),
-			ClientIP:     c.ClientIP(),
-			Method:       c.Request.Method,
-			RelativePath: c.Request.URL.Path,
-			UserAgent:    c.Request.UserAgent(),
+		latency := time.Since(startTime)
+		status := c.Writer.Status()
+		// the request context is read after c.Next, when auth has added the user
+		entry := log.WithFields(logger.FromContext(c.Request.Context()).Data).WithFields(logrus.Fields{
+			"start_time":        startTime.Format(time.RFC3339Nano),
+			logger.FieldStatus:  status,
+			logger.FieldLatency: float64(latency.Microseconds()) / 1000,
+			logger.FieldBytes:   c.Writer.Size(),
+			"path":              c.Request.URL.Path,
+			"client_ip":         c.ClientIP(),
+			"user_agent":        c.Request.UserAgent(),
+		})
+		if len(c.Errors) > 0 {
+			entry = entry.WithField("errors", c.Errors.String())
 		}
 
 		switch {
-		case c.Writer.Status() >= 500:
-			log.Error(entryLog)
-		case c.Writer.Status() >= 400:
-			log.Warn(entryLog)
+		case status >= 500:
+			entry.Error("request completed")
+		case status >= 400:
+			entry.Warn("request completed")
 		default:
-			log.Info(entryLog)
+			entry.Info("request completed")
 		}
 	}
 }
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/utils/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware observes every request in the per-route latency
+// histogram. Routes are labelled by template (/vehicles/:id), never by the
+// raw path, to keep the number of series bounded.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metrics.HTTPRequestsInFlight.Inc()
+		startTime := time.Now()
+		defer func() {
+			metrics.HTTPRequestsInFlight.Dec()
+			status := c.Writer.Status()
+			// a panicking handler wrote nothing yet, the recovery further out
+			// answers 500
+			recovered := recover()
+			if recovered != nil {
+				status = http.StatusInternalServerError
+			}
+			route := c.FullPath()
+			if route == "" {
+				route = "unmatched"
+			}
+			metrics.HTTPRequestDuration.
+				WithLabelValues(c.Request.Method, route, strconv.Itoa(status)).
+				Observe(time.Since(startTime).Seconds())
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+	}
+}
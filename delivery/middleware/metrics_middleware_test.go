@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fajritsaniy/golang-SHM/utils/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddlewarePanicFail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}), MetricsMiddleware())
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.HTTPRequestsInFlight))
+	var observed dto.Metric
+	histogram := metrics.HTTPRequestDuration.WithLabelValues(http.MethodGet, "/panic", "500").(prometheus.Metric)
+	assert.NoError(t, histogram.Write(&observed))
+	assert.Equal(t, uint64(1), observed.GetHistogram().GetSampleCount())
+}
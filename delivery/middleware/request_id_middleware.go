@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/fajritsaniy/golang-SHM/utils/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// incoming IDs are echoed into logs and headers, so only plain tokens are kept
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware accepts the caller's X-Request-ID or generates one,
+// returns it on the response and puts a logger carrying it on the request
+// context for the layers below.
+func RequestIDMiddleware(log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		entry := log.WithFields(logrus.Fields{
+			logger.FieldRequestID: requestID,
+			logger.FieldMethod:    c.Request.Method,
+			logger.FieldRoute:     route,
+		})
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), entry))
+		c.Next()
+	}
+}
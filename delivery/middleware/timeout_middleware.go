@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutMiddleware puts a deadline on the request context, so queries of a
+// request that runs too long or whose client went away are cancelled. The
+// limit of a route is looked up as "METHOD /route" in routes, with and then
+// without its version prefix, and falls back to timeout; zero means no
+// deadline.
+func TimeoutMiddleware(timeout time.Duration, routes map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			_, route := api.SplitVersion(c.FullPath())
+			limit, ok = routes[c.Request.Method+" "+route]
+		}
+		if !ok {
+			limit = timeout
+		}
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), limit)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			abortWithError(c, apperror.From(ctx.Err()))
+		}
+	}
+}
@@ -1,69 +1,187 @@
 package delivery
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/fajritsaniy/golang-SHM/config"
+	"github.com/fajritsaniy/golang-SHM/delivery/api"
+	"github.com/fajritsaniy/golang-SHM/delivery/api/response"
 	"github.com/fajritsaniy/golang-SHM/delivery/controller"
 	"github.com/fajritsaniy/golang-SHM/delivery/middleware"
 	"github.com/fajritsaniy/golang-SHM/manager"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
 	"github.com/fajritsaniy/golang-SHM/usecase"
+	"github.com/fajritsaniy/golang-SHM/utils/logger"
 	"github.com/fajritsaniy/golang-SHM/utils/security"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
+// legacyRoutes configures the unversioned aliases of the v1 routes.
+type legacyRoutes struct {
+	Enabled      bool
+	DeprecatedAt time.Time
+	Sunset       time.Time
+}
+
+// routeDeprecations dates the v1 paths that later versions replaced.
+type routeDeprecations struct {
+	EmployeeCollection time.Time
+	VehicleImagePath   time.Time
+}
+
 type Server struct {
-	ucManager    manager.UseCaseManager
-	authUseCase  usecase.AuthenticationUseCase
-	tokenService security.AccessToken
-	engine       *gin.Engine
-	host         string
-	log          *logrus.Logger
+	infra           manager.InfraManager
+	ucManager       manager.UseCaseManager
+	authUseCase     usecase.AuthenticationUseCase
+	healthUseCase   usecase.HealthUseCase
+	tokenService    security.AccessToken
+	urlSigner       security.URLSigner
+	engine          *gin.Engine
+	host            string
+	shutdownTimeout time.Duration
+	requestTimeout  time.Duration
+	routeTimeouts   map[string]time.Duration
+	defaultLocale   string
+	metricsToken    string
+	legacyRoutes    legacyRoutes
+	deprecations    routeDeprecations
+	log             *logrus.Logger
+}
+
+func (s *Server) initMiddleware() {
+	s.engine.Use(middleware.RequestIDMiddleware(s.log))
+	s.engine.Use(middleware.LocaleMiddleware(s.defaultLocale))
+	s.engine.Use(middleware.TracingMiddleware())
+	s.engine.Use(middleware.LogRequestMiddleware(s.infra.RequestLog()))
+	s.engine.Use(middleware.MetricsMiddleware())
+	s.engine.Use(middleware.ErrorMiddleware())
+	s.engine.Use(middleware.TimeoutMiddleware(s.requestTimeout, s.routeTimeouts))
+	s.engine.NoRoute(func(c *gin.Context) {
+		_ = c.Error(apperror.NotFound(apperror.CodeRouteNotFound, "error.route_not_found"))
+	})
 }
 
 func (s *Server) initController() {
-	s.engine.Use(middleware.LogRequestMiddleware(s.log))
+	controller.NewHealthController(s.engine, s.healthUseCase)
+	controller.NewMetricsController(s.engine, s.metricsToken)
+	for _, version := range api.Versions {
+		s.initVersion(s.engine.Group(version.Prefix()), version)
+	}
+	if s.legacyRoutes.Enabled {
+		// the v1 routes stay at their unversioned paths until the sunset
+		legacy := s.engine.Group("", middleware.DeprecationMiddleware(middleware.Deprecation{
+			Since:  s.legacyRoutes.DeprecatedAt,
+			Sunset: s.legacyRoutes.Sunset,
+			Successor: func(c *gin.Context) string {
+				return api.V1.Prefix() + c.Request.URL.Path
+			},
+		}))
+		s.initVersion(legacy, api.V1)
+	}
+	// last, the docs only cover the routes registered before them
+	if _, err := controller.NewDocsController(s.engine); err != nil {
+		s.log.WithError(err).Warn("API docs are incomplete")
+	}
+}
+
+// initVersion registers the routes of version on r.
+func (s *Server) initVersion(r gin.IRouter, version api.Version) {
 	authMiddleware := middleware.NewTokenValidator(s.tokenService)
-	controller.NewVehicleController(s.engine, s.ucManager.VehicleUseCase(), authMiddleware)
-	controller.NewBrandController(s.engine, s.ucManager.BrandUseCase(), authMiddleware)
-	controller.NewCustomerController(s.engine, s.ucManager.CustomerUseCase(), authMiddleware)
-	controller.NewEmployeeController(s.engine, s.ucManager.EmployeeUseCase(), authMiddleware)
-	controller.NewTransactionController(s.engine, s.ucManager.TransactionUseCase(), authMiddleware)
-	controller.NewAuthController(s.engine, s.authUseCase)
+	controller.NewVehicleController(r, version, s.ucManager.VehicleUseCase(), authMiddleware, s.urlSigner)
+	controller.NewVehicleImageController(r, version, s.ucManager.VehicleImageUseCase(), authMiddleware, s.urlSigner, s.deprecations.VehicleImagePath)
+	controller.NewBrandController(r, s.ucManager.BrandUseCase(), authMiddleware)
+	controller.NewCustomerController(r, s.ucManager.CustomerUseCase(), authMiddleware)
+	controller.NewEmployeeController(r, version, s.ucManager.EmployeeUseCase(), authMiddleware, s.deprecations.EmployeeCollection)
+	controller.NewTransactionController(r, s.ucManager.TransactionUseCase(), authMiddleware)
+	controller.NewAuthController(r, s.authUseCase, authMiddleware)
+	controller.NewAuditController(r, s.ucManager.AuditUseCase(), authMiddleware)
 }
 
-func NewServer() *Server {
-	c, err := config.NewConfig()
-	if err != nil {
-		fmt.Println(err)
-	}
-	// infra manager
-	infraManager, _ := manager.NewInfraManager(c)
+func NewServer(c *config.Config, infraManager manager.InfraManager) *Server {
 	// repo manager
 	repoManager := manager.NewRepositoryManager(infraManager)
 	// use case manager
-	useCaseManager := manager.NewUseCaseManager(repoManager)
-	// token
-	tokenService := security.NewAccessToken(c.TokenConfig)
-	authUseCase := usecase.NewAuthenticationUseCase(repoManager.UserRepo(), tokenService)
+	useCaseManager := manager.NewUseCaseManager(infraManager, repoManager)
 
-	r := gin.Default()
+	if c.Env != "DEV" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	// gin's own text logger is replaced by the JSON access log
+	r := gin.New()
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logger.FromContext(c.Request.Context()).WithField("panic", recovered).Error("request panicked")
+		response.SendErrorResponse(c, apperror.Internal(fmt.Errorf("panic: %v", recovered)))
+	}))
 	host := fmt.Sprintf("%s:%s", c.ApiHost, c.ApiPort)
 	return &Server{
-		ucManager:    useCaseManager,
-		authUseCase:  authUseCase,
-		tokenService: tokenService,
-		engine:       r,
-		host:         host,
-		log:          infraManager.Log(),
+		infra:           infraManager,
+		ucManager:       useCaseManager,
+		authUseCase:     useCaseManager.AuthUseCase(),
+		healthUseCase:   useCaseManager.HealthUseCase(),
+		tokenService:    useCaseManager.TokenService(),
+		urlSigner:       useCaseManager.URLSigner(),
+		engine:          r,
+		host:            host,
+		shutdownTimeout: c.ShutdownTimeout,
+		requestTimeout:  c.RequestTimeout,
+		routeTimeouts:   c.RouteTimeouts,
+		defaultLocale:   c.DefaultLocale,
+		metricsToken:    c.MetricsToken,
+		legacyRoutes: legacyRoutes{
+			Enabled:      c.LegacyRoutes,
+			DeprecatedAt: c.LegacyRoutesDeprecatedAt,
+			Sunset:       c.LegacyRoutesSunset,
+		},
+		deprecations: routeDeprecations{
+			EmployeeCollection: c.EmployeeCollectionDeprecatedAt,
+			VehicleImagePath:   c.VehicleImagePathDeprecatedAt,
+		},
+		log: infraManager.Log(),
 	}
 }
 
-func (s *Server) Run() {
+// Run serves until SIGINT or SIGTERM, then stops taking new connections,
+// waits for in-flight requests and releases the infrastructure.
+func (s *Server) Run() error {
+	s.initMiddleware()
 	s.initController()
-	err := s.engine.Run(s.host)
+	httpServer := &http.Server{
+		Addr:              s.host,
+		Handler:           s.engine,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		s.log.Infof("listening on %s", s.host)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return errors.Join(fmt.Errorf("server stopped: %w", err), s.infra.Close())
+	case <-ctx.Done():
+	}
+
+	s.log.Info("shutting down, draining in-flight requests")
+	s.healthUseCase.Drain()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	err := httpServer.Shutdown(shutdownCtx)
 	if err != nil {
-		panic(err)
+		err = fmt.Errorf("graceful shutdown failed: %w", err)
 	}
+	return errors.Join(err, s.infra.Close())
 }
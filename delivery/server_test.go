@@ -0,0 +1,185 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/delivery/api/openapi"
+	"github.com/fajritsaniy/golang-SHM/delivery/middleware"
+	"github.com/fajritsaniy/golang-SHM/manager"
+	"github.com/fajritsaniy/golang-SHM/usecase"
+	"github.com/fajritsaniy/golang-SHM/utils/security"
+	"github.com/fajritsaniy/golang-SHM/utils/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// useCaseManagerStub hands out nil use cases, registering routes never calls
+// them.
+type useCaseManagerStub struct {
+	manager.UseCaseManager
+}
+
+func (useCaseManagerStub) VehicleUseCase() usecase.VehicleUseCase { return nil }
+func (useCaseManagerStub) VehicleImageUseCase() usecase.VehicleImageUseCase {
+	return vehicleImageUseCaseStub{}
+}
+func (useCaseManagerStub) BrandUseCase() usecase.BrandUseCase             { return nil }
+func (useCaseManagerStub) CustomerUseCase() usecase.CustomerUseCase       { return nil }
+func (useCaseManagerStub) EmployeeUseCase() usecase.EmployeeUseCase       { return nil }
+func (useCaseManagerStub) TransactionUseCase() usecase.TransactionUseCase { return nil }
+func (useCaseManagerStub) AuditUseCase() usecase.AuditUseCase             { return nil }
+
+// vehicleImageUseCaseStub serves the same image for every vehicle.
+type vehicleImageUseCaseStub struct {
+	usecase.VehicleImageUseCase
+}
+
+func (v vehicleImageUseCaseStub) Open(ctx context.Context, vehicleID string, imageID string, size string) (*storage.Object, error) {
+	return v.OpenPrimary(ctx, vehicleID)
+}
+
+func (vehicleImageUseCaseStub) OpenPrimary(ctx context.Context, vehicleID string) (*storage.Object, error) {
+	return &storage.Object{
+		Key:         "vehicles/" + vehicleID + ".png",
+		Size:        10,
+		ContentType: "image/png",
+		ModTime:     time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		ETag:        `"abc"`,
+		Body:        nopCloser{strings.NewReader("0123456789")},
+	}, nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+type ServerTestSuite struct {
+	suite.Suite
+	server *Server
+}
+
+func (suite *ServerTestSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middleware.ErrorMiddleware())
+	suite.server = &Server{
+		engine:    engine,
+		ucManager: useCaseManagerStub{},
+		legacyRoutes: legacyRoutes{
+			Enabled:      true,
+			DeprecatedAt: time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+			Sunset:       time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC),
+		},
+		deprecations: routeDeprecations{
+			EmployeeCollection: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+		},
+		urlSigner: security.NewURLSigner("secret", time.Hour),
+		log:       logrus.New(),
+	}
+	suite.server.initController()
+}
+
+func (suite *ServerTestSuite) TestEveryRouteIsDocumentedSuccess() {
+	_, err := openapi.Build(suite.server.engine.Routes())
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *ServerTestSuite) TestServeOpenAPIDocumentSuccess() {
+	recorder := suite.get("/openapi.json")
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+
+	var document openapi.Document
+	assert.NoError(suite.T(), json.Unmarshal(recorder.Body.Bytes(), &document))
+	assert.Equal(suite.T(), openapi.Version, document.OpenAPI)
+	operation := document.Paths["/api/v1/vehicles/{id}"]["get"]
+	if assert.NotNil(suite.T(), operation) {
+		assert.Equal(suite.T(), "id", operation.Parameters[0].Name)
+		assert.False(suite.T(), operation.Deprecated)
+	}
+	assert.True(suite.T(), document.Paths["/vehicles/{id}"]["get"].Deprecated)
+	assert.Contains(suite.T(), document.Components.Schemas, "Vehicle")
+	assert.NotEmpty(suite.T(), document.Paths["/api/v1/audit"]["get"].Security)
+}
+
+func (suite *ServerTestSuite) TestLegacyRouteIsDeprecatedSuccess() {
+	recorder := suite.get("/vehicles?page=0")
+	assert.Equal(suite.T(), "@1792195200", recorder.Header().Get("Deprecation"))
+	assert.Equal(suite.T(), "Fri, 30 Apr 2027 00:00:00 GMT", recorder.Header().Get("Sunset"))
+	assert.Equal(suite.T(), `</api/v1/vehicles>; rel="successor-version"`, recorder.Header().Get("Link"))
+
+	recorder = suite.get("/api/v1/vehicles?page=0")
+	assert.Empty(suite.T(), recorder.Header().Get("Deprecation"))
+}
+
+func (suite *ServerTestSuite) TestVersionsSideBySideSuccess() {
+	recorder := suite.get("/api/v1/employee")
+	assert.Equal(suite.T(), http.StatusUnauthorized, recorder.Code)
+	assert.Equal(suite.T(), "@1790812800", recorder.Header().Get("Deprecation"))
+	assert.Equal(suite.T(), `</api/v2/employees>; rel="successor-version"`, recorder.Header().Get("Link"))
+
+	recorder = suite.get("/api/v2/employees")
+	assert.Equal(suite.T(), http.StatusUnauthorized, recorder.Code)
+	assert.Empty(suite.T(), recorder.Header().Get("Deprecation"))
+
+	assert.Equal(suite.T(), http.StatusNotFound, suite.get("/api/v2/employee").Code)
+}
+
+func (suite *ServerTestSuite) TestSignedImageSuccess() {
+	target := "/api/v2/vehicles/1/image?" + suite.server.urlSigner.Sign("/vehicles/1/image").Encode()
+	recorder := suite.get(target)
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), "image/png", recorder.Header().Get("Content-Type"))
+	assert.Equal(suite.T(), "0123456789", recorder.Body.String())
+
+	recorder = suite.get(target, "Range", "bytes=6-")
+	assert.Equal(suite.T(), http.StatusPartialContent, recorder.Code)
+	assert.Equal(suite.T(), "6789", recorder.Body.String())
+
+	recorder = suite.get(target, "If-None-Match", `"abc"`)
+	assert.Equal(suite.T(), http.StatusNotModified, recorder.Code)
+
+	recorder = suite.get("/api/v2/vehicles/1/images/a?" + suite.server.urlSigner.Sign("/vehicles/1/images/a").Encode())
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+
+	recorder = suite.get("/api/v2/vehicles/1/images/a/thumbnail?" + suite.server.urlSigner.Sign("/vehicles/1/images/a/thumbnail").Encode())
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+}
+
+func (suite *ServerTestSuite) TestSignedImageOtherVehicleFail() {
+	recorder := suite.get("/api/v1/vehicles/2/image?" + suite.server.urlSigner.Sign("/vehicles/1/image").Encode())
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+
+	recorder = suite.get("/api/v1/vehicles/2/image")
+	assert.Equal(suite.T(), http.StatusUnauthorized, recorder.Code)
+
+	recorder = suite.get("/api/v1/vehicles/1/images/b?" + suite.server.urlSigner.Sign("/vehicles/1/images/a").Encode())
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+}
+
+// get requests target with the given header name and value pairs.
+func (suite *ServerTestSuite) get(target string, header ...string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		request.Header.Set(header[i], header[i+1])
+	}
+	suite.server.engine.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
@@ -1,31 +1,67 @@
 package manager
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/fajritsaniy/golang-SHM/config"
-	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/migration"
+	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/fajritsaniy/golang-SHM/utils/logger"
+	"github.com/fajritsaniy/golang-SHM/utils/metrics"
+	"github.com/fajritsaniy/golang-SHM/utils/notification"
+	"github.com/fajritsaniy/golang-SHM/utils/storage"
+	"github.com/fajritsaniy/golang-SHM/utils/tracing"
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 )
 
 type InfraManager interface {
 	Conn() *gorm.DB
-	Migrate(model ...any) error
+	Config() *config.Config
+	Migrate() error
 	Log() *logrus.Logger
+	// RequestLog writes to the request log file.
+	RequestLog() *logrus.Logger
 	LogFilePath() string
-	UploadLocation() string
+	// Storage is shared, so the memory backend keeps its objects between
+	// requests.
+	Storage() storage.Storage
+	Notifier() notification.Notifier
+	Ping(ctx context.Context) error
+	// Close releases the database pool and flushes the request log file.
+	Close() error
 }
 
 type infraManager struct {
-	db  *gorm.DB
-	cfg *config.Config
-	log *logrus.Logger
+	db         *gorm.DB
+	cfg        *config.Config
+	log        *logrus.Logger
+	requestLog *logrus.Logger
+	logFile    *os.File
+	storage    storage.Storage
+	// stopTracing flushes spans that were not exported yet
+	stopTracing func(context.Context) error
 }
 
-func (i *infraManager) UploadLocation() string {
-	return i.cfg.UploadLocation
+func (i *infraManager) Config() *config.Config {
+	return i.cfg
+}
+
+func (i *infraManager) Storage() storage.Storage {
+	return i.storage
+}
+
+func (i *infraManager) Notifier() notification.Notifier {
+	if i.cfg.NotifierConfig.Driver == "file" {
+		return notification.NewFileNotifier(i.cfg.NotifierConfig.FilePath)
+	}
+	return notification.NewLogNotifier(i.Log())
 }
 
 func (i *infraManager) LogFilePath() string {
@@ -33,22 +69,84 @@ func (i *infraManager) LogFilePath() string {
 }
 
 func (i *infraManager) Log() *logrus.Logger {
-	logger := logrus.New()
-	return logger
+	return i.log
 }
 
-func (i *infraManager) Conn() *gorm.DB {
-	return i.db
+func (i *infraManager) RequestLog() *logrus.Logger {
+	return i.requestLog
 }
 
-func (i *infraManager) Migrate(model ...any) error {
-	err := i.Conn().AutoMigrate(model...)
+func (i *infraManager) Ping(ctx context.Context) error {
+	sqlDB, err := i.db.DB()
 	if err != nil {
 		return err
 	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (i *infraManager) Close() error {
+	var errs []error
+	if sqlDB, err := i.db.DB(); err != nil {
+		errs = append(errs, err)
+	} else if err := sqlDB.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close database: %w", err))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := i.stopTracing(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to flush traces: %w", err))
+	}
+	if i.logFile != nil {
+		if err := i.logFile.Sync(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to flush request log: %w", err))
+		}
+		if err := i.logFile.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close request log: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (i *infraManager) initLog() error {
+	level := logrus.InfoLevel
+	if i.cfg.FileConfig.Env == "DEV" {
+		level = logrus.DebugLevel
+	}
+	if i.cfg.LogLevel != "" {
+		parsed, err := logrus.ParseLevel(i.cfg.LogLevel)
+		if err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL: %w", err)
+		}
+		level = parsed
+	}
+	i.log = logger.New(os.Stdout, level)
+	logger.SetDefault(i.log)
+
+	i.requestLog = logger.New(os.Stdout, logrus.InfoLevel)
+	if i.cfg.LogFilePath == "" {
+		return nil
+	}
+	file, err := os.OpenFile(i.cfg.LogFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to open request log: %w", err)
+	}
+	i.logFile = file
+	i.requestLog.SetOutput(file)
 	return nil
 }
 
+func (i *infraManager) Conn() *gorm.DB {
+	return i.db
+}
+
+func (i *infraManager) Migrate() error {
+	applied, err := migration.NewMigrator(i.Conn(), migration.Files()).Up()
+	for _, m := range applied {
+		i.Log().Infof("applied migration %s", m)
+	}
+	return err
+}
+
 func (i *infraManager) initDb() error {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		i.cfg.Host,
@@ -57,37 +155,61 @@ func (i *infraManager) initDb() error {
 		i.cfg.Password,
 		i.cfg.Name,
 	)
-	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// every query is logged in DEV, elsewhere only errors and slow queries
+	logLevel := gormlogger.Warn
+	if i.cfg.FileConfig.Env == "DEV" {
+		logLevel = gormlogger.Info
+	}
+	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:         logger.NewGormLogger(logLevel, 200*time.Millisecond),
+		TranslateError: true,
+	})
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("failed to connect to database %s at %s:%s: %w", i.cfg.Name, i.cfg.Host, i.cfg.Port, err)
 	}
-	i.db = conn
-	if i.cfg.FileConfig.Env == "MIGRATION" {
-		i.db = conn.Debug()
-		err := i.Migrate(
-			&model.Brand{},
-			&model.Vehicle{},
-			&model.UserCredential{},
-			&model.Customer{},
-			&model.Employee{},
-			&model.Transaction{},
-		)
-		if err != nil {
-			return err
-		}
-	} else if i.cfg.FileConfig.Env == "DEV" {
-		i.db = conn.Debug()
-	} else {
-		// production / release
+	if err := conn.Use(repository.NewAuditPlugin()); err != nil {
+		return err
+	}
+	if err := conn.Use(metrics.NewGormPlugin()); err != nil {
+		return err
 	}
+	if err := conn.Use(tracing.NewGormPlugin()); err != nil {
+		return err
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return err
+	}
+	if err := metrics.RegisterDB(sqlDB, i.cfg.Name); err != nil {
+		return err
+	}
+	i.db = conn
 	return nil
 }
 
 func NewInfraManager(cfg *config.Config) (InfraManager, error) {
-	conn := &infraManager{cfg: cfg}
-	err := conn.initDb()
+	store, err := storage.New(cfg.FileConfig)
 	if err != nil {
 		return nil, err
 	}
+	conn := &infraManager{cfg: cfg, storage: store}
+	if err := conn.initLog(); err != nil {
+		return nil, err
+	}
+	stopTracing, err := tracing.Setup(context.Background(), cfg.TracingConfig)
+	if err != nil {
+		if conn.logFile != nil {
+			conn.logFile.Close()
+		}
+		return nil, err
+	}
+	conn.stopTracing = stopTracing
+	if err := conn.initDb(); err != nil {
+		stopTracing(context.Background())
+		if conn.logFile != nil {
+			conn.logFile.Close()
+		}
+		return nil, err
+	}
 	return conn, nil
 }
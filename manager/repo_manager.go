@@ -6,23 +6,49 @@ type RepositoryManager interface {
 	// kumpulan repo disini
 	BrandRepo() repository.BrandRepository
 	VehicleRepo() repository.VehicleRepository
+	VehicleImageRepo() repository.VehicleImageRepository
 	CustomerRepo() repository.CustomerRepository
 	EmployeeRepo() repository.EmployeeRepository
 	TransactionRepo() repository.TransactionRepository
 	FileRepo() repository.FileRepository
 	UserRepo() repository.UserRepository
+	TokenRepo() repository.TokenRepository
+	PasswordResetRepo() repository.PasswordResetRepository
+	SecurityRepo() repository.SecurityRepository
+	AuditRepo() repository.AuditRepository
+	UnitOfWork() repository.UnitOfWork
 }
 
 type repositoryManager struct {
 	infra InfraManager
 }
 
+func (r *repositoryManager) TokenRepo() repository.TokenRepository {
+	return repository.NewTokenRepository(r.infra.Conn())
+}
+
+func (r *repositoryManager) PasswordResetRepo() repository.PasswordResetRepository {
+	return repository.NewPasswordResetRepository(r.infra.Conn())
+}
+
+func (r *repositoryManager) SecurityRepo() repository.SecurityRepository {
+	return repository.NewSecurityRepository(r.infra.Conn())
+}
+
+func (r *repositoryManager) AuditRepo() repository.AuditRepository {
+	return repository.NewAuditRepository(r.infra.Conn())
+}
+
+func (r *repositoryManager) UnitOfWork() repository.UnitOfWork {
+	return repository.NewUnitOfWork(r.infra.Conn())
+}
+
 func (r *repositoryManager) UserRepo() repository.UserRepository {
 	return repository.NewUserRepository(r.infra.Conn())
 }
 
 func (r *repositoryManager) FileRepo() repository.FileRepository {
-	return repository.NewFileRepository(r.infra.UploadLocation())
+	return repository.NewFileRepository(r.infra.Storage())
 }
 
 func (r *repositoryManager) CustomerRepo() repository.CustomerRepository {
@@ -45,6 +71,10 @@ func (r *repositoryManager) VehicleRepo() repository.VehicleRepository {
 	return repository.NewVehicleRepository(r.infra.Conn())
 }
 
+func (r *repositoryManager) VehicleImageRepo() repository.VehicleImageRepository {
+	return repository.NewVehicleImageRepository(r.infra.Conn())
+}
+
 func NewRepositoryManager(infra InfraManager) RepositoryManager {
 	return &repositoryManager{infra: infra}
 }
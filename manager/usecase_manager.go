@@ -2,18 +2,27 @@ package manager
 
 import (
 	"github.com/fajritsaniy/golang-SHM/usecase"
+	"github.com/fajritsaniy/golang-SHM/utils/imaging"
+	"github.com/fajritsaniy/golang-SHM/utils/security"
 )
 
 type UseCaseManager interface {
 	BrandUseCase() usecase.BrandUseCase
 	VehicleUseCase() usecase.VehicleUseCase
+	VehicleImageUseCase() usecase.VehicleImageUseCase
 	CustomerUseCase() usecase.CustomerUseCase
 	EmployeeUseCase() usecase.EmployeeUseCase
 	TransactionUseCase() usecase.TransactionUseCase
 	FileUseCase() usecase.FileUseCase
+	AuditUseCase() usecase.AuditUseCase
+	AuthUseCase() usecase.AuthenticationUseCase
+	TokenService() security.AccessToken
+	URLSigner() security.URLSigner
+	HealthUseCase() usecase.HealthUseCase
 }
 
 type useCaseManager struct {
+	infra       InfraManager
 	repoManager RepositoryManager
 }
 
@@ -30,7 +39,7 @@ func (u *useCaseManager) EmployeeUseCase() usecase.EmployeeUseCase {
 }
 
 func (u *useCaseManager) TransactionUseCase() usecase.TransactionUseCase {
-	return usecase.NewTransactionUseCase(u.repoManager.TransactionRepo(), u.VehicleUseCase(), u.EmployeeUseCase(), u.CustomerUseCase())
+	return usecase.NewTransactionUseCase(u.repoManager.TransactionRepo(), u.repoManager.UnitOfWork(), u.VehicleUseCase(), u.EmployeeUseCase(), u.CustomerUseCase())
 }
 
 func (u *useCaseManager) BrandUseCase() usecase.BrandUseCase {
@@ -38,9 +47,55 @@ func (u *useCaseManager) BrandUseCase() usecase.BrandUseCase {
 }
 
 func (u *useCaseManager) VehicleUseCase() usecase.VehicleUseCase {
-	return usecase.NewVehicleUseCase(u.repoManager.VehicleRepo(), u.BrandUseCase(), u.FileUseCase())
+	return usecase.NewVehicleUseCase(u.repoManager.VehicleRepo(), u.repoManager.UnitOfWork(), u.BrandUseCase(), u.FileUseCase(), u.imageLimits())
 }
 
-func NewUseCaseManager(repoManager RepositoryManager) UseCaseManager {
-	return &useCaseManager{repoManager: repoManager}
+func (u *useCaseManager) VehicleImageUseCase() usecase.VehicleImageUseCase {
+	return usecase.NewVehicleImageUseCase(u.repoManager.VehicleImageRepo(), u.repoManager.VehicleRepo(), u.repoManager.UnitOfWork(), u.FileUseCase(), u.imageLimits())
+}
+
+func (u *useCaseManager) imageLimits() imaging.Limits {
+	cfg := u.infra.Config()
+	return imaging.Limits{MaxBytes: cfg.ImageMaxSize, MaxPixels: cfg.ImageMaxPixels}
+}
+
+func (u *useCaseManager) AuditUseCase() usecase.AuditUseCase {
+	return usecase.NewAuditUseCase(u.repoManager.AuditRepo())
+}
+
+func (u *useCaseManager) TokenService() security.AccessToken {
+	return security.NewAccessToken(u.infra.Config().TokenConfig, u.repoManager.TokenRepo())
+}
+
+func (u *useCaseManager) URLSigner() security.URLSigner {
+	cfg := u.infra.Config()
+	return security.NewURLSigner(cfg.ImageURLSecret, cfg.ImageURLLifeTime)
+}
+
+func (u *useCaseManager) AuthUseCase() usecase.AuthenticationUseCase {
+	cfg := u.infra.Config()
+	return usecase.NewAuthenticationUseCase(
+		u.repoManager.UserRepo(),
+		u.repoManager.TokenRepo(),
+		u.repoManager.PasswordResetRepo(),
+		u.repoManager.EmployeeRepo(),
+		u.repoManager.CustomerRepo(),
+		u.repoManager.SecurityRepo(),
+		u.repoManager.UnitOfWork(),
+		u.TokenService(),
+		u.infra.Notifier(),
+		cfg.PasswordResetLifeTime,
+		cfg.LoginConfig,
+	)
+}
+
+func (u *useCaseManager) HealthUseCase() usecase.HealthUseCase {
+	return usecase.NewHealthUseCase(
+		usecase.HealthCheck{Name: "database", Check: u.infra.Ping},
+		usecase.HealthCheck{Name: "storage", Check: u.infra.Storage().Ping},
+	)
+}
+
+func NewUseCaseManager(infra InfraManager, repoManager RepositoryManager) UseCaseManager {
+	return &useCaseManager{infra: infra, repoManager: repoManager}
 }
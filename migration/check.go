@@ -0,0 +1,250 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+var (
+	createTablePattern = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\S+)\s*\((.*)\)$`)
+	alterTablePattern  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(\S+)\s+(.*)$`)
+	dropTablePattern   = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(.+?)(?:\s+CASCADE)?$`)
+	addColumnPattern   = regexp.MustCompile(`(?is)^ADD\s+(?:COLUMN\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(\S+)`)
+	dropColumnPattern  = regexp.MustCompile(`(?is)^DROP\s+(?:COLUMN\s+)?(?:IF\s+EXISTS\s+)?(\S+)`)
+	renameColPattern   = regexp.MustCompile(`(?is)^RENAME\s+(?:COLUMN\s+)?(\S+)\s+TO\s+(\S+)$`)
+	renameTablePattern = regexp.MustCompile(`(?is)^RENAME\s+TO\s+(\S+)$`)
+)
+
+// table level clauses that are not columns
+var constraintKeywords = map[string]bool{
+	"CONSTRAINT": true,
+	"PRIMARY":    true,
+	"UNIQUE":     true,
+	"FOREIGN":    true,
+	"CHECK":      true,
+	"EXCLUDE":    true,
+}
+
+// CheckModels replays the up migrations in source and compares the tables
+// and columns they build with the GORM models, so a model change without a
+// migration (or the other way round) is caught before it reaches a database.
+func CheckModels(source fs.FS, models ...any) error {
+	migrations, err := Load(source)
+	if err != nil {
+		return err
+	}
+	migrated := map[string]map[string]bool{}
+	for _, migration := range migrations {
+		for _, statement := range splitStatements(migration.Up) {
+			applyStatement(migrated, statement)
+		}
+	}
+
+	expected := map[string]map[string]bool{}
+	cache := &sync.Map{}
+	for _, model := range models {
+		parsed, err := schema.Parse(model, cache, schema.NamingStrategy{})
+		if err != nil {
+			return err
+		}
+		addSchema(expected, parsed)
+		for _, relationship := range parsed.Relationships.Relations {
+			if relationship.JoinTable != nil {
+				addSchema(expected, relationship.JoinTable)
+			}
+		}
+	}
+
+	var problems []string
+	for table, columns := range expected {
+		existing, ok := migrated[table]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("table %s is not created by any migration", table))
+			continue
+		}
+		for column := range columns {
+			if !existing[column] {
+				problems = append(problems, fmt.Sprintf("column %s.%s is missing from the migrations", table, column))
+			}
+		}
+		for column := range existing {
+			if !columns[column] {
+				problems = append(problems, fmt.Sprintf("column %s.%s has no model field", table, column))
+			}
+		}
+	}
+	for table := range migrated {
+		if _, ok := expected[table]; !ok {
+			problems = append(problems, fmt.Sprintf("table %s has no model", table))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New("models and migrations differ:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func addSchema(tables map[string]map[string]bool, parsed *schema.Schema) {
+	columns, ok := tables[parsed.Table]
+	if !ok {
+		columns = map[string]bool{}
+		tables[parsed.Table] = columns
+	}
+	for _, field := range parsed.Fields {
+		if field.DBName != "" {
+			columns[field.DBName] = true
+		}
+	}
+}
+
+func applyStatement(tables map[string]map[string]bool, statement string) {
+	if match := createTablePattern.FindStringSubmatch(statement); match != nil {
+		columns := map[string]bool{}
+		for _, definition := range splitTopLevel(match[2]) {
+			fields := strings.Fields(definition)
+			if len(fields) == 0 || constraintKeywords[strings.ToUpper(fields[0])] {
+				continue
+			}
+			columns[unquote(fields[0])] = true
+		}
+		tables[unquote(match[1])] = columns
+		return
+	}
+	if match := dropTablePattern.FindStringSubmatch(statement); match != nil {
+		for _, table := range strings.Split(match[1], ",") {
+			delete(tables, unquote(strings.TrimSpace(table)))
+		}
+		return
+	}
+	match := alterTablePattern.FindStringSubmatch(statement)
+	if match == nil {
+		return
+	}
+	table := unquote(match[1])
+	columns, ok := tables[table]
+	if !ok {
+		return
+	}
+	for _, action := range splitTopLevel(match[2]) {
+		action = strings.TrimSpace(action)
+		upper := strings.ToUpper(action)
+		switch {
+		case strings.HasPrefix(upper, "ADD CONSTRAINT"), strings.HasPrefix(upper, "DROP CONSTRAINT"):
+		case renameTablePattern.MatchString(action):
+			renamed := unquote(renameTablePattern.FindStringSubmatch(action)[1])
+			tables[renamed] = columns
+			delete(tables, table)
+			table = renamed
+		case renameColPattern.MatchString(action):
+			names := renameColPattern.FindStringSubmatch(action)
+			delete(columns, unquote(names[1]))
+			columns[unquote(names[2])] = true
+		case addColumnPattern.MatchString(action):
+			name := addColumnPattern.FindStringSubmatch(action)[1]
+			if !constraintKeywords[strings.ToUpper(name)] {
+				columns[unquote(name)] = true
+			}
+		case dropColumnPattern.MatchString(action):
+			delete(columns, unquote(dropColumnPattern.FindStringSubmatch(action)[1]))
+		}
+	}
+}
+
+func unquote(name string) string {
+	name = strings.Trim(name, `"`)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = strings.Trim(name[i+1:], `"`)
+	}
+	return strings.ToLower(name)
+}
+
+// splitStatements splits a script on semicolons, skipping comments, quoted
+// strings and dollar-quoted function bodies.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			current.WriteByte('\n')
+		case c == '\'':
+			end := strings.IndexByte(script[i+1:], '\'')
+			if end < 0 {
+				end = len(script) - i - 1
+			}
+			current.WriteString(script[i : i+end+2])
+			i += end + 1
+		case c == '$':
+			tagEnd := strings.IndexByte(script[i+1:], '$')
+			tag := ""
+			if tagEnd >= 0 {
+				tag = script[i : i+tagEnd+2]
+			}
+			if tag == "" || strings.ContainsAny(tag[1:len(tag)-1], " \t\n;") {
+				current.WriteByte(c)
+				continue
+			}
+			end := strings.Index(script[i+len(tag):], tag)
+			if end < 0 {
+				end = len(script) - i - len(tag)
+			} else {
+				end += len(tag)
+			}
+			current.WriteString(script[i : i+len(tag)+end])
+			i += len(tag) + end - 1
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+// splitTopLevel splits on commas that are not inside parentheses or quotes.
+func splitTopLevel(list string) []string {
+	var parts []string
+	depth := 0
+	quoted := false
+	start := 0
+	for i := 0; i < len(list); i++ {
+		switch list[i] {
+		case '\'':
+			quoted = !quoted
+		case '(':
+			if !quoted {
+				depth++
+			}
+		case ')':
+			if !quoted {
+				depth--
+			}
+		case ',':
+			if !quoted && depth == 0 {
+				parts = append(parts, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(list[start:]))
+}
@@ -0,0 +1,114 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"gorm.io/gorm"
+)
+
+// SourceDir is where `migrate create` writes new files, relative to the
+// repository root. They are embedded into the binary on the next build.
+const SourceDir = "migration/sql"
+
+const usage = `usage: migrate <command>
+
+commands:
+  up            apply all pending migrations
+  down [N]      roll back the last N migrations (default 1)
+  status        list migrations and check them against the models
+  create NAME   add an empty up/down pair to ` + SourceDir
+
+// Run executes one migrate subcommand. connect is only called by the
+// subcommands that need the database.
+func Run(args []string, connect func() (*gorm.DB, error), out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf(usage)
+	}
+
+	switch args[0] {
+	case "create":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: migrate create NAME")
+		}
+		upPath, downPath, err := Create(SourceDir, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created %s\ncreated %s\n", upPath, downPath)
+		return nil
+	case "up", "down", "status":
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], usage)
+	}
+
+	db, err := connect()
+	if err != nil {
+		return err
+	}
+	migrator := NewMigrator(db, Files())
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied %s\n", migration)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "no pending migrations")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", args[1])
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(out, "rolled back %s\n", migration)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		writer := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "MIGRATION\tSTATUS\tAPPLIED AT")
+		for _, status := range statuses {
+			state := "pending"
+			switch {
+			case status.Missing:
+				state = "applied, file missing"
+			case status.Modified:
+				state = "applied, file modified"
+			case status.Applied:
+				state = "applied"
+			}
+			appliedAt := "-"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", status.Migration, state, appliedAt)
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if err := CheckModels(Files(), model.Models()...); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "models match the migrations")
+	}
+	return nil
+}
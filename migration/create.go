@@ -0,0 +1,40 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var nonWordPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty up/down pair to dir, numbered after the newest
+// migration already there, and returns both paths.
+func Create(dir string, name string) (string, string, error) {
+	name = strings.Trim(nonWordPattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", fmt.Errorf("migration name is required")
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	var version int64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%04d_%s", version, name)
+	upPath := filepath.Join(dir, base+".up.sql")
+	downPath := filepath.Join(dir, base+".down.sql")
+	if err := os.WriteFile(upPath, []byte(fmt.Sprintf("-- %s: apply\n", base)), 0644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte(fmt.Sprintf("-- %s: revert\n", base)), 0644); err != nil {
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
@@ -0,0 +1,302 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// Files returns the migrations compiled into the binary.
+func Files() fs.FS {
+	sub, err := fs.Sub(files, "sql")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// lockID is the pg_advisory_xact_lock key taken while a migration runs, so
+// two instances starting together never apply the same file twice.
+const lockID = 7263541
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one numbered pair of up and down SQL files.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Status describes a migration file, a row in schema_migrations, or both.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+	// Modified means the file changed after it was applied.
+	Modified bool
+	// Missing means the migration was applied but its file is gone.
+	Missing bool
+}
+
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+type Migrator interface {
+	// Up applies every pending migration in version order.
+	Up() ([]Migration, error)
+	// Down rolls back the last steps applied migrations.
+	Down(steps int) ([]Migration, error)
+	Status() ([]Status, error)
+}
+
+type migrator struct {
+	db     *gorm.DB
+	source fs.FS
+}
+
+// Load reads and pairs the migration files in source, ordered by version.
+func Load(source fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(source, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(source, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("migration %s has no up script", migration)
+		}
+		sum := sha256.Sum256([]byte(migration.Up + "\n-- down --\n" + migration.Down))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func (m *migrator) ensureTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name varchar(255) NOT NULL,
+		checksum char(64) NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`).Error
+}
+
+func (m *migrator) state() ([]Migration, map[int64]schemaMigration, error) {
+	migrations, err := Load(m.source)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := m.ensureTable(); err != nil {
+		return nil, nil, err
+	}
+	var rows []schemaMigration
+	if err := m.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	applied := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return migrations, applied, nil
+}
+
+func (m *migrator) Up() ([]Migration, error) {
+	migrations, applied, err := m.state()
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range migrations {
+		if row, ok := applied[migration.Version]; ok && row.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("migration %s was changed after it was applied", migration)
+		}
+	}
+
+	var done []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		ran := false
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+				return err
+			}
+			// another instance may have applied it while we waited for the lock
+			var count int64
+			if err := tx.Model(&schemaMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			ran = true
+			return tx.Create(&schemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Checksum:  migration.Checksum,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %s failed: %w", migration, err)
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+func (m *migrator) Down(steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1")
+	}
+	migrations, applied, err := m.state()
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] > versions[j]
+	})
+	if steps > len(versions) {
+		steps = len(versions)
+	}
+
+	var done []Migration
+	for _, version := range versions[:steps] {
+		migration, ok := byVersion[version]
+		if !ok {
+			return done, fmt.Errorf("migration %04d_%s has no file to roll back with", version, applied[version].Name)
+		}
+		if strings.TrimSpace(migration.Down) == "" {
+			return done, fmt.Errorf("migration %s cannot be rolled back, its down script is empty", migration)
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Where("version = ?", version).Delete(&schemaMigration{}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback of %s failed: %w", migration, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+func (m *migrator) Status() ([]Status, error) {
+	migrations, applied, err := m.state()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	known := make(map[int64]bool, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		status := Status{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = row.Checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+	for version, row := range applied {
+		if known[version] {
+			continue
+		}
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, Status{
+			Migration: Migration{Version: version, Name: row.Name, Checksum: row.Checksum},
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+func NewMigrator(db *gorm.DB, source fs.FS) Migrator {
+	return &migrator{db: db, source: source}
+}
@@ -0,0 +1,174 @@
+package migration
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var appliedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var testFiles = fstest.MapFS{
+	"0001_init.up.sql":        {Data: []byte("CREATE TABLE a (id bigint);")},
+	"0001_init.down.sql":      {Data: []byte("DROP TABLE a;")},
+	"0002_add_name.up.sql":    {Data: []byte("ALTER TABLE a ADD COLUMN name text;")},
+	"0002_add_name.down.sql":  {Data: []byte("ALTER TABLE a DROP COLUMN name;")},
+	"0003_seed_data.up.sql":   {Data: []byte("INSERT INTO a (id) VALUES (1);")},
+	"0003_seed_data.down.sql": {Data: []byte("")},
+}
+
+type MigrationTestSuite struct {
+	suite.Suite
+	DB   *gorm.DB
+	mock sqlmock.Sqlmock
+}
+
+func (suite *MigrationTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.mock = mock
+	dialect := postgres.New(postgres.Config{
+		Conn: db,
+	})
+	suite.DB, err = gorm.Open(dialect)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *MigrationTestSuite) expectApplied(rows *sqlmock.Rows) {
+	suite.mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery(`SELECT \* FROM "schema_migrations" ORDER BY version`).
+		WillReturnRows(rows)
+}
+
+func (suite *MigrationTestSuite) appliedRows(versions ...int64) *sqlmock.Rows {
+	migrations, err := Load(testFiles)
+	assert.NoError(suite.T(), err)
+	rows := sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"})
+	for _, migration := range migrations {
+		for _, version := range versions {
+			if migration.Version == version {
+				rows.AddRow(migration.Version, migration.Name, migration.Checksum, appliedAt)
+			}
+		}
+	}
+	return rows
+}
+
+func (suite *MigrationTestSuite) TestLoadOrdersByVersionSuccess() {
+	migrations, err := Load(testFiles)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), migrations, 3)
+	assert.Equal(suite.T(), "0001_init", migrations[0].String())
+	assert.Equal(suite.T(), "0003_seed_data", migrations[2].String())
+	assert.NotEqual(suite.T(), migrations[0].Checksum, migrations[1].Checksum)
+}
+
+func (suite *MigrationTestSuite) TestLoadInvalidNameFail() {
+	_, err := Load(fstest.MapFS{"init.sql": {Data: []byte("SELECT 1;")}})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *MigrationTestSuite) TestUpAppliesPendingSuccess() {
+	suite.expectApplied(suite.appliedRows(1))
+	for _, up := range []string{`ALTER TABLE a ADD COLUMN name text`, `INSERT INTO a \(id\) VALUES \(1\)`} {
+		suite.mock.ExpectBegin()
+		suite.mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+		suite.mock.ExpectQuery(`SELECT count\(\*\) FROM "schema_migrations"`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		suite.mock.ExpectExec(up).WillReturnResult(sqlmock.NewResult(0, 0))
+		suite.mock.ExpectExec(`INSERT INTO "schema_migrations"`).WillReturnResult(sqlmock.NewResult(0, 1))
+		suite.mock.ExpectCommit()
+	}
+
+	applied, err := NewMigrator(suite.DB, testFiles).Up()
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), applied, 2)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigrationTestSuite) TestUpFailureRollsBackFail() {
+	suite.expectApplied(suite.appliedRows(1, 2))
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM "schema_migrations"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	suite.mock.ExpectExec(`INSERT INTO a`).WillReturnError(errors.New("duplicate key"))
+	suite.mock.ExpectRollback()
+
+	applied, err := NewMigrator(suite.DB, testFiles).Up()
+	assert.Error(suite.T(), err)
+	assert.Empty(suite.T(), applied)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigrationTestSuite) TestUpModifiedMigrationFail() {
+	rows := sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"}).
+		AddRow(1, "init", "0000", appliedAt)
+	suite.expectApplied(rows)
+
+	_, err := NewMigrator(suite.DB, testFiles).Up()
+	assert.ErrorContains(suite.T(), err, "changed after it was applied")
+}
+
+func (suite *MigrationTestSuite) TestDownRollsBackLatestSuccess() {
+	suite.expectApplied(suite.appliedRows(1, 2))
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec(`ALTER TABLE a DROP COLUMN name`).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectExec(`DELETE FROM "schema_migrations" WHERE version = \$1`).
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	reverted, err := NewMigrator(suite.DB, testFiles).Down(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "0002_add_name", reverted[0].String())
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *MigrationTestSuite) TestDownIrreversibleFail() {
+	suite.expectApplied(suite.appliedRows(1, 2, 3))
+
+	_, err := NewMigrator(suite.DB, testFiles).Down(1)
+	assert.ErrorContains(suite.T(), err, "cannot be rolled back")
+}
+
+func (suite *MigrationTestSuite) TestCreateNumbersAfterLatestSuccess() {
+	dir := suite.T().TempDir()
+	for name, file := range testFiles {
+		assert.NoError(suite.T(), os.WriteFile(filepath.Join(dir, name), file.Data, 0644))
+	}
+
+	upPath, downPath, err := Create(dir, "Add Vehicle Index")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), filepath.Join(dir, "0004_add_vehicle_index.up.sql"), upPath)
+	assert.FileExists(suite.T(), downPath)
+}
+
+func (suite *MigrationTestSuite) TestCheckModelsDriftFail() {
+	err := CheckModels(testFiles, &model.Brand{})
+	assert.ErrorContains(suite.T(), err, "table mst_brand is not created by any migration")
+	assert.ErrorContains(suite.T(), err, "table a has no model")
+}
+
+// The models AutoMigrate used to build must match the latest migration.
+func (suite *MigrationTestSuite) TestModelsMatchMigrationsSuccess() {
+	err := CheckModels(Files(), model.Models()...)
+	assert.NoError(suite.T(), err)
+}
+
+func TestMigrationTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrationTestSuite))
+}
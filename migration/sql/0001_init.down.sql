@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS trx_security_event;
+DROP TABLE IF EXISTS trx_login_attempt;
+DROP TABLE IF EXISTS trx_password_reset_token;
+DROP TABLE IF EXISTS trx_revoked_token;
+DROP TABLE IF EXISTS trx_refresh_token;
+DROP TABLE IF EXISTS trx_transaction;
+DROP TABLE IF EXISTS mst_employee;
+DROP TABLE IF EXISTS customer_vehicles;
+DROP TABLE IF EXISTS mst_customer;
+DROP TABLE IF EXISTS mst_user;
+DROP TABLE IF EXISTS mst_vehicle;
+DROP TABLE IF EXISTS mst_brand;
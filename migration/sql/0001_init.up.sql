@@ -0,0 +1,199 @@
+-- Baseline schema, equal to what AutoMigrate created before versioned
+-- migrations. IF NOT EXISTS lets databases created by AutoMigrate adopt it.
+
+CREATE TABLE IF NOT EXISTS mst_brand (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text
+);
+CREATE INDEX IF NOT EXISTS idx_mst_brand_deleted_at ON mst_brand (deleted_at);
+
+CREATE TABLE IF NOT EXISTS mst_vehicle (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    brand_id uuid,
+    model varchar(30),
+    production_year smallint,
+    color varchar(30),
+    is_automatic boolean,
+    stock bigint,
+    sale_price bigint,
+    status text,
+    img_path text,
+    url_path text,
+    version bigint NOT NULL DEFAULT 1,
+    CONSTRAINT fk_mst_brand_vehicles FOREIGN KEY (brand_id) REFERENCES mst_brand (id),
+    CONSTRAINT chk_mst_vehicle_stock CHECK (stock >= 0),
+    CONSTRAINT chk_mst_vehicle_sale_price CHECK (sale_price > 0),
+    CONSTRAINT chk_mst_vehicle_status CHECK (status IN ('baru', 'bekas'))
+);
+CREATE INDEX IF NOT EXISTS idx_mst_vehicle_deleted_at ON mst_vehicle (deleted_at);
+
+CREATE TABLE IF NOT EXISTS mst_user (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_name varchar(50) NOT NULL,
+    password text NOT NULL,
+    role varchar(20) NOT NULL DEFAULT 'customer',
+    is_active boolean DEFAULT true,
+    must_change_password boolean NOT NULL DEFAULT false,
+    failed_login_count bigint NOT NULL DEFAULT 0,
+    lockout_count bigint NOT NULL DEFAULT 0,
+    locked_until timestamptz,
+    CONSTRAINT uni_mst_user_user_name UNIQUE (user_name)
+);
+CREATE INDEX IF NOT EXISTS idx_mst_user_deleted_at ON mst_user (deleted_at);
+
+CREATE TABLE IF NOT EXISTS mst_customer (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    first_name varchar(30),
+    last_name varchar(30),
+    address text,
+    email varchar(30),
+    phone_number varchar(15),
+    bod timestamptz,
+    user_credential_id uuid,
+    CONSTRAINT uni_mst_customer_email UNIQUE (email),
+    CONSTRAINT uni_mst_customer_phone_number UNIQUE (phone_number),
+    CONSTRAINT fk_mst_customer_user_credential FOREIGN KEY (user_credential_id) REFERENCES mst_user (id)
+);
+CREATE INDEX IF NOT EXISTS idx_mst_customer_deleted_at ON mst_customer (deleted_at);
+
+CREATE TABLE IF NOT EXISTS customer_vehicles (
+    vehicle_id uuid,
+    customer_id uuid,
+    PRIMARY KEY (vehicle_id, customer_id),
+    CONSTRAINT fk_customer_vehicles_vehicle FOREIGN KEY (vehicle_id) REFERENCES mst_vehicle (id),
+    CONSTRAINT fk_customer_vehicles_customer FOREIGN KEY (customer_id) REFERENCES mst_customer (id)
+);
+
+CREATE TABLE IF NOT EXISTS mst_employee (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    first_name varchar(30),
+    last_name varchar(30),
+    address text,
+    email varchar(30),
+    phone_number varchar(15),
+    bod timestamptz,
+    position text,
+    salary bigint DEFAULT 0,
+    manager_id uuid,
+    user_credential_id uuid,
+    CONSTRAINT uni_mst_employee_email UNIQUE (email),
+    CONSTRAINT uni_mst_employee_phone_number UNIQUE (phone_number),
+    CONSTRAINT uni_mst_employee_user_credential_id UNIQUE (user_credential_id),
+    CONSTRAINT fk_mst_employee_manager FOREIGN KEY (manager_id) REFERENCES mst_employee (id),
+    CONSTRAINT fk_mst_employee_user_credential FOREIGN KEY (user_credential_id) REFERENCES mst_user (id)
+);
+CREATE INDEX IF NOT EXISTS idx_mst_employee_deleted_at ON mst_employee (deleted_at);
+
+CREATE TABLE IF NOT EXISTS trx_transaction (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    transaction_date timestamptz,
+    vehicle_id uuid,
+    customer_id uuid,
+    employee_id uuid,
+    type text,
+    qty bigint,
+    payment_amount bigint,
+    CONSTRAINT fk_trx_transaction_vehicle FOREIGN KEY (vehicle_id) REFERENCES mst_vehicle (id),
+    CONSTRAINT fk_trx_transaction_customer FOREIGN KEY (customer_id) REFERENCES mst_customer (id),
+    CONSTRAINT fk_trx_transaction_employee FOREIGN KEY (employee_id) REFERENCES mst_employee (id),
+    CONSTRAINT chk_trx_transaction_type CHECK (type IN ('online', 'offline'))
+);
+CREATE INDEX IF NOT EXISTS idx_trx_transaction_deleted_at ON trx_transaction (deleted_at);
+
+CREATE TABLE IF NOT EXISTS trx_refresh_token (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_credential_id text NOT NULL,
+    family_id text NOT NULL,
+    token_id text NOT NULL,
+    expires_at timestamptz,
+    revoked_at timestamptz,
+    replaced_by text,
+    CONSTRAINT uni_trx_refresh_token_token_id UNIQUE (token_id)
+);
+CREATE INDEX IF NOT EXISTS idx_trx_refresh_token_deleted_at ON trx_refresh_token (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_trx_refresh_token_user_credential_id ON trx_refresh_token (user_credential_id);
+CREATE INDEX IF NOT EXISTS idx_trx_refresh_token_family_id ON trx_refresh_token (family_id);
+
+CREATE TABLE IF NOT EXISTS trx_revoked_token (
+    token_id text PRIMARY KEY,
+    expires_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_trx_revoked_token_expires_at ON trx_revoked_token (expires_at);
+
+CREATE TABLE IF NOT EXISTS trx_password_reset_token (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_credential_id text NOT NULL,
+    token_hash text NOT NULL,
+    expires_at timestamptz,
+    used_at timestamptz,
+    CONSTRAINT uni_trx_password_reset_token_token_hash UNIQUE (token_hash)
+);
+CREATE INDEX IF NOT EXISTS idx_trx_password_reset_token_deleted_at ON trx_password_reset_token (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_trx_password_reset_token_user_credential_id ON trx_password_reset_token (user_credential_id);
+
+CREATE TABLE IF NOT EXISTS trx_login_attempt (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_name varchar(50),
+    client_ip varchar(45),
+    success boolean
+);
+CREATE INDEX IF NOT EXISTS idx_trx_login_attempt_deleted_at ON trx_login_attempt (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_trx_login_attempt_user_name ON trx_login_attempt (user_name);
+CREATE INDEX IF NOT EXISTS idx_trx_login_attempt_client_ip ON trx_login_attempt (client_ip);
+
+CREATE TABLE IF NOT EXISTS trx_security_event (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    event varchar(30),
+    user_name varchar(50),
+    client_ip varchar(45),
+    actor varchar(50),
+    detail text
+);
+CREATE INDEX IF NOT EXISTS idx_trx_security_event_deleted_at ON trx_security_event (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_trx_security_event_event ON trx_security_event (event);
+CREATE INDEX IF NOT EXISTS idx_trx_security_event_user_name ON trx_security_event (user_name);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    actor varchar(50),
+    actor_id varchar(36),
+    entity_table varchar(50),
+    entity_id varchar(36),
+    action varchar(10) NOT NULL,
+    changes jsonb,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity_table, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
@@ -0,0 +1 @@
+ALTER TABLE mst_vehicle ADD COLUMN IF NOT EXISTS url_path text;
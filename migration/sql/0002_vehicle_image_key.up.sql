@@ -0,0 +1,7 @@
+-- img_path holds a storage key now, e.g. vehicles/<id>.jpg. Files written by
+-- the old disk upload sit directly in UPLOAD_LOCATION, so their key is the
+-- file name.
+UPDATE mst_vehicle SET img_path = regexp_replace(img_path, '^.*/', '') WHERE img_path LIKE '%/%' AND img_path NOT LIKE 'vehicles/%';
+
+-- the image URL is signed per response and no longer stored
+ALTER TABLE mst_vehicle DROP COLUMN IF EXISTS url_path;
@@ -0,0 +1,3 @@
+ALTER TABLE mst_vehicle ADD COLUMN IF NOT EXISTS img_path text;
+UPDATE mst_vehicle SET img_path = i.storage_key FROM mst_vehicle_image i WHERE i.vehicle_id = mst_vehicle.id AND i.is_primary AND i.deleted_at IS NULL;
+DROP TABLE IF EXISTS mst_vehicle_image;
@@ -0,0 +1,21 @@
+-- a vehicle has a gallery of images instead of the single img_path
+CREATE TABLE IF NOT EXISTS mst_vehicle_image (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    vehicle_id uuid NOT NULL,
+    storage_key text NOT NULL,
+    position bigint NOT NULL DEFAULT 0,
+    is_primary boolean NOT NULL DEFAULT false,
+    caption varchar(100),
+    CONSTRAINT fk_mst_vehicle_images FOREIGN KEY (vehicle_id) REFERENCES mst_vehicle (id)
+);
+CREATE INDEX IF NOT EXISTS idx_mst_vehicle_image_deleted_at ON mst_vehicle_image (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_mst_vehicle_image_vehicle_id ON mst_vehicle_image (vehicle_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_mst_vehicle_image_primary ON mst_vehicle_image (vehicle_id) WHERE is_primary AND deleted_at IS NULL;
+
+INSERT INTO mst_vehicle_image (created_at, updated_at, vehicle_id, storage_key, position, is_primary)
+SELECT now(), now(), id, img_path, 0, true FROM mst_vehicle WHERE img_path IS NOT NULL AND img_path <> '';
+
+ALTER TABLE mst_vehicle DROP COLUMN IF EXISTS img_path;
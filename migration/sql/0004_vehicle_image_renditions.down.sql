@@ -0,0 +1,4 @@
+ALTER TABLE mst_vehicle_image
+    DROP COLUMN IF EXISTS renditions,
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width;
@@ -0,0 +1,6 @@
+-- uploads are measured and rendered in smaller sizes, images uploaded before
+-- keep no renditions and are served as they are
+ALTER TABLE mst_vehicle_image
+    ADD COLUMN IF NOT EXISTS width bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS height bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS renditions jsonb;
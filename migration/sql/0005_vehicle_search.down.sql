@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_mst_vehicle_sale_price;
+DROP INDEX IF EXISTS idx_mst_vehicle_production_year;
+DROP INDEX IF EXISTS idx_mst_vehicle_brand_id;
+DROP INDEX IF EXISTS idx_mst_vehicle_color_trgm;
+DROP INDEX IF EXISTS idx_mst_vehicle_model_trgm;
+DROP INDEX IF EXISTS idx_mst_brand_name_trgm;
+-- pg_trgm stays, other schemas may use it
@@ -0,0 +1,11 @@
+-- vehicle search matches words anywhere in the brand name, model and colour,
+-- ILIKE '%word%' on these columns is served by trigram indexes
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_mst_brand_name_trgm ON mst_brand USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_mst_vehicle_model_trgm ON mst_vehicle USING gin (model gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_mst_vehicle_color_trgm ON mst_vehicle USING gin (color gin_trgm_ops);
+
+-- catalog filters
+CREATE INDEX IF NOT EXISTS idx_mst_vehicle_brand_id ON mst_vehicle (brand_id);
+CREATE INDEX IF NOT EXISTS idx_mst_vehicle_production_year ON mst_vehicle (production_year);
+CREATE INDEX IF NOT EXISTS idx_mst_vehicle_sale_price ON mst_vehicle (sale_price);
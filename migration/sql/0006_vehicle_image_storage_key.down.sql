@@ -0,0 +1,6 @@
+-- images uploaded again since keep their new key
+UPDATE mst_vehicle v SET img_path = l.img_path
+FROM legacy_vehicle_img_path l
+WHERE l.vehicle_id = v.id AND v.img_path = regexp_replace(l.img_path, '^.*/', '');
+
+DROP TABLE IF EXISTS legacy_vehicle_img_path;
@@ -0,0 +1,17 @@
+-- img_path holds a storage key since uploads go through the storage backends.
+-- Files written by the old disk upload sit directly in UPLOAD_LOCATION, so
+-- their key is the file name. The paths are kept for the down migration.
+CREATE TABLE IF NOT EXISTS legacy_vehicle_img_path (
+    vehicle_id uuid PRIMARY KEY,
+    img_path text NOT NULL,
+    CONSTRAINT fk_legacy_vehicle_img_path_vehicle FOREIGN KEY (vehicle_id) REFERENCES mst_vehicle (id) ON DELETE CASCADE
+);
+
+INSERT INTO legacy_vehicle_img_path (vehicle_id, img_path)
+SELECT id, img_path FROM mst_vehicle
+WHERE img_path LIKE '%/%' AND img_path NOT LIKE 'vehicles/%'
+ON CONFLICT (vehicle_id) DO NOTHING;
+
+UPDATE mst_vehicle v SET img_path = regexp_replace(l.img_path, '^.*/', '')
+FROM legacy_vehicle_img_path l
+WHERE l.vehicle_id = v.id AND v.img_path = l.img_path;
@@ -0,0 +1,3 @@
+ALTER TABLE trx_refresh_token
+    DROP COLUMN IF EXISTS access_token_id,
+    DROP COLUMN IF EXISTS access_expires_at;
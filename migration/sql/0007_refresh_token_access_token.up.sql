@@ -0,0 +1,5 @@
+-- the access token issued with each refresh token, so a role change can
+-- revoke the ones still valid
+ALTER TABLE trx_refresh_token
+    ADD COLUMN IF NOT EXISTS access_token_id text,
+    ADD COLUMN IF NOT EXISTS access_expires_at timestamptz;
@@ -0,0 +1 @@
+ALTER TABLE mst_vehicle_image DROP CONSTRAINT IF EXISTS uni_mst_vehicle_image_position;
@@ -0,0 +1,13 @@
+-- concurrent uploads could give two images of a vehicle the same position,
+-- number every gallery again before positions become unique. The constraint
+-- is checked at commit so a reorder can swap positions. The primary image is
+-- unique per vehicle since 0003 (idx_mst_vehicle_image_primary).
+UPDATE mst_vehicle_image i SET position = r.position
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY vehicle_id ORDER BY position, created_at, id) - 1 AS position
+    FROM mst_vehicle_image
+) r
+WHERE r.id = i.id AND i.position <> r.position;
+
+ALTER TABLE mst_vehicle_image
+    ADD CONSTRAINT uni_mst_vehicle_image_position UNIQUE (vehicle_id, position) DEFERRABLE INITIALLY DEFERRED;
@@ -0,0 +1,171 @@
+// Package apperror holds the errors use cases and repositories return. The
+// kind of an error decides its HTTP status, the code is a stable identifier
+// clients can switch on. Messages are i18n catalog keys, rendered in the
+// locale of the request.
+package apperror
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	// KindBadRequest is a request that could not be read at all, e.g.
+	// malformed JSON. Readable requests with wrong values are KindValidation.
+	KindBadRequest
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindLocked
+	KindTooManyRequests
+	KindTimeout
+)
+
+// Generic codes, for errors that do not need one of their own.
+const (
+	CodeInternal       = "INTERNAL_ERROR"
+	CodeNotFound       = "NOT_FOUND"
+	CodeConflict       = "CONFLICT"
+	CodeValidation     = "VALIDATION_FAILED"
+	CodeInvalidBody    = "INVALID_REQUEST_BODY"
+	CodeUnauthorized   = "UNAUTHORIZED"
+	CodeForbidden      = "FORBIDDEN"
+	CodeTimeout        = "REQUEST_TIMEOUT"
+	CodeRouteNotFound  = "ROUTE_NOT_FOUND"
+	internalErrMessage = "error.internal"
+)
+
+type Error struct {
+	Kind Kind
+	Code string
+	// Message is a catalog key, or plain text for messages that are not
+	// translated. Args fill its placeholders.
+	Message string
+	Args    i18n.Args
+	// Fields maps request fields to what is wrong with them, for validation
+	// errors. The messages are rendered like Message, with the same Args.
+	Fields map[string]string
+	// Err is the underlying cause. It is logged but never sent to clients.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil && e.Kind == KindInternal {
+		return e.Err.Error()
+	}
+	return e.Text(i18n.English)
+}
+
+// Text renders the message in locale.
+func (e *Error) Text(locale string) string {
+	return i18n.T(locale, e.Message, e.Args)
+}
+
+// FieldTexts renders the field messages in locale.
+func (e *Error) FieldTexts(locale string) map[string]string {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	fields := make(map[string]string, len(e.Fields))
+	for field, message := range e.Fields {
+		fields[field] = i18n.T(locale, message, e.Args)
+	}
+	return fields
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors by code, so errors.Is(err, model.ErrAccountLocked) holds
+// for an error built with the same code but a more specific message.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// With returns a copy of e whose message is filled with args.
+func (e *Error) With(args i18n.Args) *Error {
+	withArgs := *e
+	withArgs.Args = args
+	return &withArgs
+}
+
+// Wrap returns a copy of e with err as its cause.
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+func New(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NotFound(code string, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code string, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func BadRequest(code string, message string) *Error {
+	return New(KindBadRequest, code, message)
+}
+
+func Validation(code string, message string, fields map[string]string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+// FromValidation turns the result of an ozzo Validate into a validation
+// error with one entry per field.
+func FromValidation(err error) *Error {
+	var fieldErrors validation.Errors
+	if !errors.As(err, &fieldErrors) {
+		return Validation(CodeValidation, err.Error(), nil)
+	}
+	fields := make(map[string]string, len(fieldErrors))
+	for field, fieldErr := range fieldErrors {
+		fields[field] = fieldErr.Error()
+	}
+	return Validation(CodeValidation, "error.validation_failed", fields)
+}
+
+func Unauthorized(code string, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code string, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: internalErrMessage, Err: err}
+}
+
+// From finds the *Error in err's chain. A deadline that ran out becomes a
+// timeout, anything else an internal error wrapping err.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Kind: KindTimeout, Code: CodeTimeout, Message: "error.timeout", Err: err}
+	}
+	return Internal(err)
+}
+
+// KindOf returns the kind of err, KindInternal for errors of other types.
+func KindOf(err error) Kind {
+	return From(err).Kind
+}
@@ -0,0 +1,82 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AppErrorTestSuite struct {
+	suite.Suite
+}
+
+func (suite *AppErrorTestSuite) TestFromSuccess() {
+	notFound := NotFound("BRAND_NOT_FOUND", "brand.not_found")
+	cases := []struct {
+		name string
+		err  error
+		kind Kind
+		code string
+	}{
+		{name: "app error", err: notFound, kind: KindNotFound, code: "BRAND_NOT_FOUND"},
+		{name: "wrapped app error", err: fmt.Errorf("find brand: %w", notFound), kind: KindNotFound, code: "BRAND_NOT_FOUND"},
+		{name: "deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), kind: KindTimeout, code: CodeTimeout},
+		{name: "plain error", err: errors.New("connection refused"), kind: KindInternal, code: CodeInternal},
+	}
+	for _, tc := range cases {
+		appErr := From(tc.err)
+		assert.Equal(suite.T(), tc.kind, appErr.Kind, tc.name)
+		assert.Equal(suite.T(), tc.code, appErr.Code, tc.name)
+	}
+	// internal errors keep the cause for the log
+	assert.Equal(suite.T(), "connection refused", From(errors.New("connection refused")).Error())
+}
+
+func (suite *AppErrorTestSuite) TestIsSuccess() {
+	locked := New(KindLocked, "ACCOUNT_LOCKED", "auth.account_locked")
+	cases := []struct {
+		name   string
+		err    error
+		target error
+		is     bool
+	}{
+		{name: "same error", err: locked, target: locked, is: true},
+		{name: "same code, other message", err: New(KindLocked, "ACCOUNT_LOCKED", "auth.account_locked_until"), target: locked, is: true},
+		{name: "with args", err: locked.With(map[string]any{"until": "tomorrow"}), target: locked, is: true},
+		{name: "wrapped", err: fmt.Errorf("login: %w", locked), target: locked, is: true},
+		{name: "other code", err: Forbidden(CodeForbidden, "error.forbidden"), target: locked, is: false},
+		{name: "plain error", err: errors.New("locked"), target: locked, is: false},
+	}
+	for _, tc := range cases {
+		assert.Equal(suite.T(), tc.is, errors.Is(tc.err, tc.target), tc.name)
+	}
+}
+
+func (suite *AppErrorTestSuite) TestWrapSuccess() {
+	cause := errors.New("record not found")
+	cases := []struct {
+		name  string
+		err   *Error
+		text  string
+		cause error
+	}{
+		{name: "not found", err: NotFound(CodeNotFound, "plain message"), text: "plain message", cause: cause},
+		{name: "internal", err: Internal(nil), text: "record not found", cause: cause},
+	}
+	for _, tc := range cases {
+		wrapped := tc.err.Wrap(tc.cause)
+		assert.ErrorIs(suite.T(), wrapped, cause, tc.name)
+		assert.ErrorIs(suite.T(), wrapped, tc.err, tc.name)
+		assert.Equal(suite.T(), tc.text, wrapped.Error(), tc.name)
+		// the original is left as it was
+		assert.Nil(suite.T(), tc.err.Err, tc.name)
+	}
+}
+
+func TestAppErrorTestSuite(t *testing.T) {
+	suite.Run(t, new(AppErrorTestSuite))
+}
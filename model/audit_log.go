@@ -0,0 +1,72 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+)
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditLog is an append-only record of one change to one row. Rows are
+// written by the audit GORM callbacks and are never updated or deleted.
+type AuditLog struct {
+	ID          string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	Actor       string    `gorm:"size:50;index" json:"actor"`
+	ActorID     string    `gorm:"size:36" json:"actorId"`
+	EntityTable string    `gorm:"size:50;index:idx_audit_log_entity" json:"entityTable"`
+	EntityID    string    `gorm:"size:36;index:idx_audit_log_entity" json:"entityId"`
+	Action      string    `gorm:"size:10;not null" json:"action"`
+	Changes     JSON      `gorm:"type:jsonb" json:"changes"`
+	CreatedAt   time.Time `gorm:"index" json:"createdAt"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+var ErrAuditLogImmutable = apperror.Forbidden("AUDIT_LOG_IMMUTABLE", "audit.immutable")
+
+// JSON holds raw JSON that is stored in a jsonb column and rendered as-is in
+// responses instead of as an escaped string.
+type JSON json.RawMessage
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[0:0], v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return fmt.Errorf("unsupported JSON value: %T", value)
+	}
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	*j = append((*j)[0:0], data...)
+	return nil
+}
@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
 
 type Customer struct {
 	BaseModel
@@ -18,3 +22,7 @@ type Customer struct {
 func (Customer) TableName() string {
 	return "mst_customer"
 }
+
+func (c Customer) Validate() error {
+	return validation.ValidateStruct(&c, personRules(&c.FirstName, &c.LastName, &c.Email, &c.PhoneNumber, &c.Bod)...)
+}
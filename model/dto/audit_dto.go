@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// AuditLogFilter narrows GET /audit. Empty fields are not filtered on.
+type AuditLogFilter struct {
+	Entity   string
+	EntityID string
+	Actor    string
+	From     *time.Time
+	To       *time.Time
+}
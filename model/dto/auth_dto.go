@@ -0,0 +1,70 @@
+package dto
+
+import validation "github.com/go-ozzo/ozzo-validation"
+
+type TokenPair struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (r LoginRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Username, validation.Required),
+		validation.Field(&r.Password, validation.Required),
+	)
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (r RefreshTokenRequest) Validate() error {
+	return validation.ValidateStruct(&r, validation.Field(&r.RefreshToken, validation.Required))
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword"`
+}
+
+func (r ChangePasswordRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.OldPassword, validation.Required),
+		validation.Field(&r.NewPassword, validation.Required),
+	)
+}
+
+type ForgotPasswordRequest struct {
+	Username string `json:"username"`
+}
+
+func (r ForgotPasswordRequest) Validate() error {
+	return validation.ValidateStruct(&r, validation.Field(&r.Username, validation.Required))
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+func (r ResetPasswordRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Token, validation.Required),
+		validation.Field(&r.NewPassword, validation.Required),
+	)
+}
+
+type UpdateUsernameRequest struct {
+	Username string `json:"username"`
+}
+
+func (r UpdateUsernameRequest) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Username, validation.Required, validation.Length(3, 50)),
+	)
+}
@@ -0,0 +1,13 @@
+package dto
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+// HealthReport is the body of /readyz. Checks maps each dependency to "up"
+// or to the reason it is down.
+type HealthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+// VehicleFilter narrows GET /vehicles. Empty fields are not filtered on, the
+// ranges include their bounds.
+type VehicleFilter struct {
+	BrandID     string
+	Status      string
+	IsAutomatic *bool
+	Color       string
+	MinYear     int
+	MaxYear     int
+	MinPrice    int
+	MaxPrice    int
+	InStock     bool
+}
+
+// FacetCount is the number of vehicles with one value of a filter. Label
+// names the value when it is an ID.
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+// VehicleFacets counts the vehicles per filter value. Each facet applies
+// every filter but its own, so the counts show what choosing another value
+// would find.
+type VehicleFacets struct {
+	Brands        []FacetCount `json:"brands"`
+	Statuses      []FacetCount `json:"statuses"`
+	Transmissions []FacetCount `json:"transmissions"`
+	Colors        []FacetCount `json:"colors"`
+}
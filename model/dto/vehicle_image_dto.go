@@ -0,0 +1,12 @@
+package dto
+
+import validation "github.com/go-ozzo/ozzo-validation"
+
+// VehicleImageOrderRequest lists every image of a vehicle in its new order.
+type VehicleImageOrderRequest struct {
+	ImageIDs []string `json:"imageIds"`
+}
+
+func (r VehicleImageOrderRequest) Validate() error {
+	return validation.ValidateStruct(&r, validation.Field(&r.ImageIDs, validation.Required))
+}
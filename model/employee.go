@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
 
 type Employee struct {
 	BaseModel
@@ -21,3 +25,17 @@ type Employee struct {
 func (Employee) TableName() string {
 	return "mst_employee"
 }
+
+func (e Employee) Validate() error {
+	rules := personRules(&e.FirstName, &e.LastName, &e.Email, &e.PhoneNumber, &e.Bod)
+	managerRules := []validation.Rule{validation.NilOrNotEmpty}
+	if e.ID != "" {
+		managerRules = append(managerRules, validation.NotIn(e.ID).Error("validation.self_manager"))
+	}
+	rules = append(rules,
+		validation.Field(&e.Position, validation.Required, validation.Length(1, 50)),
+		validation.Field(&e.Salary, validation.Min(0)),
+		validation.Field(&e.ManagerID, managerRules...),
+	)
+	return validation.ValidateStruct(&e, rules...)
+}
@@ -0,0 +1,23 @@
+package model
+
+// Models lists every model stored in the database. The migration check
+// compares it with the schema the SQL migrations build, so a new model or
+// column needs a migration as well.
+func Models() []any {
+	return []any{
+		&Brand{},
+		&Vehicle{},
+		&LegacyVehicleImagePath{},
+		&VehicleImage{},
+		&UserCredential{},
+		&Customer{},
+		&Employee{},
+		&Transaction{},
+		&RefreshToken{},
+		&RevokedToken{},
+		&PasswordResetToken{},
+		&LoginAttempt{},
+		&SecurityEvent{},
+		&AuditLog{},
+	}
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// PasswordResetToken stores only the SHA-256 hash of the token that was sent
+// to the user. A token can be used once, before ExpiresAt.
+type PasswordResetToken struct {
+	BaseModel
+	UserCredentialID string     `gorm:"not null;index" json:"userCredentialId"`
+	TokenHash        string     `gorm:"unique;not null" json:"-"`
+	ExpiresAt        time.Time  `json:"expiresAt"`
+	UsedAt           *time.Time `json:"usedAt"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "trx_password_reset_token"
+}
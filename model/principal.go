@@ -0,0 +1,43 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type principalContextKey struct{}
+
+// Principal is the authenticated caller of a request, built from a verified
+// access token.
+type Principal struct {
+	UserID     string
+	Username   string
+	Role       string
+	EmployeeID string
+	CustomerID string
+	// MustChangePassword is set until the user replaces an initial or
+	// administrator-issued password.
+	MustChangePassword bool
+	TokenID            string
+	ExpiresAt          time.Time
+}
+
+func (p Principal) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// WithPrincipal stores the caller on ctx so layers below the controllers,
+// such as the audit trail, know who is acting.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+	return principal, ok
+}
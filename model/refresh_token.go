@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+)
+
+// RefreshToken is one issued refresh token. Every rotation creates a new row
+// in the same family, so a reused token can revoke the whole chain.
+type RefreshToken struct {
+	BaseModel
+	UserCredentialID string     `gorm:"not null;index" json:"userCredentialId"`
+	FamilyID         string     `gorm:"not null;index" json:"familyId"`
+	TokenID          string     `gorm:"unique;not null" json:"tokenId"`
+	ExpiresAt        time.Time  `json:"expiresAt"`
+	RevokedAt        *time.Time `json:"revokedAt"`
+	ReplacedBy       string     `json:"replacedBy"`
+	// AccessTokenID is the jti of the access token issued alongside, kept so
+	// it can be revoked before it expires.
+	AccessTokenID   string    `json:"accessTokenId"`
+	AccessExpiresAt time.Time `json:"accessExpiresAt"`
+}
+
+func (RefreshToken) TableName() string {
+	return "trx_refresh_token"
+}
+
+// RevokedToken lists access tokens that were logged out before they expired.
+type RevokedToken struct {
+	TokenID   string    `gorm:"primaryKey" json:"tokenId"`
+	ExpiresAt time.Time `gorm:"index" json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (RevokedToken) TableName() string {
+	return "trx_revoked_token"
+}
+
+var ErrRefreshTokenReused = apperror.Unauthorized("REFRESH_TOKEN_REUSED", "auth.refresh_token_reused")
@@ -0,0 +1,42 @@
+package model
+
+import "github.com/fajritsaniy/golang-SHM/model/apperror"
+
+const (
+	SecurityEventLockout    = "lockout"
+	SecurityEventUnlock     = "unlock"
+	SecurityEventIPThrottle = "ip_throttle"
+	// SecurityEventPasswordSet is an administrator replacing a password
+	SecurityEventPasswordSet = "password_set"
+)
+
+// LoginAttempt is written for every /login call, successful or not.
+type LoginAttempt struct {
+	BaseModel
+	UserName string `gorm:"size:50;index" json:"username"`
+	ClientIP string `gorm:"size:45;index" json:"clientIp"`
+	Success  bool   `json:"success"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "trx_login_attempt"
+}
+
+// SecurityEvent records lockouts and unlocks for later review.
+type SecurityEvent struct {
+	BaseModel
+	Event    string `gorm:"size:30;index" json:"event"`
+	UserName string `gorm:"size:50;index" json:"username"`
+	ClientIP string `gorm:"size:45" json:"clientIp"`
+	Actor    string `gorm:"size:50" json:"actor"`
+	Detail   string `json:"detail"`
+}
+
+func (SecurityEvent) TableName() string {
+	return "trx_security_event"
+}
+
+var (
+	ErrAccountLocked        = apperror.New(apperror.KindLocked, "ACCOUNT_LOCKED", "auth.account_locked")
+	ErrTooManyLoginAttempts = apperror.New(apperror.KindTooManyRequests, "TOO_MANY_LOGIN_ATTEMPTS", "auth.too_many_login_attempts")
+)
@@ -1,6 +1,15 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	TransactionTypeOnline  = "online"
+	TransactionTypeOffline = "offline"
+)
 
 type Transaction struct {
 	BaseModel
@@ -17,7 +26,18 @@ type Transaction struct {
 }
 
 func (t *Transaction) IsValidType() bool {
-	return t.Type == "online" || t.Type == "offline"
+	return t.Type == TransactionTypeOnline || t.Type == TransactionTypeOffline
+}
+
+// Validate checks what the client sends. The employee may be left out, it is
+// then taken from the caller.
+func (t *Transaction) Validate() error {
+	return validation.ValidateStruct(t,
+		validation.Field(&t.VehicleID, validation.Required),
+		validation.Field(&t.CustomerID, validation.Required),
+		validation.Field(&t.Type, validation.Required, validation.In(TransactionTypeOnline, TransactionTypeOffline)),
+		validation.Field(&t.Qty, validation.Required, validation.Min(1)),
+	)
 }
 
 func (Transaction) TableName() string {
@@ -1,12 +1,54 @@
 package model
 
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	RoleAdmin    = "admin"
+	RoleManager  = "manager"
+	RoleSales    = "sales"
+	RoleCustomer = "customer"
+)
+
+const (
+	MinPasswordLength = 8
+	// bcrypt ignores everything after 72 bytes
+	maxPasswordLength = 72
+)
+
 type UserCredential struct {
 	BaseModel
 	UserName string `gorm:"unique;size:50;not null" json:"username"`
 	Password string `gorm:"not null" json:"password"`
+	Role     string `gorm:"size:20;not null;default:'customer'" json:"role"`
 	IsActive bool   `gorm:"default:true"`
+	// MustChangePassword blocks every endpoint except the password change
+	// until the user replaces the password they were given.
+	MustChangePassword bool       `gorm:"not null;default:false" json:"mustChangePassword"`
+	FailedLoginCount   int        `gorm:"not null;default:0" json:"-"`
+	LockoutCount       int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil        *time.Time `json:"lockedUntil,omitempty"`
 }
 
 func (UserCredential) TableName() string {
 	return "mst_user"
 }
+
+func (u *UserCredential) IsValidRole() bool {
+	return u.Role == RoleAdmin || u.Role == RoleManager || u.Role == RoleSales || u.Role == RoleCustomer
+}
+
+// Validate checks the values that were sent. The password is optional here,
+// whether one is required depends on the operation.
+func (u UserCredential) Validate() error {
+	return validation.ValidateStruct(&u,
+		validation.Field(&u.UserName, validation.Required, validation.Length(3, 50)),
+		validation.Field(&u.Password,
+			validation.Length(MinPasswordLength, maxPasswordLength),
+			validation.NotIn(u.UserName).Error("validation.password_is_username")),
+		validation.Field(&u.Role, validation.In(RoleAdmin, RoleManager, RoleSales, RoleCustomer)),
+	)
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+)
+
+// phoneNumberPattern accepts Indonesian mobile numbers written as 08xx,
+// 628xx or +628xx, 10 to 13 digits in the local form.
+var phoneNumberPattern = regexp.MustCompile(`^(\+62|62|0)8[1-9][0-9]{7,10}$`)
+
+// minAge is the age at which a driving licence can be issued.
+const minAge = 17
+
+// personRules are the rules customers and employees share. The limits follow
+// the column sizes.
+func personRules(firstName *string, lastName *string, email *string, phoneNumber *string, bod *time.Time) []*validation.FieldRules {
+	return []*validation.FieldRules{
+		validation.Field(firstName, validation.Required, validation.Length(1, 30)),
+		validation.Field(lastName, validation.Length(0, 30)),
+		validation.Field(email, validation.Required, validation.Length(0, 30), is.Email),
+		validation.Field(phoneNumber, validation.Required, validation.Match(phoneNumberPattern).Error("validation.phone_number")),
+		validation.Field(bod, validation.Required, validation.Max(time.Now().AddDate(-minAge, 0, 0)).Error("validation.min_age")),
+	}
+}
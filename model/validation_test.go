@@ -0,0 +1,128 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ValidationTestSuite struct {
+	suite.Suite
+}
+
+// fieldErrors is the message key per failing field, nil when valid.
+func fieldErrors(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+	return apperror.FromValidation(err).Fields
+}
+
+func validCustomer() Customer {
+	return Customer{
+		FirstName:   "Budi",
+		Email:       "budi@mail.com",
+		PhoneNumber: "081234567890",
+		Bod:         time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func validVehicle() Vehicle {
+	return Vehicle{
+		BrandID:        "b1",
+		Model:          "Avanza",
+		ProductionYear: 2020,
+		Color:          "Silver",
+		SalePrice:      250000000,
+		Status:         VehicleStatusNew,
+	}
+}
+
+func (suite *ValidationTestSuite) TestPhoneNumberSuccess() {
+	cases := map[string]bool{
+		"081234567890":   true,
+		"6281234567890":  true,
+		"+6281234567890": true,
+		"0812345678":     true,
+		"08123456789012": false, // too long
+		"081234567":      false, // too short
+		"0212345678":     false, // landline
+		"0801234567":     false,
+		"+6181234567890": false,
+		"0812-3456-7890": false,
+	}
+	for phone, valid := range cases {
+		customer := validCustomer()
+		customer.PhoneNumber = phone
+		errs := fieldErrors(customer.Validate())
+		if valid {
+			assert.Nil(suite.T(), errs, phone)
+		} else {
+			assert.Equal(suite.T(), "validation.phone_number", errs["phoneNumber"], phone)
+		}
+	}
+}
+
+func (suite *ValidationTestSuite) TestMinAgeSuccess() {
+	customer := validCustomer()
+	customer.Bod = time.Now().AddDate(-minAge, 0, -1)
+	assert.NoError(suite.T(), customer.Validate())
+
+	customer.Bod = time.Now().AddDate(-minAge, 0, 1)
+	assert.Equal(suite.T(), "validation.min_age", fieldErrors(customer.Validate())["bod"])
+}
+
+func (suite *ValidationTestSuite) TestProductionYearSuccess() {
+	cases := map[int]bool{
+		minProductionYear - 1: false,
+		minProductionYear:     true,
+		time.Now().Year() + 1: true,
+		time.Now().Year() + 2: false,
+	}
+	for year, valid := range cases {
+		vehicle := validVehicle()
+		vehicle.ProductionYear = year
+		_, failed := fieldErrors(vehicle.Validate())["productionYear"]
+		assert.Equal(suite.T(), !valid, failed, year)
+	}
+}
+
+func (suite *ValidationTestSuite) TestVehicleVersionRequiredFail() {
+	// new vehicles get their version on insert
+	vehicle := validVehicle()
+	assert.NoError(suite.T(), vehicle.Validate())
+
+	vehicle.ID = "v1"
+	assert.Equal(suite.T(), "validation.version_required", fieldErrors(vehicle.Validate())["version"])
+	vehicle.Version = 3
+	assert.NoError(suite.T(), vehicle.Validate())
+}
+
+func (suite *ValidationTestSuite) TestEmployeeSelfManagerFail() {
+	employee := Employee{
+		BaseModel:   BaseModel{ID: "e1"},
+		FirstName:   "Siti",
+		Email:       "siti@mail.com",
+		PhoneNumber: "081234567891",
+		Bod:         time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Position:    "Sales",
+	}
+	managerID := "e2"
+	employee.ManagerID = &managerID
+	assert.NoError(suite.T(), employee.Validate())
+
+	managerID = "e1"
+	assert.Equal(suite.T(), "validation.self_manager", fieldErrors(employee.Validate())["managerID"])
+}
+
+func (suite *ValidationTestSuite) TestPasswordIsUsernameFail() {
+	user := UserCredential{UserName: "budi-santoso", Password: "budi-santoso"}
+	assert.Equal(suite.T(), "validation.password_is_username", fieldErrors(user.Validate())["password"])
+}
+
+func TestValidationTestSuite(t *testing.T) {
+	suite.Run(t, new(ValidationTestSuite))
+}
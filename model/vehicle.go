@@ -1,35 +1,85 @@
 package model
 
 import (
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	VehicleStatusNew  = "baru"
+	VehicleStatusUsed = "bekas"
+)
+
+// the first production cars date from the late 1880s, nothing older is sold
+const minProductionYear = 1900
+
 type Vehicle struct {
-	BrandID        string     `json:"brandId"`
-	Brand          Brand      `json:"brand"`
-	Model          string     `gorm:"varchar;size:30" json:"model"`
-	ProductionYear int        `gorm:"size:4" json:"productionYear"`
-	Color          string     `gorm:"varchar;size:30" json:"color"`
-	IsAutomatic    bool       `json:"isAutomatic"`
-	Stock          int        `gorm:"check:stock >= 0" json:"stock"`
-	SalePrice      int        `gorm:"check:sale_price > 0" json:"salePrice"`
-	Status         string     `gorm:"check:status IN ('baru', 'bekas')" json:"status"`
-	Customers      []Customer `gorm:"many2many:customer_vehicles;" json:"customers,omitempty"`
-	ImgPath        string     `json:"imgPath,omitempty"`
-	UrlPath        string     `json:"urlPath"`
+	BrandID        string            `json:"brandId"`
+	Brand          Brand             `json:"brand"`
+	Model          string            `gorm:"varchar;size:30" json:"model"`
+	ProductionYear int               `gorm:"size:4" json:"productionYear"`
+	Color          string            `gorm:"varchar;size:30" json:"color"`
+	IsAutomatic    bool              `json:"isAutomatic"`
+	Stock          int               `gorm:"check:stock >= 0" json:"stock"`
+	SalePrice      int               `gorm:"check:sale_price > 0" json:"salePrice"`
+	Status         string            `gorm:"check:status IN ('baru', 'bekas')" json:"status"`
+	Customers      []Customer        `gorm:"many2many:customer_vehicles;" json:"customers,omitempty"`
+	Images         []VehicleImage    `gorm:"foreignKey:VehicleID" json:"images,omitempty"`
+	PrimaryImage   *VehicleImage     `gorm:"foreignKey:VehicleID" json:"primaryImage,omitempty"` // lists load it instead of Images
+	UrlPath        string            `gorm:"-" json:"urlPath,omitempty"`                         // signed link to the primary image, set per response
+	RenditionUrls  map[string]string `gorm:"-" json:"renditionUrls,omitempty"`                   // smaller sizes of the primary image, set per response
+	Version        int               `gorm:"not null;default:1" json:"version"`
 	BaseModel
 }
 
+var (
+	ErrVehicleOutOfStock      = apperror.Conflict("VEHICLE_OUT_OF_STOCK", "vehicle.out_of_stock")
+	ErrVehicleVersionConflict = apperror.Conflict("VEHICLE_VERSION_CONFLICT", "vehicle.version_conflict")
+)
+
 func (v *Vehicle) TableName() string {
 	return "mst_vehicle"
 }
 
+// LegacyVehicleImagePath keeps the disk path an image had before img_path
+// became a storage key, so that conversion can be rolled back.
+type LegacyVehicleImagePath struct {
+	VehicleID string `gorm:"primaryKey"`
+	ImgPath   string `gorm:"not null"`
+}
+
+func (LegacyVehicleImagePath) TableName() string {
+	return "legacy_vehicle_img_path"
+}
+
 func (v *Vehicle) IsValidStatus() bool {
-	return v.Status == "baru" || v.Status == "bekas"
+	return v.Status == VehicleStatusNew || v.Status == VehicleStatusUsed
+}
+
+func (v *Vehicle) Validate() error {
+	// updates are optimistic, the client has to send the version it read
+	var versionRules []validation.Rule
+	if v.ID != "" {
+		versionRules = append(versionRules, validation.Required.Error("validation.version_required"))
+	}
+	return validation.ValidateStruct(v,
+		validation.Field(&v.BrandID, validation.Required),
+		validation.Field(&v.Model, validation.Required, validation.Length(1, 30)),
+		validation.Field(&v.ProductionYear, validation.Required, validation.Min(minProductionYear), validation.Max(time.Now().Year()+1)),
+		validation.Field(&v.Color, validation.Required, validation.Length(1, 30)),
+		validation.Field(&v.Stock, validation.Min(0)),
+		validation.Field(&v.SalePrice, validation.Required, validation.Min(1)),
+		validation.Field(&v.Status, validation.Required, validation.In(VehicleStatusNew, VehicleStatusUsed)),
+		validation.Field(&v.Version, versionRules...),
+	)
 }
 
 func (v *Vehicle) BeforeCreate(tx *gorm.DB) error {
 	v.ID = uuid.New().String()
+	v.Version = 1
 	return nil
 }
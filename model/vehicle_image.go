@@ -0,0 +1,43 @@
+package model
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxVehicleImages bounds the gallery of one vehicle.
+const MaxVehicleImages = 20
+
+// VehicleImage is one photo in the gallery of a vehicle. Position orders the
+// gallery from 0 and is unique per vehicle, exactly one image of a vehicle
+// with images is primary.
+// Renditions holds the storage key of every smaller size, by size name.
+type VehicleImage struct {
+	BaseModel
+	VehicleID     string            `gorm:"type:uuid;not null;index" json:"vehicleId"`
+	StorageKey    string            `gorm:"not null" json:"-"`
+	Position      int               `gorm:"not null;default:0" json:"position"`
+	IsPrimary     bool              `gorm:"not null;default:false" json:"isPrimary"`
+	Caption       string            `gorm:"size:100" json:"caption"`
+	Width         int               `gorm:"not null;default:0" json:"width"`
+	Height        int               `gorm:"not null;default:0" json:"height"`
+	Renditions    map[string]string `gorm:"type:jsonb;serializer:json" json:"-"`
+	UrlPath       string            `gorm:"-" json:"urlPath,omitempty"`       // signed link to the image, set per response
+	RenditionUrls map[string]string `gorm:"-" json:"renditionUrls,omitempty"` // signed links by size name, set per response
+}
+
+func (VehicleImage) TableName() string {
+	return "mst_vehicle_image"
+}
+
+func (i *VehicleImage) Validate() error {
+	return validation.ValidateStruct(i,
+		validation.Field(&i.Caption, validation.Length(0, 100)),
+	)
+}
+
+func (i *VehicleImage) BeforeCreate(tx *gorm.DB) error {
+	i.ID = uuid.New().String()
+	return nil
+}
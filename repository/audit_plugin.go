@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// auditedTables are the tables whose changes end up in audit_log.
+var auditedTables = map[string]bool{
+	"mst_brand":       true,
+	"mst_vehicle":     true,
+	"mst_customer":    true,
+	"mst_employee":    true,
+	"mst_user":        true,
+	"trx_transaction": true,
+}
+
+// ignoredAuditColumns change on their own and would only add noise. Login
+// counters are tracked as security events instead.
+var ignoredAuditColumns = map[string]bool{
+	"updated_at":         true,
+	"failed_login_count": true,
+	"lockout_count":      true,
+	"locked_until":       true,
+}
+
+var redactedAuditColumns = map[string]bool{
+	"password": true,
+}
+
+const (
+	auditBeforeKey = "audit:before"
+	auditRedacted  = "[REDACTED]"
+	auditSystem    = "system"
+)
+
+type auditPlugin struct{}
+
+func (p *auditPlugin) Name() string {
+	return "audit"
+}
+
+func (p *auditPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:snapshot_update", p.snapshot); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:snapshot_delete", p.snapshot); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("audit:after_create", p.afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("audit:after_update", p.afterUpdate); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").Register("audit:after_delete", p.afterDelete)
+}
+
+// snapshot loads the rows an update or delete is about to touch, so the
+// after callbacks can diff them. It runs inside the same transaction.
+func (p *auditPlugin) snapshot(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	if db.Statement.Table == (model.AuditLog{}).TableName() {
+		db.AddError(model.ErrAuditLogImmutable)
+		return
+	}
+	if !auditedTables[db.Statement.Table] {
+		return
+	}
+
+	query := db.Session(&gorm.Session{NewDB: true}).Table(db.Statement.Table)
+	where, hasWhere := db.Statement.Clauses["WHERE"]
+	if hasWhere {
+		query = query.Clauses(where.Expression)
+	}
+	id := primaryKeyOf(db)
+	if id != "" {
+		query = query.Where("id = ?", id)
+	}
+	if !hasWhere && id == "" {
+		// gorm refuses global updates and deletes, nothing to snapshot
+		return
+	}
+	if db.Statement.Schema != nil && db.Statement.Schema.LookUpField("DeletedAt") != nil && !db.Statement.Unscoped {
+		query = query.Where("deleted_at IS NULL")
+	}
+
+	var rows []map[string]interface{}
+	if err := query.Find(&rows).Error; err != nil {
+		db.AddError(fmt.Errorf("failed to read audit snapshot: %w", err))
+		return
+	}
+	db.InstanceSet(auditBeforeKey, rows)
+}
+
+func (p *auditPlugin) afterCreate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.RowsAffected == 0 || db.Statement.Schema == nil ||
+		!auditedTables[db.Statement.Table] {
+		return
+	}
+
+	var created []reflect.Value
+	switch db.Statement.ReflectValue.Kind() {
+	case reflect.Struct:
+		created = append(created, db.Statement.ReflectValue)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < db.Statement.ReflectValue.Len(); i++ {
+			created = append(created, reflect.Indirect(db.Statement.ReflectValue.Index(i)))
+		}
+	}
+	for _, value := range created {
+		row := map[string]interface{}{}
+		for _, field := range db.Statement.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			fieldValue, _ := field.ValueOf(db.Statement.Context, value)
+			row[field.DBName] = fieldValue
+		}
+		after := normalizeColumns(row)
+		p.write(db, model.AuditActionCreate, fmt.Sprint(after["id"]), nil, redactColumns(after))
+	}
+}
+
+func (p *auditPlugin) afterUpdate(db *gorm.DB) {
+	before, ok := p.changedRows(db)
+	if !ok {
+		return
+	}
+
+	ids := make([]interface{}, 0, len(before))
+	for _, row := range before {
+		ids = append(ids, row["id"])
+	}
+	var afterRows []map[string]interface{}
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table(db.Statement.Table).
+		Where("id IN ?", ids).
+		Find(&afterRows).Error
+	if err != nil {
+		db.AddError(fmt.Errorf("failed to read audit snapshot: %w", err))
+		return
+	}
+	afterByID := map[string]map[string]interface{}{}
+	for _, row := range afterRows {
+		row = normalizeColumns(row)
+		afterByID[fmt.Sprint(row["id"])] = row
+	}
+
+	for _, row := range before {
+		oldRow := normalizeColumns(row)
+		id := fmt.Sprint(oldRow["id"])
+		newRow, found := afterByID[id]
+		if !found {
+			continue
+		}
+		oldDiff, newDiff := diffColumns(oldRow, newRow)
+		if len(newDiff) == 0 {
+			continue
+		}
+		p.write(db, model.AuditActionUpdate, id, redactColumns(oldDiff), redactColumns(newDiff))
+	}
+}
+
+func (p *auditPlugin) afterDelete(db *gorm.DB) {
+	before, ok := p.changedRows(db)
+	if !ok {
+		return
+	}
+	for _, row := range before {
+		oldRow := normalizeColumns(row)
+		p.write(db, model.AuditActionDelete, fmt.Sprint(oldRow["id"]), redactColumns(oldRow), nil)
+	}
+}
+
+func (p *auditPlugin) changedRows(db *gorm.DB) ([]map[string]interface{}, bool) {
+	if db.Error != nil || db.Statement.RowsAffected == 0 || !auditedTables[db.Statement.Table] {
+		return nil, false
+	}
+	value, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return nil, false
+	}
+	rows, ok := value.([]map[string]interface{})
+	return rows, ok && len(rows) > 0
+}
+
+func (p *auditPlugin) write(db *gorm.DB, action string, entityID string, before, after map[string]interface{}) {
+	changes, err := json.Marshal(map[string]interface{}{
+		"before": before,
+		"after":  after,
+	})
+	if err != nil {
+		db.AddError(fmt.Errorf("failed to encode audit changes: %w", err))
+		return
+	}
+
+	entry := &model.AuditLog{
+		Actor:       auditSystem,
+		EntityTable: db.Statement.Table,
+		EntityID:    entityID,
+		Action:      action,
+		Changes:     model.JSON(changes),
+	}
+	if principal, ok := model.PrincipalFromContext(db.Statement.Context); ok {
+		entry.Actor = principal.Username
+		entry.ActorID = principal.UserID
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Omit(clause.Associations).Create(entry).Error; err != nil {
+		db.AddError(fmt.Errorf("failed to write audit log: %w", err))
+	}
+}
+
+func primaryKeyOf(db *gorm.DB) string {
+	schema := db.Statement.Schema
+	if schema == nil || schema.PrioritizedPrimaryField == nil || db.Statement.ReflectValue.Kind() != reflect.Struct {
+		return ""
+	}
+	value, zero := schema.PrioritizedPrimaryField.ValueOf(db.Statement.Context, db.Statement.ReflectValue)
+	if zero {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// normalizeColumns turns driver values such as raw uuids into what the
+// API shows for the same column.
+func normalizeColumns(row map[string]interface{}) map[string]interface{} {
+	columns := make(map[string]interface{}, len(row))
+	for column, value := range row {
+		switch v := value.(type) {
+		case []byte:
+			value = string(v)
+		case [16]byte:
+			value = uuid.UUID(v).String()
+		}
+		columns[column] = value
+	}
+	return columns
+}
+
+func redactColumns(row map[string]interface{}) map[string]interface{} {
+	for column, value := range row {
+		if redactedAuditColumns[column] && value != nil && value != "" {
+			row[column] = auditRedacted
+		}
+	}
+	return row
+}
+
+// diffColumns keeps only the columns whose value changed.
+func diffColumns(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	oldDiff := map[string]interface{}{}
+	newDiff := map[string]interface{}{}
+	for column, newValue := range after {
+		if ignoredAuditColumns[column] {
+			continue
+		}
+		oldValue := before[column]
+		oldJSON, _ := json.Marshal(oldValue)
+		newJSON, _ := json.Marshal(newValue)
+		if string(oldJSON) == string(newJSON) {
+			continue
+		}
+		oldDiff[column] = oldValue
+		newDiff[column] = newValue
+	}
+	return oldDiff, newDiff
+}
+
+// NewAuditPlugin records every create, update and delete on the audited
+// tables in audit_log, with the principal on the statement context as actor.
+func NewAuditPlugin() gorm.Plugin {
+	return &auditPlugin{}
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type AuditPluginTestSuite struct {
+	suite.Suite
+	DB   *gorm.DB
+	mock sqlmock.Sqlmock
+	ctx  context.Context
+}
+
+func (suite *AuditPluginTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.mock = mock
+	dialect := postgres.New(postgres.Config{
+		Conn: db,
+	})
+	suite.DB, err = gorm.Open(dialect)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.DB.Use(NewAuditPlugin()))
+	suite.ctx = model.WithPrincipal(context.Background(), model.Principal{UserID: "u1", Username: "admin"})
+}
+
+// auditChanges captures the changes column of the audit insert.
+type auditChanges struct {
+	value map[string]map[string]interface{}
+}
+
+func (a *auditChanges) Match(v driver.Value) bool {
+	raw, ok := v.(string)
+	return ok && json.Unmarshal([]byte(raw), &a.value) == nil
+}
+
+func (suite *AuditPluginTestSuite) TestDeleteWritesAuditLogSuccess() {
+	changes := &auditChanges{}
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT \* FROM "mst_brand" WHERE id=\$1 AND deleted_at IS NULL`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "Honda"))
+	suite.mock.ExpectExec(`UPDATE "mst_brand" SET "deleted_at"`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery(`INSERT INTO "audit_log"`).
+		WithArgs("admin", "u1", "mst_brand", "1", model.AuditActionDelete, changes, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a1"))
+	suite.mock.ExpectCommit()
+
+	err := NewBrandRepository(suite.DB).Delete(suite.ctx, "1")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+	assert.Equal(suite.T(), "Honda", changes.value["before"]["name"])
+	assert.Nil(suite.T(), changes.value["after"])
+}
+
+func (suite *AuditPluginTestSuite) TestUpdateWritesOnlyChangedColumnsSuccess() {
+	changes := &auditChanges{}
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT \* FROM "mst_vehicle" WHERE \(id = \$1 AND stock >= \$2\) AND deleted_at IS NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock", "sale_price", "updated_at"}).
+			AddRow("v1", 3, 250000000, time.Now()))
+	suite.mock.ExpectExec(`UPDATE "mst_vehicle" SET "stock"`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery(`SELECT \* FROM "mst_vehicle" WHERE id IN \(\$1\)`).
+		WithArgs("v1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock", "sale_price", "updated_at"}).
+			AddRow("v1", 2, 250000000, time.Now()))
+	suite.mock.ExpectQuery(`INSERT INTO "audit_log"`).
+		WithArgs("admin", "u1", "mst_vehicle", "v1", model.AuditActionUpdate, changes, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a1"))
+	suite.mock.ExpectCommit()
+
+	err := NewVehicleRepository(suite.DB).UpdateStock(suite.ctx, 1, "v1")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+	assert.Equal(suite.T(), map[string]interface{}{"stock": float64(3)}, changes.value["before"])
+	assert.Equal(suite.T(), map[string]interface{}{"stock": float64(2)}, changes.value["after"])
+}
+
+func (suite *AuditPluginTestSuite) TestCreateRedactsPasswordSuccess() {
+	changes := &auditChanges{}
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`INSERT INTO "mst_user"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("u2"))
+	suite.mock.ExpectQuery(`INSERT INTO "audit_log"`).
+		WithArgs("admin", "u1", "mst_user", "u2", model.AuditActionCreate, changes, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a1"))
+	suite.mock.ExpectCommit()
+
+	user := model.UserCredential{UserName: "budi@mail.com", Password: "hash", Role: model.RoleSales}
+	err := suite.DB.WithContext(suite.ctx).Create(&user).Error
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+	assert.Equal(suite.T(), "[REDACTED]", changes.value["after"]["password"])
+	assert.Equal(suite.T(), "budi@mail.com", changes.value["after"]["user_name"])
+}
+
+func (suite *AuditPluginTestSuite) TestUpdateAuditLogFail() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectRollback()
+
+	err := suite.DB.Model(&model.AuditLog{}).Where("id = ?", "a1").Update("actor", "someone").Error
+	assert.ErrorIs(suite.T(), err, model.ErrAuditLogImmutable)
+}
+
+func TestAuditPluginTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditPluginTestSuite))
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+	"github.com/fajritsaniy/golang-SHM/utils/common"
+	"gorm.io/gorm"
+)
+
+// AuditRepository only reads, entries are written by the audit plugin.
+type AuditRepository interface {
+	Paging(ctx context.Context, filter dto.AuditLogFilter, requestQueryParams dto.RequestQueryParams) ([]model.AuditLog, dto.Paging, error)
+}
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func (a *auditRepository) Paging(ctx context.Context, filter dto.AuditLogFilter, requestQueryParams dto.RequestQueryParams) ([]model.AuditLog, dto.Paging, error) {
+	paginationQuery := common.GetPaginationParams(requestQueryParams.PaginationParam)
+	query := a.db.WithContext(ctx).Model(&model.AuditLog{})
+	if filter.Entity != "" {
+		query = query.Where("entity_table = ?", filter.Entity)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var totalRows int64
+	if err := query.Count(&totalRows).Error; err != nil {
+		return nil, dto.Paging{}, err
+	}
+	var logs []model.AuditLog
+	err := query.Order("created_at DESC").Limit(paginationQuery.Take).Offset(paginationQuery.Skip).Find(&logs).Error
+	if err != nil {
+		return nil, dto.Paging{}, err
+	}
+	return logs, common.Paginate(paginationQuery.Page, paginationQuery.Take, int(totalRows)), nil
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
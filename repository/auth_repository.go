@@ -1,87 +1,86 @@
 package repository
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/fajritsaniy/golang-SHM/model"
 	"github.com/fajritsaniy/golang-SHM/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	BaseRepository[model.UserCredential]
-	GetByUsername(username string) (*model.UserCredential, error)
-	GetByUsernamePassword(username string, password string) (*model.UserCredential, error)
+	GetByUsername(ctx context.Context, username string) (*model.UserCredential, error)
+	GetByUsernamePassword(ctx context.Context, username string, password string) (*model.UserCredential, error)
+	IncrementFailedLogin(ctx context.Context, id string) (int, error)
+	LockAccount(ctx context.Context, id string, until time.Time, lockoutCount int) error
+	ResetLoginFailures(ctx context.Context, id string) error
 }
 
 type userRepository struct {
 	db *gorm.DB
 }
 
-func (u *userRepository) Search(by map[string]interface{}) ([]model.UserCredential, error) {
+func (u *userRepository) Search(ctx context.Context, by map[string]interface{}) ([]model.UserCredential, error) {
 	var users []model.UserCredential
-	result := u.db.Where(by).Find(&users).Error
+	result := u.db.WithContext(ctx).Where(by).Find(&users).Error
 	if result != nil {
 		return nil, result
 	}
 	return users, nil
 }
 
-func (u *userRepository) List() ([]model.UserCredential, error) {
+func (u *userRepository) List(ctx context.Context) ([]model.UserCredential, error) {
 	var users []model.UserCredential
-	result := u.db.Find(&users).Error
+	result := u.db.WithContext(ctx).Find(&users).Error
 	if result != nil {
 		return nil, result
 	}
 	return users, nil
 }
 
-func (u *userRepository) Get(id string) (*model.UserCredential, error) {
+func (u *userRepository) Get(ctx context.Context, id string) (*model.UserCredential, error) {
 	var user model.UserCredential
-	result := u.db.First(&user, "id=?", id).Error
+	result := u.db.WithContext(ctx).First(&user, "id=?", id).Error
 	if result != nil {
-		return nil, result
+		return nil, translateError(result, "user")
 	}
 	return &user, nil
 }
 
-func (u *userRepository) Save(payload *model.UserCredential) error {
-	return u.db.Save(payload).Error
+func (u *userRepository) Save(ctx context.Context, payload *model.UserCredential) error {
+	return translateError(u.db.WithContext(ctx).Save(payload).Error, "user")
 }
 
-func (u *userRepository) Delete(id string) error {
-	return u.db.Delete(&model.UserCredential{}, "id=?", id).Error
+func (u *userRepository) Delete(ctx context.Context, id string) error {
+	return translateError(u.db.WithContext(ctx).Delete(&model.UserCredential{}, "id=?", id).Error, "user")
 }
 
-func (u *userRepository) GetByUsernameActive(username string) (*model.UserCredential, error) {
+func (u *userRepository) GetByUsernameActive(ctx context.Context, username string) (*model.UserCredential, error) {
 	var userCredential model.UserCredential
-	result := u.db.Where("user_name = ?", username).Where("is_active = ?", true).First(&userCredential)
+	result := u.db.WithContext(ctx).Where("user_name = ?", username).Where("is_active = ?", true).First(&userCredential)
 	if err := result.Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("user with username '%s' not found", username)
-		}
-		return nil, fmt.Errorf("failed to get user with username '%s': %v", username, err)
+		return nil, translateError(err, "user")
 	}
 	return &userCredential, nil
 }
 
-func (u *userRepository) GetByUsername(username string) (*model.UserCredential, error) {
+func (u *userRepository) GetByUsername(ctx context.Context, username string) (*model.UserCredential, error) {
 	var userCredential model.UserCredential
-	result := u.db.Where("user_name = ?", username).First(&userCredential)
+	result := u.db.WithContext(ctx).Where("user_name = ?", username).First(&userCredential)
 	if err := result.Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("user with username '%s' not found", username)
-		}
-		return nil, fmt.Errorf("failed to get user with username '%s': %v", username, err)
+		return nil, translateError(err, "user")
 	}
 	return &userCredential, nil
 }
 
-func (u *userRepository) GetByUsernamePassword(username string, password string) (*model.UserCredential, error) {
-	user, err := u.GetByUsernameActive(username)
+func (u *userRepository) GetByUsernamePassword(ctx context.Context, username string, password string) (*model.UserCredential, error) {
+	user, err := u.GetByUsernameActive(ctx, username)
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, "user")
 	}
 
 	pwdCheck := utils.CheckPasswordHash(password, user.Password)
@@ -91,6 +90,35 @@ func (u *userRepository) GetByUsernamePassword(username string, password string)
 	return user, nil
 }
 
+func (u *userRepository) IncrementFailedLogin(ctx context.Context, id string) (int, error) {
+	// increment in SQL so parallel guesses cannot overwrite each other's count
+	var user model.UserCredential
+	result := u.db.WithContext(ctx).Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_count"}}}).
+		Where("id = ?", id).
+		Update("failed_login_count", gorm.Expr("failed_login_count + 1"))
+	if err := result.Error; err != nil {
+		return 0, err
+	}
+	return user.FailedLoginCount, nil
+}
+
+func (u *userRepository) LockAccount(ctx context.Context, id string, until time.Time, lockoutCount int) error {
+	return u.db.WithContext(ctx).Model(&model.UserCredential{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"failed_login_count": 0,
+		"lockout_count":      lockoutCount,
+		"locked_until":       until,
+	}).Error
+}
+
+func (u *userRepository) ResetLoginFailures(ctx context.Context, id string) error {
+	return u.db.WithContext(ctx).Model(&model.UserCredential{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"failed_login_count": 0,
+		"lockout_count":      0,
+		"locked_until":       nil,
+	}).Error
+}
+
 func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
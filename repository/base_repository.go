@@ -1,22 +1,24 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/fajritsaniy/golang-SHM/model/dto"
 )
 
 type BaseRepository[T any] interface {
-	Search(by map[string]interface{}) ([]T, error)
-	List() ([]T, error)
-	Get(id string) (*T, error)
-	Save(payload *T) error
-	Delete(id string) error
+	Search(ctx context.Context, by map[string]interface{}) ([]T, error)
+	List(ctx context.Context) ([]T, error)
+	Get(ctx context.Context, id string) (*T, error)
+	Save(ctx context.Context, payload *T) error
+	Delete(ctx context.Context, id string) error
 }
 
 type BaseRepositoryEmailPhone[T any] interface {
-	GetByEmail(email string) (*T, error)
-	GetByPhone(phone string) (*T, error)
+	GetByEmail(ctx context.Context, email string) (*T, error)
+	GetByPhone(ctx context.Context, phone string) (*T, error)
 }
 
 type BaseRepositoryPaging[T any] interface {
-	Paging(requestQueryParams dto.RequestQueryParams) ([]T, dto.Paging, error)
+	Paging(ctx context.Context, requestQueryParams dto.RequestQueryParams) ([]T, dto.Paging, error)
 }
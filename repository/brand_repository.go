@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/fajritsaniy/golang-SHM/model"
@@ -12,55 +13,55 @@ import (
 type BrandRepository interface {
 	BaseRepository[model.Brand]
 	BaseRepositoryPaging[model.Brand]
-	CountByName(name string, id string) (int64, error)
+	CountByName(ctx context.Context, name string, id string) (int64, error)
 }
 
 type brandRepository struct {
 	db *gorm.DB
 }
 
-func (b *brandRepository) Delete(id string) error {
-	return b.db.Delete(&model.Brand{}, "id=?", id).Error
+func (b *brandRepository) Delete(ctx context.Context, id string) error {
+	return translateError(b.db.WithContext(ctx).Delete(&model.Brand{}, "id=?", id).Error, "brand")
 }
 
-func (b *brandRepository) Get(id string) (*model.Brand, error) {
+func (b *brandRepository) Get(ctx context.Context, id string) (*model.Brand, error) {
 	var brand model.Brand
-	result := b.db.First(&brand, "id=?", id).Error
+	result := b.db.WithContext(ctx).First(&brand, "id=?", id).Error
 	if result != nil {
-		return nil, result
+		return nil, translateError(result, "brand")
 	}
 	return &brand, nil
 }
 
-func (b *brandRepository) List() ([]model.Brand, error) {
+func (b *brandRepository) List(ctx context.Context) ([]model.Brand, error) {
 	var brands []model.Brand
-	result := b.db.Find(&brands).Error
+	result := b.db.WithContext(ctx).Find(&brands).Error
 	if result != nil {
 		return nil, result
 	}
 	return brands, nil
 }
 
-func (b *brandRepository) Save(payload *model.Brand) error {
-	return b.db.Save(payload).Error
+func (b *brandRepository) Save(ctx context.Context, payload *model.Brand) error {
+	return translateError(b.db.WithContext(ctx).Save(payload).Error, "brand")
 }
 
-func (b *brandRepository) Search(by map[string]interface{}) ([]model.Brand, error) {
+func (b *brandRepository) Search(ctx context.Context, by map[string]interface{}) ([]model.Brand, error) {
 	var brands []model.Brand
-	result := b.db.Where(by).Find(&brands).Error
+	result := b.db.WithContext(ctx).Where(by).Find(&brands).Error
 	if result != nil {
 		return nil, result
 	}
 	return brands, nil
 }
 
-func (b *brandRepository) CountByName(name string, id string) (int64, error) {
+func (b *brandRepository) CountByName(ctx context.Context, name string, id string) (int64, error) {
 	var count int64
 	var result *gorm.DB
 	if id != "" {
-		result = b.db.Model(&model.Brand{}).Where("name ILIKE ? AND id <> ?", "%"+name+"%", id).Count(&count)
+		result = b.db.WithContext(ctx).Model(&model.Brand{}).Where("name ILIKE ? AND id <> ?", "%"+name+"%", id).Count(&count)
 	} else {
-		result = b.db.Model(&model.Brand{}).Where("name ILIKE ?", "%"+name+"%").Count(&count)
+		result = b.db.WithContext(ctx).Model(&model.Brand{}).Where("name ILIKE ?", "%"+name+"%").Count(&count)
 	}
 	if err := result.Error; err != nil {
 		return 0, err
@@ -68,15 +69,15 @@ func (b *brandRepository) CountByName(name string, id string) (int64, error) {
 	return count, nil
 }
 
-func (b *brandRepository) Paging(requestQueryParams dto.RequestQueryParams) ([]model.Brand, dto.Paging, error) {
+func (b *brandRepository) Paging(ctx context.Context, requestQueryParams dto.RequestQueryParams) ([]model.Brand, dto.Paging, error) {
 	paginationQuery, orderQuery := b.pagingValidate(requestQueryParams)
 	var brands []model.Brand
-	result := b.db.Preload("Vehicles").Order(orderQuery).Limit(paginationQuery.Take).Offset(paginationQuery.Skip).Find(&brands).Error
+	result := b.db.WithContext(ctx).Preload("Vehicles").Order(orderQuery).Limit(paginationQuery.Take).Offset(paginationQuery.Skip).Find(&brands).Error
 	if result != nil {
 		return nil, dto.Paging{}, result
 	}
 	var totalRows int64
-	result = b.db.Model(&model.Brand{}).Count(&totalRows).Error
+	result = b.db.WithContext(ctx).Model(&model.Brand{}).Count(&totalRows).Error
 	if result != nil {
 		return nil, dto.Paging{}, result
 	}
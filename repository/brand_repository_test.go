@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -58,7 +59,7 @@ func (suite *BrandRepoTestSuite) TestGetAllBrandSuccess() {
 	expectedQuery := `SELECT \* FROM "mst_brand"`
 	suite.mock.ExpectQuery(expectedQuery).WillReturnRows(rows)
 	repo := NewBrandRepository(suite.DB)
-	listBrand, err := repo.List()
+	listBrand, err := repo.List(context.Background())
 	assert.Equal(suite.T(), brandRowDummies, listBrand)
 	assert.NoError(suite.T(), err)
 }
@@ -71,7 +72,7 @@ func (suite *BrandRepoTestSuite) TestGetAllMenuDBErrorFail() {
 	expectedQuery := `SELECT \* FROM "mst_brand"`
 	suite.mock.ExpectQuery(expectedQuery).WillReturnError(errors.New(dbErrorMessage))
 	repo := NewBrandRepository(suite.DB)
-	listMenu, err := repo.List()
+	listMenu, err := repo.List(context.Background())
 	assert.Nil(suite.T(), listMenu)
 	assert.Error(suite.T(), err)
 }
@@ -84,7 +85,7 @@ func (suite *BrandRepoTestSuite) TestGetByIdSuccess() {
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(brandDm.ID).WillReturnRows(brandRow)
 	repo := NewBrandRepository(suite.DB)
-	brand, err := repo.Get(brandDm.ID)
+	brand, err := repo.Get(context.Background(), brandDm.ID)
 	assert.Equal(suite.T(), *brandDm, *brand)
 	assert.NoError(suite.T(), err)
 }
@@ -97,7 +98,7 @@ func (suite *BrandRepoTestSuite) TestGetByIdDBErrorFail() {
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(brandDm.ID).WillReturnError(errors.New(dbErrorMessage))
 	repo := NewBrandRepository(suite.DB)
-	brand, err := repo.Get(brandDm.ID)
+	brand, err := repo.Get(context.Background(), brandDm.ID)
 	assert.Nil(suite.T(), brand)
 	assert.Error(suite.T(), err)
 }
@@ -114,7 +115,7 @@ func (suite *BrandRepoTestSuite) TestSearchBrandSuccess() {
 		WithArgs("Honda").WillReturnRows(rows)
 	repo := NewBrandRepository(suite.DB)
 	filter := map[string]interface{}{"name": "Honda"}
-	listBrand, err := repo.Search(filter)
+	listBrand, err := repo.Search(context.Background(), filter)
 	assert.Equal(suite.T(), brandRowDummies, listBrand)
 	assert.NoError(suite.T(), err)
 }
@@ -128,7 +129,7 @@ func (suite *BrandRepoTestSuite) TestSearchBrandDBErrorFail() {
 	suite.mock.ExpectQuery(expectedQuery).WillReturnError(errors.New(dbErrorMessage))
 	repo := NewBrandRepository(suite.DB)
 	filter := map[string]interface{}{"name": "Honda"}
-	listMenu, err := repo.Search(filter)
+	listMenu, err := repo.Search(context.Background(), filter)
 	assert.Nil(suite.T(), listMenu)
 	assert.Error(suite.T(), err)
 }
@@ -140,7 +141,7 @@ func (suite *BrandRepoTestSuite) TestDeleteMenuSuccess() {
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	suite.mock.ExpectCommit()
 	repo := NewBrandRepository(suite.DB)
-	err := repo.Delete("1")
+	err := repo.Delete(context.Background(), "1")
 	assert.Nil(suite.T(), err)
 }
 
@@ -149,7 +150,7 @@ func (suite *BrandRepoTestSuite) TestDeleteMenuDBErrorFail() {
 	suite.mock.ExpectExec(expectedQuery).
 		WillReturnError(errors.New(dbErrorMessage))
 	repo := NewBrandRepository(suite.DB)
-	err := repo.Delete("1")
+	err := repo.Delete(context.Background(), "1")
 	assert.Error(suite.T(), err)
 }
 
@@ -162,7 +163,7 @@ func (suite *BrandRepoTestSuite) TestCountByNameSuccess() {
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(filter).WillReturnRows(brandRow)
 	repo := NewBrandRepository(suite.DB)
-	brand, err := repo.Search(filter)
+	brand, err := repo.Search(context.Background(), filter)
 	assert.Equal(suite.T(), brandDm, brand)
 	assert.NoError(suite.T(), err)
 }
@@ -176,7 +177,7 @@ func (suite *BrandRepoTestSuite) TestCountByNameDBErrorFail() {
 	suite.mock.ExpectQuery(expectedQuery).
 		WithArgs(brandDm.ID).WillReturnError(errors.New(dbErrorMessage))
 	repo := NewBrandRepository(suite.DB)
-	brand, err := repo.Search(filter)
+	brand, err := repo.Search(context.Background(), filter)
 	assert.Nil(suite.T(), brand)
 	assert.Error(suite.T(), err)
 }
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/fajritsaniy/golang-SHM/model"
@@ -9,46 +10,46 @@ import (
 
 type CustomerRepository interface {
 	BaseRepository[model.Customer]
-	ListCustomerUser() ([]model.Customer, error)
-	GetByUser(userId string) (*model.Customer, error)
+	ListCustomerUser(ctx context.Context) ([]model.Customer, error)
+	GetByUser(ctx context.Context, userId string) (*model.Customer, error)
 	BaseRepositoryEmailPhone[model.Customer]
-	CreateCustomerVehicle(payload *model.Customer, association any) error
+	CreateCustomerVehicle(ctx context.Context, payload *model.Customer, association any) error
 }
 
 type customerRepository struct {
 	db *gorm.DB
 }
 
-func (c *customerRepository) Search(by map[string]interface{}) ([]model.Customer, error) {
+func (c *customerRepository) Search(ctx context.Context, by map[string]interface{}) ([]model.Customer, error) {
 	var customers []model.Customer
-	result := c.db.Where(by).Find(&customers).Error
+	result := c.db.WithContext(ctx).Where(by).Find(&customers).Error
 	if result != nil {
 		return nil, result
 	}
 	return customers, nil
 }
 
-func (c *customerRepository) List() ([]model.Customer, error) {
+func (c *customerRepository) List(ctx context.Context) ([]model.Customer, error) {
 	var customers []model.Customer
-	result := c.db.Find(&customers).Error
+	result := c.db.WithContext(ctx).Find(&customers).Error
 	if result != nil {
 		return nil, result
 	}
 	return customers, nil
 }
 
-func (c *customerRepository) Get(id string) (*model.Customer, error) {
+func (c *customerRepository) Get(ctx context.Context, id string) (*model.Customer, error) {
 	var customer model.Customer
-	result := c.db.First(&customer, "id=?", id).Error
+	result := c.db.WithContext(ctx).First(&customer, "id=?", id).Error
 	if result != nil {
-		return nil, result
+		return nil, translateError(result, "customer")
 	}
 	return &customer, nil
 }
 
-func (c *customerRepository) ListCustomerUser() ([]model.Customer, error) {
+func (c *customerRepository) ListCustomerUser(ctx context.Context) ([]model.Customer, error) {
 	var customers []model.Customer
-	result := c.db.Preload("UserCredential").Order("created_at").Find(&customers).Error
+	result := c.db.WithContext(ctx).Preload("UserCredential").Order("created_at").Find(&customers).Error
 	if result != nil {
 		return nil, result
 	}
@@ -56,50 +57,50 @@ func (c *customerRepository) ListCustomerUser() ([]model.Customer, error) {
 	return customers, nil
 }
 
-func (c *customerRepository) GetByUser(userId string) (*model.Customer, error) {
+func (c *customerRepository) GetByUser(ctx context.Context, userId string) (*model.Customer, error) {
 	var customer model.Customer
-	result := c.db.Preload("UserCredential").First(&customer, "user_credential_id=?", userId).Error
+	result := c.db.WithContext(ctx).Preload("UserCredential").Preload("Vehicles").First(&customer, "user_credential_id=?", userId).Error
 	if result != nil {
-		return nil, result
+		return nil, translateError(result, "customer")
 	}
 
 	return &customer, nil
 }
 
-func (c *customerRepository) Save(payload *model.Customer) error {
-	return c.db.Save(payload).Error
+func (c *customerRepository) Save(ctx context.Context, payload *model.Customer) error {
+	return translateError(c.db.WithContext(ctx).Save(payload).Error, "customer")
 }
 
-func (c *customerRepository) Delete(id string) error {
-	return c.db.Delete(&model.Customer{}, "id=?", id).Error
+func (c *customerRepository) Delete(ctx context.Context, id string) error {
+	return translateError(c.db.WithContext(ctx).Delete(&model.Customer{}, "id=?", id).Error, "customer")
 }
 
-func (c *customerRepository) GetByEmail(email string) (*model.Customer, error) {
+func (c *customerRepository) GetByEmail(ctx context.Context, email string) (*model.Customer, error) {
 	var customer model.Customer
-	result := c.db.Select("id, email").First(&customer, "email=?", email).Error
+	result := c.db.WithContext(ctx).Select("id, email").First(&customer, "email=?", email).Error
 	if result != nil {
-		return nil, result
+		return nil, translateError(result, "customer")
 	}
 	return &customer, nil
 }
 
-func (c *customerRepository) GetByPhone(phone string) (*model.Customer, error) {
+func (c *customerRepository) GetByPhone(ctx context.Context, phone string) (*model.Customer, error) {
 	var customer model.Customer
-	result := c.db.Select("id, phone_number").First(&customer, "phone_number=?", phone).Error
+	result := c.db.WithContext(ctx).Select("id, phone_number").First(&customer, "phone_number=?", phone).Error
 	if result != nil {
-		return nil, result
+		return nil, translateError(result, "customer")
 	}
 	return &customer, nil
 }
 
-func (c *customerRepository) CreateCustomerVehicle(payload *model.Customer, association interface{}) error {
+func (c *customerRepository) CreateCustomerVehicle(ctx context.Context, payload *model.Customer, association interface{}) error {
 	vehicle, ok := association.(*model.Vehicle)
 	if !ok {
 		return fmt.Errorf("invalid vehicle association")
 	}
 
-	if err := c.db.Model(vehicle).Association("Customers").Append(payload); err != nil {
-		return err
+	if err := c.db.WithContext(ctx).Model(vehicle).Association("Customers").Append(payload); err != nil {
+		return translateError(err, "customer")
 	}
 
 	return nil
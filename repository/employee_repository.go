@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/fajritsaniy/golang-SHM/model"
@@ -9,9 +10,9 @@ import (
 
 type EmployeeRepository interface {
 	BaseRepository[model.Employee]
-	ListEmployeeUser() ([]model.Employee, error)
-	GetByUser(userId string) (*model.Employee, error)
-	ListEmployeeByManager(managerId string) ([]model.Employee, error)
+	ListEmployeeUser(ctx context.Context) ([]model.Employee, error)
+	GetByUser(ctx context.Context, userId string) (*model.Employee, error)
+	ListEmployeeByManager(ctx context.Context, managerId string) ([]model.Employee, error)
 	BaseRepositoryEmailPhone[model.Employee]
 }
 
@@ -19,36 +20,36 @@ type employeeRepository struct {
 	db *gorm.DB
 }
 
-func (e *employeeRepository) Search(by map[string]interface{}) ([]model.Employee, error) {
+func (e *employeeRepository) Search(ctx context.Context, by map[string]interface{}) ([]model.Employee, error) {
 	var employees []model.Employee
-	result := e.db.Where(by).Find(&employees).Error
+	result := e.db.WithContext(ctx).Where(by).Find(&employees).Error
 	if result != nil {
 		return nil, result
 	}
 	return employees, nil
 }
 
-func (e *employeeRepository) List() ([]model.Employee, error) {
+func (e *employeeRepository) List(ctx context.Context) ([]model.Employee, error) {
 	var employees []model.Employee
-	result := e.db.Preload("Manager").Find(&employees).Error
+	result := e.db.WithContext(ctx).Preload("Manager").Find(&employees).Error
 	if result != nil {
 		return nil, result
 	}
 	return employees, nil
 }
 
-func (e *employeeRepository) Get(id string) (*model.Employee, error) {
+func (e *employeeRepository) Get(ctx context.Context, id string) (*model.Employee, error) {
 	var employee model.Employee
-	result := e.db.First(&employee, "id=?", id).Error
+	result := e.db.WithContext(ctx).First(&employee, "id=?", id).Error
 	if result != nil {
-		return nil, result
+		return nil, translateError(result, "employee")
 	}
 	return &employee, nil
 }
 
-func (e *employeeRepository) ListEmployeeUser() ([]model.Employee, error) {
+func (e *employeeRepository) ListEmployeeUser(ctx context.Context) ([]model.Employee, error) {
 	var employees []model.Employee
-	result := e.db.Preload("UserCredential").Order("created_at").Find(&employees).Error
+	result := e.db.WithContext(ctx).Preload("UserCredential").Order("created_at").Find(&employees).Error
 	if result != nil {
 		return nil, result
 	}
@@ -56,51 +57,51 @@ func (e *employeeRepository) ListEmployeeUser() ([]model.Employee, error) {
 	return employees, nil
 }
 
-func (e *employeeRepository) GetByUser(userId string) (*model.Employee, error) {
+func (e *employeeRepository) GetByUser(ctx context.Context, userId string) (*model.Employee, error) {
 	var employee model.Employee
-	result := e.db.Preload("UserCredential").Where("user_credential_id = ?", userId).First(&employee).Error
+	result := e.db.WithContext(ctx).Preload("UserCredential").Where("user_credential_id = ?", userId).First(&employee).Error
 	if result != nil {
-		return nil, result
+		return nil, translateError(result, "employee")
 	}
 
 	return &employee, nil
 }
 
-func (e *employeeRepository) Save(payload *model.Employee) error {
-	return e.db.Save(payload).Error
+func (e *employeeRepository) Save(ctx context.Context, payload *model.Employee) error {
+	return translateError(e.db.WithContext(ctx).Save(payload).Error, "employee")
 }
 
-func (e *employeeRepository) Delete(id string) error {
-	return e.db.Delete(&model.Employee{}, "id=?", id).Error
+func (e *employeeRepository) Delete(ctx context.Context, id string) error {
+	return translateError(e.db.WithContext(ctx).Delete(&model.Employee{}, "id=?", id).Error, "employee")
 }
 
-func (e *employeeRepository) GetByEmail(email string) (*model.Employee, error) {
+func (e *employeeRepository) GetByEmail(ctx context.Context, email string) (*model.Employee, error) {
 	var employee model.Employee
-	err := e.db.Where("email = ?", email).Select("id, email").First(&employee).Error
+	err := e.db.WithContext(ctx).Where("email = ?", email).Select("id, email").First(&employee).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
-		return nil, err
+		return nil, translateError(err, "employee")
 	}
 	return &employee, nil
 }
 
-func (e *employeeRepository) GetByPhone(phone string) (*model.Employee, error) {
+func (e *employeeRepository) GetByPhone(ctx context.Context, phone string) (*model.Employee, error) {
 	var employee model.Employee
-	err := e.db.Where("phone_number = ?", phone).Select("id, phone_number").First(&employee).Error
+	err := e.db.WithContext(ctx).Where("phone_number = ?", phone).Select("id, phone_number").First(&employee).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
-		return nil, err
+		return nil, translateError(err, "employee")
 	}
 	return &employee, nil
 }
 
-func (e *employeeRepository) ListEmployeeByManager(managerId string) ([]model.Employee, error) {
+func (e *employeeRepository) ListEmployeeByManager(ctx context.Context, managerId string) ([]model.Employee, error) {
 	var employees []model.Employee
-	result := e.db.Preload("Manager").Where("manager_id = ?", managerId).Find(&employees).Error
+	result := e.db.WithContext(ctx).Preload("Manager").Where("manager_id = ?", managerId).Find(&employees).Error
 	if result != nil {
 		return nil, result
 	}
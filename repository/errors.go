@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"gorm.io/gorm"
+)
+
+// translateError turns the GORM errors callers care about into domain
+// errors, so the layers above never have to import gorm. entity names the
+// row in the code, e.g. "brand" gives BRAND_NOT_FOUND.
+// Unique violations only arrive as gorm.ErrDuplicatedKey when the connection
+// is opened with TranslateError.
+func translateError(err error, entity string) error {
+	code := strings.ToUpper(strings.ReplaceAll(entity, " ", "_"))
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperror.NotFound(code+"_NOT_FOUND", "record.not_found").Wrap(err)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return apperror.Conflict(code+"_ALREADY_EXISTS", "record.already_exists").Wrap(err)
+	}
+	return err
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TranslateErrorTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TranslateErrorTestSuite) TestTranslateErrorSuccess() {
+	cases := []struct {
+		name string
+		err  error
+		kind apperror.Kind
+		code string
+	}{
+		{name: "not found", err: gorm.ErrRecordNotFound, kind: apperror.KindNotFound, code: "VEHICLE_IMAGE_NOT_FOUND"},
+		{name: "wrapped not found", err: fmt.Errorf("first: %w", gorm.ErrRecordNotFound), kind: apperror.KindNotFound, code: "VEHICLE_IMAGE_NOT_FOUND"},
+		{name: "duplicated key", err: gorm.ErrDuplicatedKey, kind: apperror.KindConflict, code: "VEHICLE_IMAGE_ALREADY_EXISTS"},
+	}
+	for _, tc := range cases {
+		err := translateError(tc.err, "vehicle image")
+		assert.Equal(suite.T(), tc.kind, apperror.KindOf(err), tc.name)
+		assert.Equal(suite.T(), tc.code, apperror.From(err).Code, tc.name)
+		assert.ErrorIs(suite.T(), err, tc.err, tc.name)
+	}
+}
+
+func (suite *TranslateErrorTestSuite) TestTranslateErrorPassThroughSuccess() {
+	assert.NoError(suite.T(), translateError(nil, "brand"))
+	other := errors.New("connection refused")
+	assert.Same(suite.T(), other, translateError(other, "brand"))
+}
+
+func TestTranslateErrorTestSuite(t *testing.T) {
+	suite.Run(t, new(TranslateErrorTestSuite))
+}
@@ -1,35 +1,43 @@
 package repository
 
 import (
+	"context"
 	"io"
-	"mime/multipart"
-	"os"
-	"path/filepath"
+
+	"github.com/fajritsaniy/golang-SHM/utils/storage"
 )
 
 type FileRepository interface {
-	Save(file multipart.File, fileName string) (string, error)
+	// Save stores body under key, the content type is guessed from the key
+	// when empty.
+	Save(ctx context.Context, key string, body io.ReadSeeker, contentType string) error
+	Open(ctx context.Context, key string) (*storage.Object, error)
+	Delete(ctx context.Context, key string) error
 }
 
 type fileRepository struct {
-	path string
+	storage storage.Storage
 }
 
-func (f *fileRepository) Save(file multipart.File, fileName string) (string, error) {
-	fileLocation := filepath.Join(f.path, fileName)
-	out, err := os.OpenFile(fileLocation, os.O_WRONLY|os.O_CREATE, 0666)
+func (f *fileRepository) Save(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	size, err := body.Seek(0, io.SeekEnd)
 	if err != nil {
-		return "", err
+		return err
 	}
-	defer out.Close()
-
-	_, err = io.Copy(out, file)
-	if err != nil {
-		return "", err
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return err
 	}
-	return fileLocation, nil
+	return f.storage.Put(ctx, key, body, size, contentType)
+}
+
+func (f *fileRepository) Open(ctx context.Context, key string) (*storage.Object, error) {
+	return f.storage.Open(ctx, key)
+}
+
+func (f *fileRepository) Delete(ctx context.Context, key string) error {
+	return f.storage.Delete(ctx, key)
 }
 
-func NewFileRepository(path string) FileRepository {
-	return &fileRepository{path: path}
+func NewFileRepository(store storage.Storage) FileRepository {
+	return &fileRepository{storage: store}
 }
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	Save(ctx context.Context, payload *model.PasswordResetToken) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id string) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func (p *passwordResetRepository) Save(ctx context.Context, payload *model.PasswordResetToken) error {
+	return translateError(p.db.WithContext(ctx).Save(payload).Error, "reset token")
+}
+
+func (p *passwordResetRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	var resetToken model.PasswordResetToken
+	result := p.db.WithContext(ctx).First(&resetToken, "token_hash = ?", tokenHash).Error
+	if result != nil {
+		return nil, translateError(result, "reset token")
+	}
+	return &resetToken, nil
+}
+
+func (p *passwordResetRepository) MarkUsed(ctx context.Context, id string) error {
+	result := p.db.WithContext(ctx).Model(&model.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if err := result.Error; err != nil {
+		return translateError(err, "reset token")
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("reset token has already been used")
+	}
+	return nil
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+	"github.com/fajritsaniy/golang-SHM/utils/common"
+	"gorm.io/gorm"
+)
+
+type SecurityRepository interface {
+	SaveLoginAttempt(ctx context.Context, payload *model.LoginAttempt) error
+	CountFailedLoginsByIP(ctx context.Context, clientIP string, since time.Time) (int64, error)
+	SaveEvent(ctx context.Context, payload *model.SecurityEvent) error
+	PagingEvents(ctx context.Context, requestQueryParams dto.RequestQueryParams) ([]model.SecurityEvent, dto.Paging, error)
+}
+
+type securityRepository struct {
+	db *gorm.DB
+}
+
+func (s *securityRepository) SaveLoginAttempt(ctx context.Context, payload *model.LoginAttempt) error {
+	return s.db.WithContext(ctx).Create(payload).Error
+}
+
+func (s *securityRepository) CountFailedLoginsByIP(ctx context.Context, clientIP string, since time.Time) (int64, error) {
+	var count int64
+	result := s.db.WithContext(ctx).Model(&model.LoginAttempt{}).
+		Where("client_ip = ? AND success = ? AND created_at >= ?", clientIP, false, since).
+		Count(&count)
+	if err := result.Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *securityRepository) SaveEvent(ctx context.Context, payload *model.SecurityEvent) error {
+	return s.db.WithContext(ctx).Create(payload).Error
+}
+
+func (s *securityRepository) PagingEvents(ctx context.Context, requestQueryParams dto.RequestQueryParams) ([]model.SecurityEvent, dto.Paging, error) {
+	paginationQuery := common.GetPaginationParams(requestQueryParams.PaginationParam)
+	var events []model.SecurityEvent
+	result := s.db.WithContext(ctx).Order("created_at DESC").Limit(paginationQuery.Take).Offset(paginationQuery.Skip).Find(&events).Error
+	if result != nil {
+		return nil, dto.Paging{}, result
+	}
+	var totalRows int64
+	result = s.db.WithContext(ctx).Model(&model.SecurityEvent{}).Count(&totalRows).Error
+	if result != nil {
+		return nil, dto.Paging{}, result
+	}
+	return events, common.Paginate(paginationQuery.Page, paginationQuery.Take, int(totalRows)), nil
+}
+
+func NewSecurityRepository(db *gorm.DB) SecurityRepository {
+	return &securityRepository{db: db}
+}
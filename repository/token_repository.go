@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"gorm.io/gorm"
+)
+
+type TokenRepository interface {
+	SaveRefreshToken(ctx context.Context, payload *model.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenID string) (*model.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, tokenID string, next *model.RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID string) error
+	RevokeUserAccessTokens(ctx context.Context, userID string) error
+	RevokeToken(ctx context.Context, payload *model.RevokedToken) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func (t *tokenRepository) SaveRefreshToken(ctx context.Context, payload *model.RefreshToken) error {
+	return translateError(t.db.WithContext(ctx).Create(payload).Error, "refresh token")
+}
+
+func (t *tokenRepository) GetRefreshToken(ctx context.Context, tokenID string) (*model.RefreshToken, error) {
+	var refreshToken model.RefreshToken
+	result := t.db.WithContext(ctx).First(&refreshToken, "token_id = ?", tokenID).Error
+	if result != nil {
+		return nil, translateError(result, "refresh token")
+	}
+	return &refreshToken, nil
+}
+
+func (t *tokenRepository) RotateRefreshToken(ctx context.Context, tokenID string, next *model.RefreshToken) error {
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// only one caller can retire a given token, a second one is a reuse
+		result := tx.Model(&model.RefreshToken{}).
+			Where("token_id = ? AND revoked_at IS NULL", tokenID).
+			Updates(map[string]interface{}{
+				"revoked_at":  time.Now(),
+				"replaced_by": next.TokenID,
+			})
+		if err := result.Error; err != nil {
+			return err
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrRefreshTokenReused
+		}
+		return tx.Create(next).Error
+	})
+}
+
+func (t *tokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	return t.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (t *tokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	return t.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_credential_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserAccessTokens revokes every access token of the user that has not
+// expired yet, including those issued with refresh tokens rotated since.
+func (t *tokenRepository) RevokeUserAccessTokens(ctx context.Context, userID string) error {
+	return t.db.WithContext(ctx).Exec(`INSERT INTO trx_revoked_token (token_id, expires_at, created_at)
+		SELECT access_token_id, access_expires_at, now() FROM trx_refresh_token
+		WHERE user_credential_id = ? AND access_token_id <> '' AND access_expires_at > now()
+		ON CONFLICT (token_id) DO NOTHING`, userID).Error
+}
+
+func (t *tokenRepository) RevokeToken(ctx context.Context, payload *model.RevokedToken) error {
+	return t.db.WithContext(ctx).Save(payload).Error
+}
+
+func (t *tokenRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var revokedToken model.RevokedToken
+	err := t.db.WithContext(ctx).Select("token_id").First(&revokedToken, "token_id = ?", tokenID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
@@ -1,31 +1,33 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/fajritsaniy/golang-SHM/model"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository interface {
-	Create(payload *model.Transaction) error
-	List() ([]model.Transaction, error)
-	Get(id string) (model.Transaction, error)
+	Create(ctx context.Context, payload *model.Transaction) error
+	List(ctx context.Context) ([]model.Transaction, error)
+	Get(ctx context.Context, id string) (model.Transaction, error)
 }
 
 type transactionRepository struct {
 	db *gorm.DB
 }
 
-func (t *transactionRepository) Create(payload *model.Transaction) error {
-	if err := t.db.Omit(clause.Associations).Create(payload).Error; err != nil {
-		return err
+func (t *transactionRepository) Create(ctx context.Context, payload *model.Transaction) error {
+	if err := t.db.WithContext(ctx).Omit(clause.Associations).Create(payload).Error; err != nil {
+		return translateError(err, "transaction")
 	}
 	return nil
 }
 
-func (t *transactionRepository) List() ([]model.Transaction, error) {
+func (t *transactionRepository) List(ctx context.Context) ([]model.Transaction, error) {
 	var transactions []model.Transaction
-	if err := t.db.
+	if err := t.db.WithContext(ctx).
 		Preload("Vehicle").
 		Preload("Customer").
 		Preload("Employee").
@@ -36,10 +38,10 @@ func (t *transactionRepository) List() ([]model.Transaction, error) {
 	return transactions, nil
 }
 
-func (t *transactionRepository) Get(id string) (model.Transaction, error) {
+func (t *transactionRepository) Get(ctx context.Context, id string) (model.Transaction, error) {
 	var transaction model.Transaction
-	if err := t.db.Preload(clause.Associations).Where("transaction.id=?", id).First(&transaction).Error; err != nil {
-		return model.Transaction{}, err
+	if err := t.db.WithContext(ctx).Preload(clause.Associations).Where("transaction.id=?", id).First(&transaction).Error; err != nil {
+		return model.Transaction{}, translateError(err, "transaction")
 	}
 
 	return transaction, nil
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/fajritsaniy/golang-SHM/utils/tracing"
+	"gorm.io/gorm"
+)
+
+// TxRepositories exposes the repositories that share a single database
+// transaction inside UnitOfWork.Do.
+type TxRepositories interface {
+	VehicleRepo() VehicleRepository
+	VehicleImageRepo() VehicleImageRepository
+	CustomerRepo() CustomerRepository
+	TransactionRepo() TransactionRepository
+	UserRepo() UserRepository
+	TokenRepo() TokenRepository
+	PasswordResetRepo() PasswordResetRepository
+}
+
+type UnitOfWork interface {
+	// Do runs fn inside one database transaction. The transaction is committed
+	// when fn returns nil and rolled back when it returns an error or panics.
+	Do(ctx context.Context, fn func(repos TxRepositories) error) error
+}
+
+type txRepositories struct {
+	tx *gorm.DB
+}
+
+func (t *txRepositories) VehicleRepo() VehicleRepository {
+	return NewVehicleRepository(t.tx)
+}
+
+func (t *txRepositories) VehicleImageRepo() VehicleImageRepository {
+	return NewVehicleImageRepository(t.tx)
+}
+
+func (t *txRepositories) CustomerRepo() CustomerRepository {
+	return NewCustomerRepository(t.tx)
+}
+
+func (t *txRepositories) TransactionRepo() TransactionRepository {
+	return NewTransactionRepository(t.tx)
+}
+
+func (t *txRepositories) UserRepo() UserRepository {
+	return NewUserRepository(t.tx)
+}
+
+func (t *txRepositories) TokenRepo() TokenRepository {
+	return NewTokenRepository(t.tx)
+}
+
+func (t *txRepositories) PasswordResetRepo() PasswordResetRepository {
+	return NewPasswordResetRepository(t.tx)
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos TxRepositories) error) (err error) {
+	ctx, span := tracing.Start(ctx, "UnitOfWork.Do")
+	defer func() { tracing.End(span, err) }()
+
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&txRepositories{tx: tx})
+	})
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db: db}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VehicleImageRepository works on the gallery of one vehicle, every call is
+// scoped to vehicleID so an image ID of another vehicle is not found.
+type VehicleImageRepository interface {
+	// List returns the gallery in order.
+	List(ctx context.Context, vehicleID string) ([]model.VehicleImage, error)
+	// LockGallery locks the vehicle until the transaction ends, so changes
+	// to its gallery take turns.
+	LockGallery(ctx context.Context, vehicleID string) error
+	Get(ctx context.Context, vehicleID string, id string) (*model.VehicleImage, error)
+	Create(ctx context.Context, image *model.VehicleImage) error
+	UpdatePosition(ctx context.Context, vehicleID string, id string, position int) error
+	// SetPrimary makes id the only primary image of the vehicle. The two
+	// updates need a transaction, the primary is unique per vehicle.
+	SetPrimary(ctx context.Context, vehicleID string, id string) error
+	Delete(ctx context.Context, vehicleID string, id string) error
+}
+
+type vehicleImageRepository struct {
+	db *gorm.DB
+}
+
+func (v *vehicleImageRepository) List(ctx context.Context, vehicleID string) ([]model.VehicleImage, error) {
+	var images []model.VehicleImage
+	err := v.db.WithContext(ctx).Where("vehicle_id = ?", vehicleID).Order("position").Find(&images).Error
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+func (v *vehicleImageRepository) LockGallery(ctx context.Context, vehicleID string) error {
+	var vehicle model.Vehicle
+	err := v.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").First(&vehicle, "id = ?", vehicleID).Error
+	return translateError(err, "vehicle")
+}
+
+func (v *vehicleImageRepository) Get(ctx context.Context, vehicleID string, id string) (*model.VehicleImage, error) {
+	var image model.VehicleImage
+	err := v.db.WithContext(ctx).First(&image, "vehicle_id = ? AND id = ?", vehicleID, id).Error
+	if err != nil {
+		return nil, translateError(err, "vehicle image")
+	}
+	return &image, nil
+}
+
+func (v *vehicleImageRepository) Create(ctx context.Context, image *model.VehicleImage) error {
+	return translateError(v.db.WithContext(ctx).Create(image).Error, "vehicle image")
+}
+
+func (v *vehicleImageRepository) UpdatePosition(ctx context.Context, vehicleID string, id string, position int) error {
+	return v.update(ctx, vehicleID, id, "position", position)
+}
+
+func (v *vehicleImageRepository) SetPrimary(ctx context.Context, vehicleID string, id string) error {
+	err := v.db.WithContext(ctx).Model(&model.VehicleImage{}).
+		Where("vehicle_id = ? AND is_primary", vehicleID).
+		Update("is_primary", false).Error
+	if err != nil {
+		return err
+	}
+	return v.update(ctx, vehicleID, id, "is_primary", true)
+}
+
+func (v *vehicleImageRepository) update(ctx context.Context, vehicleID string, id string, column string, value any) error {
+	result := v.db.WithContext(ctx).Model(&model.VehicleImage{}).
+		Where("vehicle_id = ? AND id = ?", vehicleID, id).
+		Update(column, value)
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return translateError(gorm.ErrRecordNotFound, "vehicle image")
+	}
+	return nil
+}
+
+// Delete removes the row for good, its storage object goes with it.
+func (v *vehicleImageRepository) Delete(ctx context.Context, vehicleID string, id string) error {
+	result := v.db.WithContext(ctx).Unscoped().Delete(&model.VehicleImage{}, "vehicle_id = ? AND id = ?", vehicleID, id)
+	if err := result.Error; err != nil {
+		return err
+	}
+	if result.RowsAffected == 0 {
+		return translateError(gorm.ErrRecordNotFound, "vehicle image")
+	}
+	return nil
+}
+
+func NewVehicleImageRepository(db *gorm.DB) VehicleImageRepository {
+	return &vehicleImageRepository{db: db}
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type VehicleImageRepoTestSuite struct {
+	suite.Suite
+	DB   *gorm.DB
+	mock sqlmock.Sqlmock
+}
+
+func (suite *VehicleImageRepoTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.mock = mock
+	dialect := postgres.New(postgres.Config{
+		Conn: db,
+	})
+	suite.DB, err = gorm.Open(dialect)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *VehicleImageRepoTestSuite) TestSetPrimarySuccess() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`UPDATE "mst_vehicle_image" SET "is_primary"=\$1,"updated_at"=\$2 WHERE \(vehicle_id = \$3 AND is_primary\)`).
+		WithArgs(false, sqlmock.AnyArg(), "v1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`UPDATE "mst_vehicle_image" SET "is_primary"=\$1,"updated_at"=\$2 WHERE \(vehicle_id = \$3 AND id = \$4\)`).
+		WithArgs(true, sqlmock.AnyArg(), "v1", "i2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	repo := NewVehicleImageRepository(suite.DB)
+	err := repo.SetPrimary(context.Background(), "v1", "i2")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *VehicleImageRepoTestSuite) TestLockGallerySuccess() {
+	suite.mock.ExpectQuery(`SELECT "id" FROM "mst_vehicle" WHERE id = \$1 AND "mst_vehicle"."deleted_at" IS NULL ORDER BY "mst_vehicle"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs("v1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("v1"))
+	repo := NewVehicleImageRepository(suite.DB)
+	err := repo.LockGallery(context.Background(), "v1")
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func (suite *VehicleImageRepoTestSuite) TestDeleteOtherVehicleFail() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`DELETE FROM "mst_vehicle_image" WHERE vehicle_id = \$1 AND id = \$2`).
+		WithArgs("v2", "i1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+	repo := NewVehicleImageRepository(suite.DB)
+	err := repo.Delete(context.Background(), "v2", "i1")
+	assert.ErrorIs(suite.T(), err, apperror.NotFound("VEHICLE_IMAGE_NOT_FOUND", ""))
+}
+
+func TestVehicleImageRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(VehicleImageRepoTestSuite))
+}
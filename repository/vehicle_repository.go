@@ -1,8 +1,8 @@
 package repository
 
 import (
-	"errors"
-	"fmt"
+	"context"
+	"strings"
 
 	"github.com/fajritsaniy/golang-SHM/model"
 	"github.com/fajritsaniy/golang-SHM/model/dto"
@@ -13,91 +13,233 @@ import (
 
 type VehicleRepository interface {
 	BaseRepository[model.Vehicle]
-	BaseRepositoryPaging[model.Vehicle]
-	UpdateStock(count int, id string) error
+	// Paging lists the vehicles matching filter and the search words of
+	// QueryParams.Query. A search without an order puts the best matches
+	// first.
+	Paging(ctx context.Context, filter dto.VehicleFilter, requestQueryParams dto.RequestQueryParams) ([]model.Vehicle, dto.Paging, error)
+	Facets(ctx context.Context, filter dto.VehicleFilter, query string) (dto.VehicleFacets, error)
+	UpdateStock(ctx context.Context, count int, id string) error
 }
 
+// maxSearchWords bounds the conditions a single search adds.
+const maxSearchWords = 8
+
 type vehicleRepository struct {
 	db *gorm.DB
 }
 
-func (v *vehicleRepository) Search(by map[string]interface{}) ([]model.Vehicle, error) {
+func (v *vehicleRepository) Search(ctx context.Context, by map[string]interface{}) ([]model.Vehicle, error) {
 	var vehicles []model.Vehicle
-	result := v.db.Where(by).Find(&vehicles)
+	result := v.db.WithContext(ctx).Where(by).Find(&vehicles)
 	if err := result.Error; err != nil {
 		return vehicles, err
 	}
 	return vehicles, nil
 }
 
-func (v *vehicleRepository) List() ([]model.Vehicle, error) {
+func (v *vehicleRepository) List(ctx context.Context) ([]model.Vehicle, error) {
 	var vehicles []model.Vehicle
-	result := v.db.Preload(clause.Associations).Find(&vehicles)
+	result := v.preloadList(v.db.WithContext(ctx)).Find(&vehicles)
 	if err := result.Error; err != nil {
 		return nil, err
 	}
 	return vehicles, nil
 }
 
-func (v *vehicleRepository) Get(id string) (*model.Vehicle, error) {
+func (v *vehicleRepository) Get(ctx context.Context, id string) (*model.Vehicle, error) {
 	var vehicle model.Vehicle
-	result := v.db.Preload(clause.Associations).First(&vehicle, "id = ?", id)
+	result := v.preloadList(v.db.WithContext(ctx)).
+		Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		First(&vehicle, "id = ?", id)
 	if err := result.Error; err != nil {
-		return nil, err
+		return nil, translateError(err, "vehicle")
 	}
 	return &vehicle, nil
 }
 
-func (v *vehicleRepository) Save(payload *model.Vehicle) error {
-	result := v.db.Save(payload)
+func (v *vehicleRepository) Save(ctx context.Context, payload *model.Vehicle) error {
+	if payload.ID == "" {
+		return translateError(v.db.WithContext(ctx).Create(payload).Error, "vehicle")
+	}
+
+	// optimistic locking: only update the row the client has read
+	version := payload.Version
+	payload.Version = version + 1
+	result := v.db.WithContext(ctx).Model(payload).
+		Select("*").
+		Omit("created_at", clause.Associations).
+		Where("version = ?", version).
+		Updates(payload)
 	if err := result.Error; err != nil {
-		return err
+		payload.Version = version
+		return translateError(err, "vehicle")
+	}
+	if result.RowsAffected == 0 {
+		payload.Version = version
+		return model.ErrVehicleVersionConflict
 	}
 	return nil
 }
 
-func (v *vehicleRepository) Delete(id string) error {
-	return v.db.Delete(&model.Vehicle{}, "id=?", id).Error
+func (v *vehicleRepository) Delete(ctx context.Context, id string) error {
+	return translateError(v.db.WithContext(ctx).Delete(&model.Vehicle{}, "id=?", id).Error, "vehicle")
 }
 
-func (v *vehicleRepository) UpdateStock(count int, id string) error {
-	result := v.db.Model(&model.Vehicle{}).Where("id=?", id).Update("stock", gorm.Expr("stock - ?", count))
+func (v *vehicleRepository) UpdateStock(ctx context.Context, count int, id string) error {
+	// the stock check and the decrement happen in one statement, so two
+	// concurrent sales can never both take the last unit
+	result := v.db.WithContext(ctx).Model(&model.Vehicle{}).
+		Where("id = ? AND stock >= ?", id, count).
+		Updates(map[string]interface{}{
+			"stock":   gorm.Expr("stock - ?", count),
+			"version": gorm.Expr("version + 1"),
+		})
 	if err := result.Error; err != nil {
 		return err
 	}
+	if result.RowsAffected == 0 {
+		return model.ErrVehicleOutOfStock
+	}
 	return nil
 }
 
-func (v *vehicleRepository) Paging(requestQueryParams dto.RequestQueryParams) ([]model.Vehicle, dto.Paging, error) {
-	var paginationQuery dto.PaginationQuery
-	var vehicles []model.Vehicle
-	paginationQuery = common.GetPaginationParams(requestQueryParams.PaginationParam)
-	orderQuery := "id"
-	if requestQueryParams.QueryParams.Order != "" && requestQueryParams.QueryParams.Sort != "" {
-		sorting := "ASC"
-		if requestQueryParams.QueryParams.Sort == "desc" {
-			sorting = "DESC"
-		}
-		orderQuery = fmt.Sprintf("%s %s", requestQueryParams.QueryParams.Order, sorting)
-	}
+func (v *vehicleRepository) Paging(ctx context.Context, filter dto.VehicleFilter, requestQueryParams dto.RequestQueryParams) ([]model.Vehicle, dto.Paging, error) {
+	paginationQuery := common.GetPaginationParams(requestQueryParams.PaginationParam)
+	queryParams := requestQueryParams.QueryParams
 
-	res := v.db.Order(orderQuery).Limit(paginationQuery.Take).Offset(paginationQuery.Skip).Preload(clause.Associations).Find(&vehicles)
-	if err := res.Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, dto.Paging{}, nil
-		} else {
-			return nil, dto.Paging{}, err
-		}
+	var totalRows int64
+	if err := v.filtered(ctx, filter, queryParams.Query).Count(&totalRows).Error; err != nil {
+		return nil, dto.Paging{}, err
 	}
 
-	var totalRows int64
-	err := v.db.Model(&model.Vehicle{}).Count(&totalRows).Error
+	query := v.preloadList(v.filtered(ctx, filter, queryParams.Query)).Select("mst_vehicle.*")
+	// ties are broken by id, so pages do not overlap
+	switch {
+	case queryParams.Order != "" && queryParams.Order != "id":
+		query = query.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: "mst_vehicle", Name: queryParams.Order},
+			Desc:   queryParams.Sort == "DESC",
+		}).Order("mst_vehicle.id")
+	case queryParams.Order == "" && queryParams.Query != "":
+		query = query.Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "word_similarity(?, concat_ws(' ', mst_brand.name, mst_vehicle.model, mst_vehicle.color)) DESC, mst_vehicle.id",
+			Vars:               []interface{}{queryParams.Query},
+			WithoutParentheses: true,
+		}})
+	default:
+		query = query.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: "mst_vehicle", Name: "id"},
+			Desc:   queryParams.Sort == "DESC",
+		})
+	}
+	var vehicles []model.Vehicle
+	err := query.Limit(paginationQuery.Take).Offset(paginationQuery.Skip).Find(&vehicles).Error
 	if err != nil {
 		return nil, dto.Paging{}, err
 	}
 	return vehicles, common.Paginate(paginationQuery.Page, paginationQuery.Take, int(totalRows)), nil
 }
 
+func (v *vehicleRepository) Facets(ctx context.Context, filter dto.VehicleFilter, query string) (dto.VehicleFacets, error) {
+	var facets dto.VehicleFacets
+
+	withoutBrand := filter
+	withoutBrand.BrandID = ""
+	err := v.filtered(ctx, withoutBrand, query).
+		Select("mst_vehicle.brand_id AS value, mst_brand.name AS label, count(*) AS count").
+		Group("mst_vehicle.brand_id, mst_brand.name").
+		Order("count DESC, label").
+		Scan(&facets.Brands).Error
+	if err != nil {
+		return facets, err
+	}
+
+	withoutStatus := filter
+	withoutStatus.Status = ""
+	err = v.filtered(ctx, withoutStatus, query).
+		Select("mst_vehicle.status AS value, count(*) AS count").
+		Group("mst_vehicle.status").
+		Order("count DESC, value").
+		Scan(&facets.Statuses).Error
+	if err != nil {
+		return facets, err
+	}
+
+	withoutTransmission := filter
+	withoutTransmission.IsAutomatic = nil
+	err = v.filtered(ctx, withoutTransmission, query).
+		Select("CASE WHEN mst_vehicle.is_automatic THEN 'automatic' ELSE 'manual' END AS value, count(*) AS count").
+		Group("value").
+		Order("count DESC, value").
+		Scan(&facets.Transmissions).Error
+	if err != nil {
+		return facets, err
+	}
+
+	// colours are typed in by hand, so "Red" and "red" count together
+	withoutColor := filter
+	withoutColor.Color = ""
+	err = v.filtered(ctx, withoutColor, query).
+		Select("lower(mst_vehicle.color) AS value, count(*) AS count").
+		Group("value").
+		Order("count DESC, value").
+		Scan(&facets.Colors).Error
+	return facets, err
+}
+
+// filtered selects the vehicles matching filter and every word of query.
+// A word matches anywhere in the brand name, the model or the colour, which
+// the trigram indexes serve.
+func (v *vehicleRepository) filtered(ctx context.Context, filter dto.VehicleFilter, query string) *gorm.DB {
+	db := v.db.WithContext(ctx).Model(&model.Vehicle{}).
+		Joins("LEFT JOIN mst_brand ON mst_brand.id = mst_vehicle.brand_id")
+	if filter.BrandID != "" {
+		db = db.Where("mst_vehicle.brand_id = ?", filter.BrandID)
+	}
+	if filter.Status != "" {
+		db = db.Where("mst_vehicle.status = ?", filter.Status)
+	}
+	if filter.IsAutomatic != nil {
+		db = db.Where("mst_vehicle.is_automatic = ?", *filter.IsAutomatic)
+	}
+	if filter.Color != "" {
+		db = db.Where("mst_vehicle.color ILIKE ?", escapeLike(filter.Color))
+	}
+	if filter.MinYear > 0 {
+		db = db.Where("mst_vehicle.production_year >= ?", filter.MinYear)
+	}
+	if filter.MaxYear > 0 {
+		db = db.Where("mst_vehicle.production_year <= ?", filter.MaxYear)
+	}
+	if filter.MinPrice > 0 {
+		db = db.Where("mst_vehicle.sale_price >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		db = db.Where("mst_vehicle.sale_price <= ?", filter.MaxPrice)
+	}
+	if filter.InStock {
+		db = db.Where("mst_vehicle.stock > 0")
+	}
+
+	words := strings.Fields(query)
+	for _, word := range words[:min(len(words), maxSearchWords)] {
+		pattern := "%" + escapeLike(word) + "%"
+		db = db.Where("mst_brand.name ILIKE ? OR mst_vehicle.model ILIKE ? OR mst_vehicle.color ILIKE ?", pattern, pattern, pattern)
+	}
+	return db
+}
+
+// escapeLike makes the wildcards of s match themselves in a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// preloadList loads what a vehicle in a list shows: the brand, the owners
+// and the primary image, but not the whole gallery.
+func (v *vehicleRepository) preloadList(db *gorm.DB) *gorm.DB {
+	return db.Preload("Brand").Preload("Customers").Preload("PrimaryImage", "is_primary = ?", true)
+}
+
 func NewVehicleRepository(db *gorm.DB) VehicleRepository {
 	return &vehicleRepository{db: db}
 }
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type VehicleRepoTestSuite struct {
+	suite.Suite
+	DB   *gorm.DB
+	mock sqlmock.Sqlmock
+}
+
+func (suite *VehicleRepoTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+
+	suite.mock = mock
+	dialect := postgres.New(postgres.Config{
+		Conn: db,
+	})
+	suite.DB, err = gorm.Open(dialect)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *VehicleRepoTestSuite) TestUpdateStockSuccess() {
+	suite.mock.ExpectBegin()
+	expectedQuery := `UPDATE "mst_vehicle" SET "stock"=stock - \$1,"version"=version \+ 1,"updated_at"=\$2 WHERE \(id = \$3 AND stock >= \$4\)`
+	suite.mock.ExpectExec(expectedQuery).
+		WithArgs(1, sqlmock.AnyArg(), "1", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	repo := NewVehicleRepository(suite.DB)
+	err := repo.UpdateStock(context.Background(), 1, "1")
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *VehicleRepoTestSuite) TestUpdateStockOutOfStockFail() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`UPDATE "mst_vehicle" SET "stock"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+	repo := NewVehicleRepository(suite.DB)
+	err := repo.UpdateStock(context.Background(), 2, "1")
+	assert.ErrorIs(suite.T(), err, model.ErrVehicleOutOfStock)
+}
+
+func (suite *VehicleRepoTestSuite) TestUpdateStockDBErrorFail() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`UPDATE "mst_vehicle" SET "stock"`).
+		WillReturnError(errors.New(dbErrorMessage))
+	suite.mock.ExpectRollback()
+	repo := NewVehicleRepository(suite.DB)
+	err := repo.UpdateStock(context.Background(), 1, "1")
+	assert.Error(suite.T(), err)
+	assert.NotErrorIs(suite.T(), err, model.ErrVehicleOutOfStock)
+}
+
+func (suite *VehicleRepoTestSuite) TestSaveVersionSuccess() {
+	vehicle := model.Vehicle{BaseModel: model.BaseModel{ID: "1"}, Model: "Jazz", Version: 3}
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`UPDATE "mst_vehicle" SET .*"version"=\$\d+.* WHERE version = \$\d+ AND .*"id" = \$\d+`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+	repo := NewVehicleRepository(suite.DB)
+	err := repo.Save(context.Background(), &vehicle)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 4, vehicle.Version)
+}
+
+func (suite *VehicleRepoTestSuite) TestSaveVersionConflictFail() {
+	vehicle := model.Vehicle{BaseModel: model.BaseModel{ID: "1"}, Model: "Jazz", Version: 3}
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectExec(`UPDATE "mst_vehicle" SET`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectCommit()
+	repo := NewVehicleRepository(suite.DB)
+	err := repo.Save(context.Background(), &vehicle)
+	assert.ErrorIs(suite.T(), err, model.ErrVehicleVersionConflict)
+	assert.Equal(suite.T(), 3, vehicle.Version)
+}
+
+func (suite *VehicleRepoTestSuite) TestPagingFilterSearchSuccess() {
+	automatic := true
+	filter := dto.VehicleFilter{Status: "bekas", IsAutomatic: &automatic, MinPrice: 100, InStock: true}
+	params := dto.RequestQueryParams{
+		QueryParams:     dto.QueryParams{Query: "toyota 50%", Sort: "ASC"},
+		PaginationParam: dto.PaginationParam{Page: 2, Limit: 10},
+	}
+	where := `WHERE mst_vehicle.status = \$1 AND mst_vehicle.is_automatic = \$2 AND mst_vehicle.sale_price >= \$3 AND mst_vehicle.stock > 0 ` +
+		`AND \(mst_brand.name ILIKE \$4 OR mst_vehicle.model ILIKE \$5 OR mst_vehicle.color ILIKE \$6\) ` +
+		`AND \(mst_brand.name ILIKE \$7 OR mst_vehicle.model ILIKE \$8 OR mst_vehicle.color ILIKE \$9\) ` +
+		`AND "mst_vehicle"."deleted_at" IS NULL`
+	suite.mock.ExpectQuery(`SELECT count\(\*\) FROM "mst_vehicle" LEFT JOIN mst_brand ON mst_brand.id = mst_vehicle.brand_id `+where).
+		WithArgs("bekas", true, 100, "%toyota%", "%toyota%", "%toyota%", `%50\%%`, `%50\%%`, `%50\%%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+	suite.mock.ExpectQuery(`SELECT mst_vehicle.\* FROM "mst_vehicle" LEFT JOIN mst_brand .* ` + where +
+		` ORDER BY word_similarity\(\$10, .*\) DESC, mst_vehicle.id LIMIT 10 OFFSET 10`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	repo := NewVehicleRepository(suite.DB)
+	vehicles, paging, err := repo.Paging(context.Background(), filter, params)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), vehicles)
+	assert.Equal(suite.T(), 2, paging.TotalPages)
+	assert.NoError(suite.T(), suite.mock.ExpectationsWereMet())
+}
+
+func TestVehicleRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(VehicleRepoTestSuite))
+}
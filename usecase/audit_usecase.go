@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+	"github.com/fajritsaniy/golang-SHM/repository"
+)
+
+type AuditUseCase interface {
+	Pagination(ctx context.Context, filter dto.AuditLogFilter, requestQueryParams dto.RequestQueryParams) ([]model.AuditLog, dto.Paging, error)
+}
+
+type auditUseCase struct {
+	repo repository.AuditRepository
+}
+
+func (a *auditUseCase) Pagination(ctx context.Context, filter dto.AuditLogFilter, requestQueryParams dto.RequestQueryParams) ([]model.AuditLog, dto.Paging, error) {
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, dto.Paging{}, invalidField("to", "audit.to_before_from", nil)
+	}
+	return a.repo.Paging(ctx, filter, requestQueryParams)
+}
+
+func NewAuditUseCase(repo repository.AuditRepository) AuditUseCase {
+	return &auditUseCase{repo: repo}
+}
@@ -1,73 +1,459 @@
 package usecase
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/fajritsaniy/golang-SHM/config"
 	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
 	"github.com/fajritsaniy/golang-SHM/utils"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/fajritsaniy/golang-SHM/utils/notification"
 	"github.com/fajritsaniy/golang-SHM/utils/security"
-	"gorm.io/gorm"
+	"github.com/google/uuid"
 
 	"github.com/fajritsaniy/golang-SHM/repository"
 )
 
 type AuthenticationUseCase interface {
-	Login(username string, password string) (string, error)
-	Register(payload *model.UserCredential) error
-	UserActivation(payload *model.UserCredential) (bool, error)
+	Login(ctx context.Context, username string, password string, clientIP string) (dto.TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string) (dto.TokenPair, error)
+	Logout(ctx context.Context, principal model.Principal, refreshToken string) error
+	ChangePassword(ctx context.Context, principal model.Principal, payload dto.ChangePasswordRequest) (dto.TokenPair, error)
+	ForgotPassword(ctx context.Context, username string) error
+	ResetPassword(ctx context.Context, payload dto.ResetPasswordRequest) error
+	Register(ctx context.Context, payload *model.UserCredential) error
+	UpdateUsername(ctx context.Context, principal model.Principal, userID string, payload dto.UpdateUsernameRequest) error
+	UserActivation(ctx context.Context, payload *model.UserCredential) (bool, error)
+	ChangeRole(ctx context.Context, payload *model.UserCredential) error
+	UnlockUser(ctx context.Context, principal model.Principal, username string) error
+	CreateUser(ctx context.Context, payload *model.UserCredential) error
+	SetUserActive(ctx context.Context, username string, active bool) error
+	SetPassword(ctx context.Context, username string, password string) error
+	SecurityEvents(ctx context.Context, requestQueryParams dto.RequestQueryParams) ([]model.SecurityEvent, dto.Paging, error)
 }
 
 type authenticationUseCase struct {
-	repo         repository.UserRepository
-	tokenService security.AccessToken
+	repo               repository.UserRepository
+	tokenRepo          repository.TokenRepository
+	passwordResetRepo  repository.PasswordResetRepository
+	employeeRepo       repository.EmployeeRepository
+	customerRepo       repository.CustomerRepository
+	securityRepo       repository.SecurityRepository
+	uow                repository.UnitOfWork
+	tokenService       security.AccessToken
+	notifier           notification.Notifier
+	resetTokenLifeTime time.Duration
+	loginConfig        config.LoginConfig
 }
 
-func (a *authenticationUseCase) Login(username string, password string) (string, error) {
-	user, err := a.repo.GetByUsernamePassword(username, password)
-	var token string
-	if err != nil {
-		return "", fmt.Errorf("user with username: %s not found", username)
+const codeUserNotFound = "USER_NOT_FOUND"
+
+var (
+	errInvalidRefreshToken = apperror.Unauthorized("INVALID_REFRESH_TOKEN", "auth.invalid_refresh_token")
+	errInvalidResetToken   = apperror.Validation("INVALID_RESET_TOKEN", "auth.invalid_reset_token", nil)
+	errNotOwnAccount       = apperror.Forbidden(apperror.CodeForbidden, "error.forbidden")
+)
+
+func validateNewPassword(field string, password string) error {
+	if len(password) < model.MinPasswordLength {
+		return invalidField(field, "auth.password_too_short", i18n.Args{"min": model.MinPasswordLength})
 	}
-	if user != nil {
-		token, err = a.tokenService.CreateAccessToken(user)
-		fmt.Println("token:", token)
+	return nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (a *authenticationUseCase) Login(ctx context.Context, username string, password string, clientIP string) (dto.TokenPair, error) {
+	invalidCredentials := apperror.Unauthorized("INVALID_CREDENTIALS", "auth.invalid_credentials")
+
+	if a.loginConfig.IPMaxAttempts > 0 && clientIP != "" {
+		since := time.Now().Add(-a.loginConfig.IPWindow)
+		failures, err := a.securityRepo.CountFailedLoginsByIP(ctx, clientIP, since)
 		if err != nil {
-			return "", err
+			return dto.TokenPair{}, err
+		}
+		if failures >= int64(a.loginConfig.IPMaxAttempts) {
+			err := a.securityRepo.SaveEvent(ctx, &model.SecurityEvent{
+				Event:    model.SecurityEventIPThrottle,
+				UserName: username,
+				ClientIP: clientIP,
+				Detail:   fmt.Sprintf("%d failed logins within %s", failures, a.loginConfig.IPWindow),
+			})
+			if err != nil {
+				return dto.TokenPair{}, err
+			}
+			return dto.TokenPair{}, model.ErrTooManyLoginAttempts
 		}
 	}
-	return token, nil
+
+	user, err := a.repo.GetByUsername(ctx, username)
+	if err != nil {
+		if err := a.recordLoginAttempt(ctx, username, clientIP, false); err != nil {
+			return dto.TokenPair{}, err
+		}
+		return dto.TokenPair{}, invalidCredentials
+	}
+
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		if err := a.recordLoginAttempt(ctx, username, clientIP, false); err != nil {
+			return dto.TokenPair{}, err
+		}
+		return dto.TokenPair{}, apperror.New(apperror.KindLocked, model.ErrAccountLocked.Code, "auth.account_locked_until").
+			With(i18n.Args{"until": user.LockedUntil.Format(time.RFC3339)})
+	}
+
+	if !utils.CheckPasswordHash(password, user.Password) {
+		if err := a.recordLoginAttempt(ctx, username, clientIP, false); err != nil {
+			return dto.TokenPair{}, err
+		}
+		if err := a.registerFailedLogin(ctx, user, clientIP); err != nil {
+			return dto.TokenPair{}, err
+		}
+		return dto.TokenPair{}, invalidCredentials
+	}
+
+	if !user.IsActive {
+		if err := a.recordLoginAttempt(ctx, username, clientIP, false); err != nil {
+			return dto.TokenPair{}, err
+		}
+		return dto.TokenPair{}, invalidCredentials
+	}
+
+	if err := a.recordLoginAttempt(ctx, username, clientIP, true); err != nil {
+		return dto.TokenPair{}, err
+	}
+	if user.FailedLoginCount > 0 || user.LockoutCount > 0 || user.LockedUntil != nil {
+		if err := a.repo.ResetLoginFailures(ctx, user.ID); err != nil {
+			return dto.TokenPair{}, err
+		}
+	}
+	// every login starts a new refresh token family
+	return a.issueTokenPair(ctx, user, uuid.New().String(), "")
 }
 
-func (a *authenticationUseCase) Register(payload *model.UserCredential) error {
-	if payload.ID != "" {
-		_, err := a.repo.Get(payload.ID)
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return fmt.Errorf("user with ID '%s' not found", payload.ID)
-			}
-			return fmt.Errorf("failed to check user with ID '%s': %v", payload.ID, err)
+func (a *authenticationUseCase) recordLoginAttempt(ctx context.Context, username string, clientIP string, success bool) error {
+	return a.securityRepo.SaveLoginAttempt(ctx, &model.LoginAttempt{
+		UserName: username,
+		ClientIP: clientIP,
+		Success:  success,
+	})
+}
+
+// registerFailedLogin counts a wrong password and locks the account once the
+// limit is reached. Every lockout in a row doubles the lock time.
+func (a *authenticationUseCase) registerFailedLogin(ctx context.Context, user *model.UserCredential, clientIP string) error {
+	failures, err := a.repo.IncrementFailedLogin(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if a.loginConfig.MaxAttempts <= 0 || failures < a.loginConfig.MaxAttempts {
+		return nil
+	}
+
+	lockoutCount := user.LockoutCount + 1
+	duration := a.loginConfig.LockoutDuration
+	for i := 1; i < lockoutCount && duration < a.loginConfig.MaxLockoutDuration; i++ {
+		duration *= 2
+	}
+	if duration > a.loginConfig.MaxLockoutDuration {
+		duration = a.loginConfig.MaxLockoutDuration
+	}
+	lockedUntil := time.Now().Add(duration)
+	if err := a.repo.LockAccount(ctx, user.ID, lockedUntil, lockoutCount); err != nil {
+		return err
+	}
+	return a.securityRepo.SaveEvent(ctx, &model.SecurityEvent{
+		Event:    model.SecurityEventLockout,
+		UserName: user.UserName,
+		ClientIP: clientIP,
+		Detail: fmt.Sprintf("locked until %s after %d failed attempts (lockout #%d)",
+			lockedUntil.Format(time.RFC3339), failures, lockoutCount),
+	})
+}
+
+func (a *authenticationUseCase) RefreshToken(ctx context.Context, refreshToken string) (dto.TokenPair, error) {
+	claims, err := a.tokenService.VerifyRefreshToken(refreshToken)
+	if err != nil {
+		return dto.TokenPair{}, errInvalidRefreshToken
+	}
+	tokenID, _ := claims["jti"].(string)
+	stored, err := a.tokenRepo.GetRefreshToken(ctx, tokenID)
+	if err != nil {
+		return dto.TokenPair{}, errInvalidRefreshToken
+	}
+
+	// a rotated token presented again means it leaked, so the whole family goes
+	if stored.RevokedAt != nil {
+		if err := a.tokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+			return dto.TokenPair{}, err
+		}
+		return dto.TokenPair{}, model.ErrRefreshTokenReused
+	}
+
+	user, err := a.repo.Get(ctx, stored.UserCredentialID)
+	if err != nil || !user.IsActive {
+		if err := a.tokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+			return dto.TokenPair{}, err
 		}
+		return dto.TokenPair{}, errInvalidRefreshToken
 	}
 
-	if payload.Password != "" {
-		password, err := utils.HashPassword(payload.Password)
+	pair, err := a.issueTokenPair(ctx, user, stored.FamilyID, stored.TokenID)
+	if errors.Is(err, model.ErrRefreshTokenReused) {
+		if err := a.tokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+			return dto.TokenPair{}, err
+		}
+	}
+	return pair, err
+}
+
+func (a *authenticationUseCase) Logout(ctx context.Context, principal model.Principal, refreshToken string) error {
+	err := a.tokenRepo.RevokeToken(ctx, &model.RevokedToken{
+		TokenID:   principal.TokenID,
+		ExpiresAt: principal.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	claims, err := a.tokenService.VerifyRefreshToken(refreshToken)
+	if err != nil || claims["sub"] != principal.UserID {
+		return errInvalidRefreshToken
+	}
+	familyID, _ := claims["FamilyID"].(string)
+	return a.tokenRepo.RevokeRefreshTokenFamily(ctx, familyID)
+}
+
+func (a *authenticationUseCase) ChangePassword(ctx context.Context, principal model.Principal, payload dto.ChangePasswordRequest) (dto.TokenPair, error) {
+	if err := validateNewPassword("newPassword", payload.NewPassword); err != nil {
+		return dto.TokenPair{}, err
+	}
+	if payload.NewPassword == payload.OldPassword {
+		return dto.TokenPair{}, invalidField("newPassword", "auth.password_unchanged", nil)
+	}
+
+	user, err := a.repo.Get(ctx, principal.UserID)
+	if err != nil {
+		return dto.TokenPair{}, notFound(err, codeUserNotFound, "auth.user_not_found", i18n.Args{"id": principal.UserID})
+	}
+	if !utils.CheckPasswordHash(payload.OldPassword, user.Password) {
+		return dto.TokenPair{}, invalidField("oldPassword", "auth.old_password_incorrect", nil)
+	}
+
+	password, err := utils.HashPassword(payload.NewPassword)
+	if err != nil {
+		return dto.TokenPair{}, err
+	}
+	user.Password = password
+	user.MustChangePassword = false
+	if err := a.repo.Save(ctx, user); err != nil {
+		return dto.TokenPair{}, err
+	}
+
+	// the current token still says the password must change, swap it
+	err = a.tokenRepo.RevokeToken(ctx, &model.RevokedToken{
+		TokenID:   principal.TokenID,
+		ExpiresAt: principal.ExpiresAt,
+	})
+	if err != nil {
+		return dto.TokenPair{}, err
+	}
+	if err := a.tokenRepo.RevokeUserRefreshTokens(ctx, user.ID); err != nil {
+		return dto.TokenPair{}, err
+	}
+	return a.issueTokenPair(ctx, user, uuid.New().String(), "")
+}
+
+func (a *authenticationUseCase) ForgotPassword(ctx context.Context, username string) error {
+	user, err := a.repo.GetByUsername(ctx, username)
+	if err != nil {
+		// do not reveal whether the username exists
+		return nil
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(raw)
+	resetToken := &model.PasswordResetToken{
+		UserCredentialID: user.ID,
+		TokenHash:        hashResetToken(token),
+		ExpiresAt:        time.Now().Add(a.resetTokenLifeTime),
+	}
+	if err := a.passwordResetRepo.Save(ctx, resetToken); err != nil {
+		return err
+	}
+
+	// the message goes out in the language the reset was requested in
+	locale := i18n.FromContext(ctx)
+	return a.notifier.Send(notification.Message{
+		Recipient: user.UserName,
+		Subject:   i18n.T(locale, "notification.password_reset.subject", nil),
+		Body: i18n.T(locale, "notification.password_reset.body", i18n.Args{
+			"token":     token,
+			"expiresAt": resetToken.ExpiresAt.Format(time.RFC1123),
+		}),
+	})
+}
+
+func (a *authenticationUseCase) ResetPassword(ctx context.Context, payload dto.ResetPasswordRequest) error {
+	if err := validateNewPassword("newPassword", payload.NewPassword); err != nil {
+		return err
+	}
+
+	resetToken, err := a.passwordResetRepo.GetByTokenHash(ctx, hashResetToken(payload.Token))
+	if err != nil || resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		return errInvalidResetToken
+	}
+
+	// the token is only spent when the new password is stored
+	return a.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		if err := repos.PasswordResetRepo().MarkUsed(ctx, resetToken.ID); err != nil {
+			return errInvalidResetToken
+		}
+
+		userRepo := repos.UserRepo()
+		user, err := userRepo.Get(ctx, resetToken.UserCredentialID)
 		if err != nil {
+			return notFound(err, codeUserNotFound, "auth.user_not_found", i18n.Args{"id": resetToken.UserCredentialID})
+		}
+		password, err := utils.HashPassword(payload.NewPassword)
+		if err != nil {
+			return err
+		}
+		user.Password = password
+		user.MustChangePassword = false
+		if err := userRepo.Save(ctx, user); err != nil {
 			return err
 		}
-		payload.Password = password
+		// sessions opened with the old password are signed out
+		return repos.TokenRepo().RevokeUserRefreshTokens(ctx, user.ID)
+	})
+}
+
+// issueTokenPair signs a new access/refresh pair. When previousTokenID is set
+// the stored refresh token is rotated, otherwise a new one is saved.
+func (a *authenticationUseCase) issueTokenPair(ctx context.Context, user *model.UserCredential, familyID string, previousTokenID string) (dto.TokenPair, error) {
+	principal := a.principalOf(ctx, user)
+	accessToken, err := a.tokenService.CreateAccessToken(principal)
+	if err != nil {
+		return dto.TokenPair{}, err
+	}
+	refreshToken, err := a.tokenService.CreateRefreshToken(principal, familyID)
+	if err != nil {
+		return dto.TokenPair{}, err
 	}
 
-	return a.repo.Save(payload)
+	stored := &model.RefreshToken{
+		UserCredentialID: user.ID,
+		FamilyID:         familyID,
+		TokenID:          refreshToken.TokenID,
+		ExpiresAt:        refreshToken.ExpiresAt,
+		AccessTokenID:    accessToken.TokenID,
+		AccessExpiresAt:  accessToken.ExpiresAt,
+	}
+	if previousTokenID == "" {
+		err = a.tokenRepo.SaveRefreshToken(ctx, stored)
+	} else {
+		err = a.tokenRepo.RotateRefreshToken(ctx, previousTokenID, stored)
+	}
+	if err != nil {
+		return dto.TokenPair{}, err
+	}
+
+	return dto.TokenPair{
+		AccessToken:  accessToken.Token,
+		RefreshToken: refreshToken.Token,
+	}, nil
 }
 
-func (a *authenticationUseCase) UserActivation(payload *model.UserCredential) (bool, error) {
-	user, err := a.repo.GetByUsername(payload.UserName)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, fmt.Errorf("username '%s' not found", payload.UserName)
+// principalOf links the user to the customer or employee profile it signs in
+// for, so handlers know who is calling without another lookup.
+func (a *authenticationUseCase) principalOf(ctx context.Context, user *model.UserCredential) model.Principal {
+	principal := model.Principal{
+		UserID:             user.ID,
+		Username:           user.UserName,
+		Role:               user.Role,
+		MustChangePassword: user.MustChangePassword,
+	}
+	if user.Role == model.RoleCustomer {
+		if customer, err := a.customerRepo.GetByUser(ctx, user.ID); err == nil {
+			principal.CustomerID = customer.ID
+		}
+	} else {
+		if employee, err := a.employeeRepo.GetByUser(ctx, user.ID); err == nil {
+			principal.EmployeeID = employee.ID
 		}
-		return false, fmt.Errorf("failed to check user with username '%s': %v", payload.UserName, err)
+	}
+	return principal
+}
+
+func (a *authenticationUseCase) Register(ctx context.Context, payload *model.UserCredential) error {
+	// registration only creates accounts, existing ones change through
+	// UpdateUsername and the password flows
+	if payload.ID != "" {
+		return invalidField("id", "auth.register_with_id", nil)
+	}
+	if err := payload.Validate(); err != nil {
+		return apperror.FromValidation(err)
+	}
+	// self registration never grants a role
+	payload.Role = model.RoleCustomer
+	if err := validateNewPassword("password", payload.Password); err != nil {
+		return err
+	}
+
+	password, err := utils.HashPassword(payload.Password)
+	if err != nil {
+		return err
+	}
+	payload.Password = password
+	return a.repo.Save(ctx, payload)
+}
+
+// UpdateUsername renames a user. Administrators may rename any user, everyone
+// else only their own account. Passwords change through ChangePassword, which
+// asks for the old one, and SetPassword.
+func (a *authenticationUseCase) UpdateUsername(ctx context.Context, principal model.Principal, userID string, payload dto.UpdateUsernameRequest) error {
+	if principal.Role != model.RoleAdmin && principal.UserID != userID {
+		return errNotOwnAccount
+	}
+	user, err := a.repo.Get(ctx, userID)
+	if err != nil {
+		return notFound(err, codeUserNotFound, "auth.user_not_found", i18n.Args{"id": userID})
+	}
+	if payload.Username == user.UserName {
+		return nil
+	}
+
+	if _, err := a.repo.GetByUsername(ctx, payload.Username); err == nil {
+		return apperror.Conflict("USERNAME_TAKEN", "auth.username_taken").With(i18n.Args{"username": payload.Username})
+	} else if apperror.KindOf(err) != apperror.KindNotFound {
+		return err
+	}
+	user.UserName = payload.Username
+	return a.repo.Save(ctx, user)
+}
+
+func (a *authenticationUseCase) UserActivation(ctx context.Context, payload *model.UserCredential) (bool, error) {
+	user, err := a.repo.GetByUsername(ctx, payload.UserName)
+	if err != nil {
+		return false, notFound(err, codeUserNotFound, "auth.username_not_found", i18n.Args{"username": payload.UserName})
 	}
 
 	var status bool
@@ -78,9 +464,155 @@ func (a *authenticationUseCase) UserActivation(payload *model.UserCredential) (b
 	}
 	status = user.IsActive
 
-	return status, a.repo.Save(user)
+	return status, a.repo.Save(ctx, user)
 }
 
-func NewAuthenticationUseCase(repo repository.UserRepository, tokenService security.AccessToken) AuthenticationUseCase {
-	return &authenticationUseCase{repo: repo, tokenService: tokenService}
+func (a *authenticationUseCase) ChangeRole(ctx context.Context, payload *model.UserCredential) error {
+	if !payload.IsValidRole() {
+		return invalidField("role", "auth.invalid_role", i18n.Args{"role": payload.Role})
+	}
+
+	user, err := a.repo.GetByUsername(ctx, payload.UserName)
+	if err != nil {
+		return err
+	}
+
+	return a.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		user.Role = payload.Role
+		if err := repos.UserRepo().Save(ctx, user); err != nil {
+			return err
+		}
+		// tokens issued before carry the old role, the user signs in again
+		tokenRepo := repos.TokenRepo()
+		if err := tokenRepo.RevokeUserAccessTokens(ctx, user.ID); err != nil {
+			return err
+		}
+		return tokenRepo.RevokeUserRefreshTokens(ctx, user.ID)
+	})
+}
+
+func (a *authenticationUseCase) UnlockUser(ctx context.Context, principal model.Principal, username string) error {
+	user, err := a.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if err := a.repo.ResetLoginFailures(ctx, user.ID); err != nil {
+		return err
+	}
+	return a.securityRepo.SaveEvent(ctx, &model.SecurityEvent{
+		Event:    model.SecurityEventUnlock,
+		UserName: user.UserName,
+		Actor:    principal.Username,
+		Detail:   "unlocked by admin",
+	})
+}
+
+// CreateUser adds a user with any role. It is meant for administrators and
+// the command line, unlike Register which only creates customers.
+func (a *authenticationUseCase) CreateUser(ctx context.Context, payload *model.UserCredential) error {
+	if !payload.IsValidRole() {
+		return invalidField("role", "auth.invalid_role", i18n.Args{"role": payload.Role})
+	}
+	if err := validateNewPassword("password", payload.Password); err != nil {
+		return err
+	}
+	if err := payload.Validate(); err != nil {
+		return apperror.FromValidation(err)
+	}
+	if _, err := a.repo.GetByUsername(ctx, payload.UserName); err == nil {
+		return apperror.Conflict("USERNAME_TAKEN", "auth.username_taken").With(i18n.Args{"username": payload.UserName})
+	} else if apperror.KindOf(err) != apperror.KindNotFound {
+		return err
+	}
+
+	password, err := utils.HashPassword(payload.Password)
+	if err != nil {
+		return err
+	}
+	payload.ID = ""
+	payload.Password = password
+	return a.repo.Save(ctx, payload)
+}
+
+func (a *authenticationUseCase) SetUserActive(ctx context.Context, username string, active bool) error {
+	user, err := a.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return notFound(err, codeUserNotFound, "auth.username_not_found", i18n.Args{"username": username})
+	}
+	if user.IsActive == active {
+		return nil
+	}
+	user.IsActive = active
+	return a.repo.Save(ctx, user)
+}
+
+// SetPassword replaces a user's password without a reset token. The user has
+// to change it on the next login, and any lockout is lifted.
+func (a *authenticationUseCase) SetPassword(ctx context.Context, username string, password string) error {
+	if err := validateNewPassword("password", password); err != nil {
+		return err
+	}
+	user, err := a.repo.GetByUsername(ctx, username)
+	if err != nil {
+		return notFound(err, codeUserNotFound, "auth.username_not_found", i18n.Args{"username": username})
+	}
+
+	hashed, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	user.MustChangePassword = true
+	if err := a.repo.Save(ctx, user); err != nil {
+		return err
+	}
+	if err := a.repo.ResetLoginFailures(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := a.tokenRepo.RevokeUserRefreshTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	actor := "system"
+	if principal, ok := model.PrincipalFromContext(ctx); ok {
+		actor = principal.Username
+	}
+	return a.securityRepo.SaveEvent(ctx, &model.SecurityEvent{
+		Event:    model.SecurityEventPasswordSet,
+		UserName: user.UserName,
+		Actor:    actor,
+		Detail:   "password set by administrator",
+	})
+}
+
+func (a *authenticationUseCase) SecurityEvents(ctx context.Context, requestQueryParams dto.RequestQueryParams) ([]model.SecurityEvent, dto.Paging, error) {
+	return a.securityRepo.PagingEvents(ctx, requestQueryParams)
+}
+
+func NewAuthenticationUseCase(
+	repo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	passwordResetRepo repository.PasswordResetRepository,
+	employeeRepo repository.EmployeeRepository,
+	customerRepo repository.CustomerRepository,
+	securityRepo repository.SecurityRepository,
+	uow repository.UnitOfWork,
+	tokenService security.AccessToken,
+	notifier notification.Notifier,
+	resetTokenLifeTime time.Duration,
+	loginConfig config.LoginConfig,
+) AuthenticationUseCase {
+	return &authenticationUseCase{
+		repo:               repo,
+		tokenRepo:          tokenRepo,
+		passwordResetRepo:  passwordResetRepo,
+		employeeRepo:       employeeRepo,
+		customerRepo:       customerRepo,
+		securityRepo:       securityRepo,
+		uow:                uow,
+		tokenService:       tokenService,
+		notifier:           notifier,
+		resetTokenLifeTime: resetTokenLifeTime,
+		loginConfig:        loginConfig,
+	}
 }
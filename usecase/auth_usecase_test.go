@@ -0,0 +1,589 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/config"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/fajritsaniy/golang-SHM/utils"
+	"github.com/fajritsaniy/golang-SHM/utils/notification"
+	"github.com/fajritsaniy/golang-SHM/utils/security"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var userDummy = model.UserCredential{
+	BaseModel: model.BaseModel{ID: "u1"},
+	UserName:  "budi@mail.com",
+	Role:      model.RoleSales,
+	IsActive:  true,
+}
+
+type userRepoMock struct {
+	mock.Mock
+	repository.UserRepository
+}
+
+func (u *userRepoMock) GetByUsername(ctx context.Context, username string) (*model.UserCredential, error) {
+	args := u.Called(username)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserCredential), nil
+}
+
+func (u *userRepoMock) Save(ctx context.Context, payload *model.UserCredential) error {
+	return u.Called(payload).Error(0)
+}
+
+func (u *userRepoMock) Get(ctx context.Context, id string) (*model.UserCredential, error) {
+	args := u.Called(id)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserCredential), nil
+}
+
+func (u *userRepoMock) IncrementFailedLogin(ctx context.Context, id string) (int, error) {
+	args := u.Called(id)
+	return args.Int(0), args.Error(1)
+}
+
+func (u *userRepoMock) LockAccount(ctx context.Context, id string, until time.Time, lockoutCount int) error {
+	return u.Called(id, until, lockoutCount).Error(0)
+}
+
+func (u *userRepoMock) ResetLoginFailures(ctx context.Context, id string) error {
+	return u.Called(id).Error(0)
+}
+
+type securityRepoMock struct {
+	mock.Mock
+	repository.SecurityRepository
+}
+
+func (s *securityRepoMock) SaveLoginAttempt(ctx context.Context, payload *model.LoginAttempt) error {
+	return s.Called(payload).Error(0)
+}
+
+func (s *securityRepoMock) CountFailedLoginsByIP(ctx context.Context, clientIP string, since time.Time) (int64, error) {
+	args := s.Called(clientIP, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (s *securityRepoMock) SaveEvent(ctx context.Context, payload *model.SecurityEvent) error {
+	return s.Called(payload).Error(0)
+}
+
+type employeeRepoMock struct {
+	mock.Mock
+	repository.EmployeeRepository
+}
+
+func (e *employeeRepoMock) GetByUser(ctx context.Context, userId string) (*model.Employee, error) {
+	args := e.Called(userId)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Employee), nil
+}
+
+type passwordResetRepoMock struct {
+	mock.Mock
+}
+
+func (p *passwordResetRepoMock) Save(ctx context.Context, payload *model.PasswordResetToken) error {
+	return p.Called(payload).Error(0)
+}
+
+func (p *passwordResetRepoMock) GetByTokenHash(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	args := p.Called(tokenHash)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PasswordResetToken), nil
+}
+
+func (p *passwordResetRepoMock) MarkUsed(ctx context.Context, id string) error {
+	return p.Called(id).Error(0)
+}
+
+type notifierMock struct {
+	mock.Mock
+}
+
+func (n *notifierMock) Send(message notification.Message) error {
+	return n.Called(message).Error(0)
+}
+
+type tokenRepoMock struct {
+	mock.Mock
+}
+
+func (t *tokenRepoMock) SaveRefreshToken(ctx context.Context, payload *model.RefreshToken) error {
+	return t.Called(payload).Error(0)
+}
+
+func (t *tokenRepoMock) GetRefreshToken(ctx context.Context, tokenID string) (*model.RefreshToken, error) {
+	args := t.Called(tokenID)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RefreshToken), nil
+}
+
+func (t *tokenRepoMock) RotateRefreshToken(ctx context.Context, tokenID string, next *model.RefreshToken) error {
+	return t.Called(tokenID, next).Error(0)
+}
+
+func (t *tokenRepoMock) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	return t.Called(familyID).Error(0)
+}
+
+func (t *tokenRepoMock) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	return t.Called(userID).Error(0)
+}
+
+func (t *tokenRepoMock) RevokeUserAccessTokens(ctx context.Context, userID string) error {
+	return t.Called(userID).Error(0)
+}
+
+func (t *tokenRepoMock) RevokeToken(ctx context.Context, payload *model.RevokedToken) error {
+	return t.Called(payload).Error(0)
+}
+
+func (t *tokenRepoMock) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	args := t.Called(tokenID)
+	return args.Bool(0), args.Error(1)
+}
+
+// unitOfWorkStub hands the suite mocks to the work, without a transaction.
+type unitOfWorkStub struct {
+	repository.TxRepositories
+	userRepo  repository.UserRepository
+	tokenRepo repository.TokenRepository
+	resetRepo repository.PasswordResetRepository
+	imageRepo repository.VehicleImageRepository
+}
+
+func (u *unitOfWorkStub) Do(ctx context.Context, fn func(repos repository.TxRepositories) error) error {
+	return fn(u)
+}
+
+func (u *unitOfWorkStub) UserRepo() repository.UserRepository {
+	return u.userRepo
+}
+
+func (u *unitOfWorkStub) TokenRepo() repository.TokenRepository {
+	return u.tokenRepo
+}
+
+func (u *unitOfWorkStub) PasswordResetRepo() repository.PasswordResetRepository {
+	return u.resetRepo
+}
+
+func (u *unitOfWorkStub) VehicleImageRepo() repository.VehicleImageRepository {
+	return u.imageRepo
+}
+
+type AuthUseCaseTestSuite struct {
+	suite.Suite
+	userRepo     *userRepoMock
+	tokenRepo    *tokenRepoMock
+	employeeRepo *employeeRepoMock
+	resetRepo    *passwordResetRepoMock
+	securityRepo *securityRepoMock
+	notifier     *notifierMock
+	tokenService security.AccessToken
+	useCase      AuthenticationUseCase
+}
+
+func (suite *AuthUseCaseTestSuite) SetupTest() {
+	suite.userRepo = new(userRepoMock)
+	suite.tokenRepo = new(tokenRepoMock)
+	suite.employeeRepo = new(employeeRepoMock)
+	suite.resetRepo = new(passwordResetRepoMock)
+	suite.securityRepo = new(securityRepoMock)
+	suite.notifier = new(notifierMock)
+	suite.tokenService = security.NewAccessToken(config.TokenConfig{
+		ApplicationName:      "TEST",
+		JwtSignatureKey:      "secret",
+		JwtSigningMethod:     jwt.SigningMethodHS256,
+		AccessTokenLifeTime:  time.Minute,
+		RefreshTokenLifeTime: time.Hour,
+	}, suite.tokenRepo)
+	// customers never sign in during these tests, so no customer repository
+	suite.useCase = NewAuthenticationUseCase(
+		suite.userRepo,
+		suite.tokenRepo,
+		suite.resetRepo,
+		suite.employeeRepo,
+		nil,
+		suite.securityRepo,
+		&unitOfWorkStub{userRepo: suite.userRepo, tokenRepo: suite.tokenRepo, resetRepo: suite.resetRepo},
+		suite.tokenService,
+		suite.notifier,
+		time.Minute,
+		config.LoginConfig{
+			MaxAttempts:        3,
+			LockoutDuration:    15 * time.Minute,
+			MaxLockoutDuration: time.Hour,
+			IPMaxAttempts:      10,
+			IPWindow:           15 * time.Minute,
+		},
+	)
+}
+
+func (suite *AuthUseCaseTestSuite) userWithPassword(password string) *model.UserCredential {
+	user := userDummy
+	hashed, err := utils.HashPassword(password)
+	assert.NoError(suite.T(), err)
+	user.Password = hashed
+	return &user
+}
+
+func (suite *AuthUseCaseTestSuite) TestLoginResetsFailuresSuccess() {
+	user := suite.userWithPassword("secret-password")
+	user.FailedLoginCount = 2
+	suite.securityRepo.On("CountFailedLoginsByIP", "10.0.0.1", mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+	suite.securityRepo.On("SaveLoginAttempt", mock.AnythingOfType("*model.LoginAttempt")).Return(nil)
+	suite.userRepo.On("GetByUsername", user.UserName).Return(user, nil)
+	suite.userRepo.On("ResetLoginFailures", "u1").Return(nil)
+	suite.employeeRepo.On("GetByUser", "u1").Return(&employeeDummy, nil)
+	suite.tokenRepo.On("SaveRefreshToken", mock.AnythingOfType("*model.RefreshToken")).Return(nil)
+
+	pair, err := suite.useCase.Login(context.Background(), user.UserName, "secret-password", "10.0.0.1")
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), pair.AccessToken)
+	// the access token is recorded so it can be revoked before it expires
+	stored := suite.tokenRepo.Calls[0].Arguments.Get(0).(*model.RefreshToken)
+	assert.NotEmpty(suite.T(), stored.AccessTokenID)
+	assert.True(suite.T(), stored.AccessExpiresAt.After(time.Now()))
+	attempt := suite.securityRepo.Calls[1].Arguments.Get(0).(*model.LoginAttempt)
+	assert.True(suite.T(), attempt.Success)
+	suite.userRepo.AssertCalled(suite.T(), "ResetLoginFailures", "u1")
+}
+
+func (suite *AuthUseCaseTestSuite) TestLoginWrongPasswordCountsFailureFail() {
+	user := suite.userWithPassword("secret-password")
+	suite.securityRepo.On("CountFailedLoginsByIP", "10.0.0.1", mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+	suite.securityRepo.On("SaveLoginAttempt", mock.AnythingOfType("*model.LoginAttempt")).Return(nil)
+	suite.userRepo.On("GetByUsername", user.UserName).Return(user, nil)
+	suite.userRepo.On("IncrementFailedLogin", "u1").Return(1, nil)
+
+	_, err := suite.useCase.Login(context.Background(), user.UserName, "wrong-password", "10.0.0.1")
+	assert.Error(suite.T(), err)
+	assert.NotErrorIs(suite.T(), err, model.ErrAccountLocked)
+	suite.userRepo.AssertNotCalled(suite.T(), "LockAccount", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AuthUseCaseTestSuite) TestLoginLocksWithBackoffFail() {
+	user := suite.userWithPassword("secret-password")
+	user.LockoutCount = 1
+	suite.securityRepo.On("CountFailedLoginsByIP", "10.0.0.1", mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+	suite.securityRepo.On("SaveLoginAttempt", mock.AnythingOfType("*model.LoginAttempt")).Return(nil)
+	suite.securityRepo.On("SaveEvent", mock.AnythingOfType("*model.SecurityEvent")).Return(nil)
+	suite.userRepo.On("GetByUsername", user.UserName).Return(user, nil)
+	suite.userRepo.On("IncrementFailedLogin", "u1").Return(3, nil)
+	suite.userRepo.On("LockAccount", "u1", mock.AnythingOfType("time.Time"), 2).Return(nil)
+
+	before := time.Now()
+	_, err := suite.useCase.Login(context.Background(), user.UserName, "wrong-password", "10.0.0.1")
+	assert.Error(suite.T(), err)
+	// the second lockout in a row lasts twice as long
+	lockedUntil := suite.userRepo.Calls[2].Arguments.Get(1).(time.Time)
+	assert.WithinDuration(suite.T(), before.Add(30*time.Minute), lockedUntil, time.Second)
+	event := suite.securityRepo.Calls[2].Arguments.Get(0).(*model.SecurityEvent)
+	assert.Equal(suite.T(), model.SecurityEventLockout, event.Event)
+}
+
+func (suite *AuthUseCaseTestSuite) TestLoginLockedAccountFail() {
+	user := suite.userWithPassword("secret-password")
+	lockedUntil := time.Now().Add(time.Minute)
+	user.LockedUntil = &lockedUntil
+	suite.securityRepo.On("CountFailedLoginsByIP", "10.0.0.1", mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+	suite.securityRepo.On("SaveLoginAttempt", mock.AnythingOfType("*model.LoginAttempt")).Return(nil)
+	suite.userRepo.On("GetByUsername", user.UserName).Return(user, nil)
+
+	_, err := suite.useCase.Login(context.Background(), user.UserName, "secret-password", "10.0.0.1")
+	assert.ErrorIs(suite.T(), err, model.ErrAccountLocked)
+	suite.userRepo.AssertNotCalled(suite.T(), "IncrementFailedLogin", mock.Anything)
+}
+
+func (suite *AuthUseCaseTestSuite) TestLoginThrottledIPFail() {
+	suite.securityRepo.On("CountFailedLoginsByIP", "10.0.0.1", mock.AnythingOfType("time.Time")).Return(int64(10), nil)
+	suite.securityRepo.On("SaveEvent", mock.AnythingOfType("*model.SecurityEvent")).Return(nil)
+
+	_, err := suite.useCase.Login(context.Background(), userDummy.UserName, "secret-password", "10.0.0.1")
+	assert.ErrorIs(suite.T(), err, model.ErrTooManyLoginAttempts)
+	suite.userRepo.AssertNotCalled(suite.T(), "GetByUsername", mock.Anything)
+	event := suite.securityRepo.Calls[1].Arguments.Get(0).(*model.SecurityEvent)
+	assert.Equal(suite.T(), model.SecurityEventIPThrottle, event.Event)
+	assert.Equal(suite.T(), "10.0.0.1", event.ClientIP)
+	assert.Equal(suite.T(), userDummy.UserName, event.UserName)
+}
+
+func (suite *AuthUseCaseTestSuite) TestUnlockUserRecordsEventSuccess() {
+	user := userDummy
+	suite.userRepo.On("GetByUsername", user.UserName).Return(&user, nil)
+	suite.userRepo.On("ResetLoginFailures", "u1").Return(nil)
+	suite.securityRepo.On("SaveEvent", mock.AnythingOfType("*model.SecurityEvent")).Return(nil)
+
+	err := suite.useCase.UnlockUser(context.Background(), model.Principal{Username: "admin"}, user.UserName)
+	assert.NoError(suite.T(), err)
+	event := suite.securityRepo.Calls[0].Arguments.Get(0).(*model.SecurityEvent)
+	assert.Equal(suite.T(), model.SecurityEventUnlock, event.Event)
+	assert.Equal(suite.T(), "admin", event.Actor)
+}
+
+func (suite *AuthUseCaseTestSuite) newRefreshToken(familyID string) security.SignedToken {
+	refreshToken, err := suite.tokenService.CreateRefreshToken(model.Principal{UserID: userDummy.ID}, familyID)
+	assert.NoError(suite.T(), err)
+	return refreshToken
+}
+
+func (suite *AuthUseCaseTestSuite) TestRefreshTokenRotateSuccess() {
+	refreshToken := suite.newRefreshToken("f1")
+	user := userDummy
+	suite.tokenRepo.On("GetRefreshToken", refreshToken.TokenID).Return(&model.RefreshToken{
+		UserCredentialID: "u1", FamilyID: "f1", TokenID: refreshToken.TokenID,
+	}, nil)
+	suite.userRepo.On("Get", "u1").Return(&user, nil)
+	suite.employeeRepo.On("GetByUser", "u1").Return(&employeeDummy, nil)
+	suite.tokenRepo.On("RotateRefreshToken", refreshToken.TokenID, mock.AnythingOfType("*model.RefreshToken")).Return(nil)
+
+	pair, err := suite.useCase.RefreshToken(context.Background(), refreshToken.Token)
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), pair.AccessToken)
+	assert.NotEqual(suite.T(), refreshToken.Token, pair.RefreshToken)
+	next := suite.tokenRepo.Calls[1].Arguments.Get(1).(*model.RefreshToken)
+	assert.Equal(suite.T(), "f1", next.FamilyID)
+	suite.tokenRepo.On("IsTokenRevoked", mock.AnythingOfType("string")).Return(false, nil)
+	claims, err := suite.tokenService.VerifyAccessToken(context.Background(), pair.AccessToken)
+	assert.NoError(suite.T(), err)
+	principal := security.PrincipalFromClaims(claims)
+	assert.Equal(suite.T(), "u1", principal.UserID)
+	assert.Equal(suite.T(), "e1", principal.EmployeeID)
+	suite.tokenRepo.AssertNotCalled(suite.T(), "RevokeRefreshTokenFamily", mock.Anything)
+}
+
+func (suite *AuthUseCaseTestSuite) TestRefreshTokenReuseRevokesFamilyFail() {
+	refreshToken := suite.newRefreshToken("f1")
+	revokedAt := time.Now()
+	suite.tokenRepo.On("GetRefreshToken", refreshToken.TokenID).Return(&model.RefreshToken{
+		UserCredentialID: "u1", FamilyID: "f1", TokenID: refreshToken.TokenID, RevokedAt: &revokedAt,
+	}, nil)
+	suite.tokenRepo.On("RevokeRefreshTokenFamily", "f1").Return(nil)
+
+	_, err := suite.useCase.RefreshToken(context.Background(), refreshToken.Token)
+	assert.ErrorIs(suite.T(), err, model.ErrRefreshTokenReused)
+	suite.tokenRepo.AssertCalled(suite.T(), "RevokeRefreshTokenFamily", "f1")
+}
+
+func (suite *AuthUseCaseTestSuite) TestRefreshTokenWithAccessTokenFail() {
+	accessToken, err := suite.tokenService.CreateAccessToken(model.Principal{UserID: userDummy.ID})
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.useCase.RefreshToken(context.Background(), accessToken.Token)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *AuthUseCaseTestSuite) TestLogoutRevokesTokensSuccess() {
+	refreshToken := suite.newRefreshToken("f1")
+	suite.tokenRepo.On("RevokeToken", mock.AnythingOfType("*model.RevokedToken")).Return(nil)
+	suite.tokenRepo.On("RevokeRefreshTokenFamily", "f1").Return(nil)
+
+	principal := model.Principal{UserID: "u1", TokenID: "jti-1", ExpiresAt: time.Now().Add(time.Minute)}
+	err := suite.useCase.Logout(context.Background(), principal, refreshToken.Token)
+	assert.NoError(suite.T(), err)
+	revoked := suite.tokenRepo.Calls[0].Arguments.Get(0).(*model.RevokedToken)
+	assert.Equal(suite.T(), "jti-1", revoked.TokenID)
+	suite.tokenRepo.AssertCalled(suite.T(), "RevokeRefreshTokenFamily", "f1")
+}
+
+func (suite *AuthUseCaseTestSuite) TestVerifyRevokedAccessTokenFail() {
+	accessToken, err := suite.tokenService.CreateAccessToken(model.Principal{UserID: userDummy.ID})
+	assert.NoError(suite.T(), err)
+	suite.tokenRepo.On("IsTokenRevoked", mock.AnythingOfType("string")).Return(true, nil)
+
+	claims, err := suite.tokenService.VerifyAccessToken(context.Background(), accessToken.Token)
+	assert.Nil(suite.T(), claims)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *AuthUseCaseTestSuite) TestForgotPasswordSendsTokenSuccess() {
+	user := userDummy
+	suite.userRepo.On("GetByUsername", user.UserName).Return(&user, nil)
+	suite.resetRepo.On("Save", mock.AnythingOfType("*model.PasswordResetToken")).Return(nil)
+	suite.notifier.On("Send", mock.AnythingOfType("notification.Message")).Return(nil)
+
+	err := suite.useCase.ForgotPassword(context.Background(), user.UserName)
+	assert.NoError(suite.T(), err)
+	stored := suite.resetRepo.Calls[0].Arguments.Get(0).(*model.PasswordResetToken)
+	message := suite.notifier.Calls[0].Arguments.Get(0).(notification.Message)
+	assert.Equal(suite.T(), user.UserName, message.Recipient)
+	// only the hash is stored, the raw token goes to the user
+	assert.NotContains(suite.T(), message.Body, stored.TokenHash)
+}
+
+func (suite *AuthUseCaseTestSuite) TestForgotPasswordUnknownUserSuccess() {
+	suite.userRepo.On("GetByUsername", "nobody").Return(nil, errors.New(repositoryErrorMessage))
+
+	err := suite.useCase.ForgotPassword(context.Background(), "nobody")
+	assert.NoError(suite.T(), err)
+	suite.notifier.AssertNotCalled(suite.T(), "Send", mock.Anything)
+}
+
+func (suite *AuthUseCaseTestSuite) TestResetPasswordSuccess() {
+	user := userDummy
+	user.MustChangePassword = true
+	suite.resetRepo.On("GetByTokenHash", hashResetToken("reset-token")).Return(&model.PasswordResetToken{
+		BaseModel: model.BaseModel{ID: "r1"}, UserCredentialID: "u1", ExpiresAt: time.Now().Add(time.Minute),
+	}, nil)
+	suite.resetRepo.On("MarkUsed", "r1").Return(nil)
+	suite.userRepo.On("Get", "u1").Return(&user, nil)
+	suite.userRepo.On("Save", &user).Return(nil)
+	suite.tokenRepo.On("RevokeUserRefreshTokens", "u1").Return(nil)
+
+	err := suite.useCase.ResetPassword(context.Background(), dto.ResetPasswordRequest{Token: "reset-token", NewPassword: "new-password"})
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), user.MustChangePassword)
+}
+
+func (suite *AuthUseCaseTestSuite) TestResetPasswordSaveErrorFail() {
+	user := userDummy
+	suite.resetRepo.On("GetByTokenHash", hashResetToken("reset-token")).Return(&model.PasswordResetToken{
+		BaseModel: model.BaseModel{ID: "r1"}, UserCredentialID: "u1", ExpiresAt: time.Now().Add(time.Minute),
+	}, nil)
+	suite.resetRepo.On("MarkUsed", "r1").Return(nil)
+	suite.userRepo.On("Get", "u1").Return(&user, nil)
+	suite.userRepo.On("Save", &user).Return(errors.New(repositoryErrorMessage))
+
+	// the error leaves the transaction, which rolls the used mark back
+	err := suite.useCase.ResetPassword(context.Background(), dto.ResetPasswordRequest{Token: "reset-token", NewPassword: "new-password"})
+	assert.Error(suite.T(), err)
+	suite.tokenRepo.AssertNotCalled(suite.T(), "RevokeUserRefreshTokens", mock.Anything)
+}
+
+func (suite *AuthUseCaseTestSuite) TestResetPasswordExpiredTokenFail() {
+	suite.resetRepo.On("GetByTokenHash", hashResetToken("reset-token")).Return(&model.PasswordResetToken{
+		BaseModel: model.BaseModel{ID: "r1"}, UserCredentialID: "u1", ExpiresAt: time.Now().Add(-time.Minute),
+	}, nil)
+
+	err := suite.useCase.ResetPassword(context.Background(), dto.ResetPasswordRequest{Token: "reset-token", NewPassword: "new-password"})
+	assert.Error(suite.T(), err)
+	suite.resetRepo.AssertNotCalled(suite.T(), "MarkUsed", "r1")
+}
+
+func (suite *AuthUseCaseTestSuite) TestResetPasswordShortPasswordFail() {
+	err := suite.useCase.ResetPassword(context.Background(), dto.ResetPasswordRequest{Token: "reset-token", NewPassword: "short"})
+	assert.Error(suite.T(), err)
+	suite.resetRepo.AssertNotCalled(suite.T(), "GetByTokenHash", mock.Anything)
+}
+
+func (suite *AuthUseCaseTestSuite) TestCreateUserHashesPasswordSuccess() {
+	suite.userRepo.On("GetByUsername", "admin").Return(nil, apperror.NotFound("USER_NOT_FOUND", "user not found"))
+	suite.userRepo.On("Save", mock.AnythingOfType("*model.UserCredential")).Return(nil)
+
+	user := &model.UserCredential{UserName: "admin", Password: "first-password", Role: model.RoleAdmin}
+	err := suite.useCase.CreateUser(context.Background(), user)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.RoleAdmin, user.Role)
+	assert.True(suite.T(), utils.CheckPasswordHash("first-password", user.Password))
+}
+
+func (suite *AuthUseCaseTestSuite) TestCreateUserExistingUsernameFail() {
+	user := userDummy
+	suite.userRepo.On("GetByUsername", user.UserName).Return(&user, nil)
+
+	err := suite.useCase.CreateUser(context.Background(), &model.UserCredential{
+		UserName: user.UserName, Password: "first-password", Role: model.RoleSales,
+	})
+	assert.Error(suite.T(), err)
+	suite.userRepo.AssertNotCalled(suite.T(), "Save", mock.Anything)
+}
+
+func (suite *AuthUseCaseTestSuite) TestSetPasswordForcesChangeSuccess() {
+	user := userDummy
+	suite.userRepo.On("GetByUsername", user.UserName).Return(&user, nil)
+	suite.userRepo.On("Save", &user).Return(nil)
+	suite.userRepo.On("ResetLoginFailures", "u1").Return(nil)
+	suite.tokenRepo.On("RevokeUserRefreshTokens", "u1").Return(nil)
+	suite.securityRepo.On("SaveEvent", mock.AnythingOfType("*model.SecurityEvent")).Return(nil)
+
+	ctx := model.WithPrincipal(context.Background(), model.Principal{Username: "cli"})
+	err := suite.useCase.SetPassword(ctx, user.UserName, "temporary-password")
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), user.MustChangePassword)
+	assert.True(suite.T(), utils.CheckPasswordHash("temporary-password", user.Password))
+	event := suite.securityRepo.Calls[0].Arguments.Get(0).(*model.SecurityEvent)
+	assert.Equal(suite.T(), model.SecurityEventPasswordSet, event.Event)
+	assert.Equal(suite.T(), "cli", event.Actor)
+}
+
+func (suite *AuthUseCaseTestSuite) TestRegisterWithIDFail() {
+	err := suite.useCase.Register(context.Background(), &model.UserCredential{
+		BaseModel: model.BaseModel{ID: "u1"}, UserName: "intruder", Password: "intruder-password",
+	})
+	assert.Equal(suite.T(), apperror.KindValidation, apperror.KindOf(err))
+	suite.userRepo.AssertNotCalled(suite.T(), "Get", mock.Anything)
+	suite.userRepo.AssertNotCalled(suite.T(), "Save", mock.Anything)
+}
+
+func (suite *AuthUseCaseTestSuite) TestChangeRoleRevokesTokensSuccess() {
+	user := userDummy
+	suite.userRepo.On("GetByUsername", user.UserName).Return(&user, nil)
+	suite.userRepo.On("Save", &user).Return(nil)
+	suite.tokenRepo.On("RevokeUserAccessTokens", "u1").Return(nil)
+	suite.tokenRepo.On("RevokeUserRefreshTokens", "u1").Return(nil)
+
+	err := suite.useCase.ChangeRole(context.Background(), &model.UserCredential{UserName: user.UserName, Role: model.RoleManager})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), model.RoleManager, user.Role)
+	suite.tokenRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AuthUseCaseTestSuite) TestUpdateUsernameOwnAccountSuccess() {
+	user := suite.userWithPassword("secret-password")
+	password := user.Password
+	suite.userRepo.On("Get", "u1").Return(user, nil)
+	suite.userRepo.On("GetByUsername", "renamed").Return(nil, apperror.NotFound("USER_NOT_FOUND", "user not found"))
+	suite.userRepo.On("Save", user).Return(nil)
+
+	principal := model.Principal{UserID: "u1", Role: model.RoleSales}
+	err := suite.useCase.UpdateUsername(context.Background(), principal, "u1", dto.UpdateUsernameRequest{Username: "renamed"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "renamed", user.UserName)
+	assert.Equal(suite.T(), password, user.Password)
+	assert.Equal(suite.T(), model.RoleSales, user.Role)
+}
+
+func (suite *AuthUseCaseTestSuite) TestUpdateUsernameTakenFail() {
+	user := suite.userWithPassword("secret-password")
+	suite.userRepo.On("Get", "u1").Return(user, nil)
+	suite.userRepo.On("GetByUsername", "taken").Return(&model.UserCredential{UserName: "taken"}, nil)
+
+	principal := model.Principal{UserID: "u1", Role: model.RoleSales}
+	err := suite.useCase.UpdateUsername(context.Background(), principal, "u1", dto.UpdateUsernameRequest{Username: "taken"})
+	assert.Equal(suite.T(), apperror.KindConflict, apperror.KindOf(err))
+	suite.userRepo.AssertNotCalled(suite.T(), "Save", mock.Anything)
+}
+
+func (suite *AuthUseCaseTestSuite) TestUpdateUsernameOtherAccountFail() {
+	principal := model.Principal{UserID: "u2", Role: model.RoleCustomer}
+	err := suite.useCase.UpdateUsername(context.Background(), principal, "u1", dto.UpdateUsernameRequest{Username: "renamed"})
+	assert.Equal(suite.T(), apperror.KindForbidden, apperror.KindOf(err))
+	suite.userRepo.AssertNotCalled(suite.T(), "Save", mock.Anything)
+}
+
+func TestAuthUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthUseCaseTestSuite))
+}
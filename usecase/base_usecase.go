@@ -1,20 +1,24 @@
 package usecase
 
-import "github.com/fajritsaniy/golang-SHM/model/dto"
+import (
+	"context"
+
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+)
 
 type BaseUseCase[T any] interface {
-	SearchBy(by map[string]interface{}) ([]T, error)
-	FindAll() ([]T, error)
-	FindById(id string) (*T, error)
-	SaveData(payload *T) error
-	DeleteData(id string) error
+	SearchBy(ctx context.Context, by map[string]interface{}) ([]T, error)
+	FindAll(ctx context.Context) ([]T, error)
+	FindById(ctx context.Context, id string) (*T, error)
+	SaveData(ctx context.Context, payload *T) error
+	DeleteData(ctx context.Context, id string) error
 }
 
 type BaseUseCaseEmailPhone[T any] interface {
-	FindByEmail(email string) (*T, error)
-	FindByPhone(phone string) (*T, error)
+	FindByEmail(ctx context.Context, email string) (*T, error)
+	FindByPhone(ctx context.Context, phone string) (*T, error)
 }
 
 type BaseUseCasePaging[T any] interface {
-	Pagination(requestQueryParams dto.RequestQueryParams) ([]T, dto.Paging, error)
+	Pagination(ctx context.Context, requestQueryParams dto.RequestQueryParams) ([]T, dto.Paging, error)
 }
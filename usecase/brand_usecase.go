@@ -1,90 +1,94 @@
 package usecase
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/fajritsaniy/golang-SHM/model/dto"
 
 	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
 	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/fajritsaniy/golang-SHM/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type BrandUseCase interface {
 	BaseUseCase[model.Brand]
 	BaseUseCasePaging[model.Brand]
-	IsNameExists(name string, id string) (bool, error)
+	IsNameExists(ctx context.Context, name string, id string) (bool, error)
 }
 
 type brandUseCase struct {
 	repo repository.BrandRepository
 }
 
-func BrandNotFoundMessage(id string) string {
-	return fmt.Sprintf("brand with ID %s not found", id)
-}
+const codeBrandNotFound = "BRAND_NOT_FOUND"
 
-func (b *brandUseCase) DeleteData(id string) error {
-	brand, err := b.FindById(id)
+func (b *brandUseCase) DeleteData(ctx context.Context, id string) error {
+	brand, err := b.FindById(ctx, id)
 	if err != nil {
-		return fmt.Errorf(BrandNotFoundMessage(id))
+		return err
 	}
-	return b.repo.Delete(brand.ID)
+	return b.repo.Delete(ctx, brand.ID)
 }
 
-func (b *brandUseCase) FindAll() ([]model.Brand, error) {
-	return b.repo.List()
+func (b *brandUseCase) FindAll(ctx context.Context) ([]model.Brand, error) {
+	return b.repo.List(ctx)
 }
 
-func (b *brandUseCase) FindById(id string) (*model.Brand, error) {
-	brand, err := b.repo.Get(id)
+func (b *brandUseCase) FindById(ctx context.Context, id string) (*model.Brand, error) {
+	ctx, span := tracing.Start(ctx, "BrandUseCase.FindById", attribute.String("brand.id", id))
+	defer span.End()
+
+	brand, err := b.repo.Get(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf(BrandNotFoundMessage(id))
+		return nil, notFound(err, codeBrandNotFound, "brand.not_found", i18n.Args{"id": id})
 	}
 	return brand, nil
 }
 
-func (b *brandUseCase) SaveData(payload *model.Brand) error {
+func (b *brandUseCase) SaveData(ctx context.Context, payload *model.Brand) error {
 	err := payload.Validate()
 	if err != nil {
-		return err
+		return apperror.FromValidation(err)
 	}
 
-	_, err = b.IsNameExists(payload.Name, payload.ID)
+	_, err = b.IsNameExists(ctx, payload.Name, payload.ID)
 	if err != nil {
 		return err
 	}
 
 	if payload.ID != "" {
-		fmt.Println("here")
-		_, err := b.FindById(payload.ID)
+		_, err := b.FindById(ctx, payload.ID)
 		if err != nil {
-			return fmt.Errorf(BrandNotFoundMessage(payload.ID))
+			return err
 		}
 	}
-	return b.repo.Save(payload)
+	return b.repo.Save(ctx, payload)
 }
 
-func (b *brandUseCase) SearchBy(by map[string]interface{}) ([]model.Brand, error) {
-	brands, err := b.repo.Search(by)
+func (b *brandUseCase) SearchBy(ctx context.Context, by map[string]interface{}) ([]model.Brand, error) {
+	brands, err := b.repo.Search(ctx, by)
 	if err != nil {
-		return nil, fmt.Errorf("data not found")
+		return nil, err
 	}
 	return brands, nil
 }
 
-func (b *brandUseCase) IsNameExists(name string, id string) (bool, error) {
-	count, _ := b.repo.CountByName(name, id)
+func (b *brandUseCase) IsNameExists(ctx context.Context, name string, id string) (bool, error) {
+	count, _ := b.repo.CountByName(ctx, name, id)
 	if count > 0 {
-		return true, fmt.Errorf("brand with name %s already exists", name)
+		return true, apperror.Conflict("BRAND_NAME_TAKEN", "brand.name_taken").With(i18n.Args{"name": name})
 	}
 	return false, nil
 }
 
-func (b *brandUseCase) Pagination(requestQueryParams dto.RequestQueryParams) ([]model.Brand, dto.Paging, error) {
+func (b *brandUseCase) Pagination(ctx context.Context, requestQueryParams dto.RequestQueryParams) ([]model.Brand, dto.Paging, error) {
 	if !requestQueryParams.QueryParams.IsSortValid() {
-		return nil, dto.Paging{}, fmt.Errorf("invalid sort by: %s", requestQueryParams.QueryParams.Sort)
+		return nil, dto.Paging{}, invalidField("sort", "query.invalid_sort", i18n.Args{"sort": requestQueryParams.QueryParams.Sort})
 	}
-	return b.repo.Paging(requestQueryParams)
+	return b.repo.Paging(ctx, requestQueryParams)
 }
 
 func NewBrandUseCase(repo repository.BrandRepository) BrandUseCase {
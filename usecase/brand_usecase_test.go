@@ -1,10 +1,12 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
 	"github.com/fajritsaniy/golang-SHM/model/dto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -35,12 +37,12 @@ type repoMock struct {
 }
 
 // Setup all repository here (mock)
-func (r *repoMock) Delete(id string) error {
+func (r *repoMock) Delete(ctx context.Context, id string) error {
 	ret := r.Called(id)
 	return ret.Error(0)
 }
 
-func (r *repoMock) Get(id string) (*model.Brand, error) {
+func (r *repoMock) Get(ctx context.Context, id string) (*model.Brand, error) {
 	args := r.Called(id)
 	if args.Get(1) != nil {
 		return nil, args.Error(1)
@@ -48,7 +50,7 @@ func (r *repoMock) Get(id string) (*model.Brand, error) {
 	return args.Get(0).(*model.Brand), nil
 }
 
-func (r *repoMock) List() ([]model.Brand, error) {
+func (r *repoMock) List(ctx context.Context) ([]model.Brand, error) {
 	args := r.Called()
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -56,12 +58,12 @@ func (r *repoMock) List() ([]model.Brand, error) {
 	return args.Get(0).([]model.Brand), nil
 }
 
-func (r *repoMock) Save(payload *model.Brand) error {
+func (r *repoMock) Save(ctx context.Context, payload *model.Brand) error {
 	ret := r.Called(payload)
 	return ret.Error(0)
 }
 
-func (r *repoMock) Search(by map[string]interface{}) ([]model.Brand, error) {
+func (r *repoMock) Search(ctx context.Context, by map[string]interface{}) ([]model.Brand, error) {
 	args := r.Called(by)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -69,7 +71,7 @@ func (r *repoMock) Search(by map[string]interface{}) ([]model.Brand, error) {
 	return args.Get(0).([]model.Brand), nil
 }
 
-func (r *repoMock) CountByName(name string, id string) (int64, error) {
+func (r *repoMock) CountByName(ctx context.Context, name string, id string) (int64, error) {
 	args := r.Called(name, id)
 	if args.Get(0) == nil {
 		return 0, args.Error(1)
@@ -77,7 +79,7 @@ func (r *repoMock) CountByName(name string, id string) (int64, error) {
 	return args.Get(0).(int64), nil
 }
 
-func (b *repoMock) Paging(requestQueryParams dto.RequestQueryParams) ([]model.Brand, dto.Paging, error) {
+func (b *repoMock) Paging(ctx context.Context, requestQueryParams dto.RequestQueryParams) ([]model.Brand, dto.Paging, error) {
 	args := b.Called(requestQueryParams)
 	return args.Get(0).([]model.Brand), args.Get(1).(dto.Paging), args.Error(2)
 }
@@ -86,7 +88,7 @@ func (suite *BrandUseCaseTestSuite) TestIsNameExistsSuccess() {
 	var countBrand int64 = 0
 	suite.repoMock.On("CountByName", "Honda", "1").Return(countBrand, nil)
 	useCase := NewBrandUseCase(suite.repoMock)
-	count, err := useCase.IsNameExists("Honda", "1")
+	count, err := useCase.IsNameExists(context.Background(), "Honda", "1")
 	assert.Equal(suite.T(), false, count)
 	assert.Nil(suite.T(), err)
 }
@@ -95,7 +97,7 @@ func (suite *BrandUseCaseTestSuite) TestIsNameExistsRepoErrorFail() {
 	var countBrand int64 = 1
 	suite.repoMock.On("CountByName", "Honda", "1").Return(countBrand, errors.New(repositoryErrorMessage))
 	useCase := NewBrandUseCase(suite.repoMock)
-	count, err := useCase.IsNameExists("Honda", "1")
+	count, err := useCase.IsNameExists(context.Background(), "Honda", "1")
 	assert.Equal(suite.T(), true, count)
 	assert.Error(suite.T(), err)
 }
@@ -103,7 +105,7 @@ func (suite *BrandUseCaseTestSuite) TestIsNameExistsRepoErrorFail() {
 func (suite *BrandUseCaseTestSuite) TestFindAllSuccess() {
 	suite.repoMock.On("List").Return(brandDummies, nil)
 	useCase := NewBrandUseCase(suite.repoMock)
-	brands, err := useCase.FindAll()
+	brands, err := useCase.FindAll(context.Background())
 	assert.Equal(suite.T(), brandDummies, brands)
 	assert.Nil(suite.T(), err)
 }
@@ -111,7 +113,7 @@ func (suite *BrandUseCaseTestSuite) TestFindAllSuccess() {
 func (suite *BrandUseCaseTestSuite) TestFindAllRepoErrorFail() {
 	suite.repoMock.On("List").Return(nil, errors.New(repositoryErrorMessage))
 	useCase := NewBrandUseCase(suite.repoMock)
-	list, err := useCase.FindAll()
+	list, err := useCase.FindAll(context.Background())
 	assert.Nil(suite.T(), list)
 	assert.Error(suite.T(), err)
 }
@@ -120,7 +122,7 @@ func (suite *BrandUseCaseTestSuite) TestDeleteBrandSuccess() {
 	suite.repoMock.On("Get", "1").Return(&brandDummies[0], nil)
 	suite.repoMock.On("Delete", "1").Return(nil)
 	useCase := NewBrandUseCase(suite.repoMock)
-	err := useCase.DeleteData("1")
+	err := useCase.DeleteData(context.Background(), "1")
 	assert.Nil(suite.T(), err)
 }
 
@@ -128,14 +130,14 @@ func (suite *BrandUseCaseTestSuite) TestDeleteBrandRepoErrorFail() {
 	suite.repoMock.On("Get", "1").Return(nil, errors.New(repositoryErrorMessage))
 	suite.repoMock.On("Delete", "1").Return(errors.New(repositoryErrorMessage))
 	useCase := NewBrandUseCase(suite.repoMock)
-	err := useCase.DeleteData("1")
+	err := useCase.DeleteData(context.Background(), "1")
 	assert.Error(suite.T(), err)
 }
 
 func (suite *BrandUseCaseTestSuite) TestFindByIdSuccess() {
 	suite.repoMock.On("Get", "1").Return(&brandDummies[0], nil)
 	useCase := NewBrandUseCase(suite.repoMock)
-	brand, err := useCase.FindById("1")
+	brand, err := useCase.FindById(context.Background(), "1")
 	assert.Equal(suite.T(), brandDummies[0], *brand)
 	assert.Nil(suite.T(), err)
 }
@@ -143,7 +145,7 @@ func (suite *BrandUseCaseTestSuite) TestFindByIdSuccess() {
 func (suite *BrandUseCaseTestSuite) TestFindByIdRepoErrorFail() {
 	suite.repoMock.On("Get", "1").Return(nil, errors.New(repositoryErrorMessage))
 	useCase := NewBrandUseCase(suite.repoMock)
-	brand, err := useCase.FindById("1")
+	brand, err := useCase.FindById(context.Background(), "1")
 	assert.Nil(suite.T(), brand)
 	assert.Error(suite.T(), err)
 }
@@ -152,7 +154,7 @@ func (suite *BrandUseCaseTestSuite) TestSearchBySuccess() {
 	filter := map[string]interface{}{"brand": "Honda"}
 	suite.repoMock.On("Search", filter).Return(brandDummies, nil)
 	useCase := NewBrandUseCase(suite.repoMock)
-	brands, err := useCase.SearchBy(filter)
+	brands, err := useCase.SearchBy(context.Background(), filter)
 	assert.Equal(suite.T(), brandDummies, brands)
 	assert.Nil(suite.T(), err)
 }
@@ -161,7 +163,7 @@ func (suite *BrandUseCaseTestSuite) TestSearchByRepoErrorFail() {
 	filter := map[string]interface{}{"brand": "Honda"}
 	suite.repoMock.On("Search", filter).Return(nil, errors.New(repositoryErrorMessage))
 	useCase := NewBrandUseCase(suite.repoMock)
-	brands, err := useCase.SearchBy(filter)
+	brands, err := useCase.SearchBy(context.Background(), filter)
 	assert.Nil(suite.T(), brands)
 	assert.Error(suite.T(), err)
 }
@@ -173,7 +175,7 @@ func (suite *BrandUseCaseTestSuite) TestSaveDataSuccess() {
 	suite.repoMock.On("Get", "1").Return(&brandDummies[0], nil)
 	suite.repoMock.On("Save", &dummy).Return(nil)
 	useCase := NewBrandUseCase(suite.repoMock)
-	err := useCase.SaveData(&dummy)
+	err := useCase.SaveData(context.Background(), &dummy)
 	assert.Nil(suite.T(), err)
 }
 
@@ -186,10 +188,10 @@ func (suite *BrandUseCaseTestSuite) TestSaveDataRepoErrorFail() {
 	err := dummy.Validate()
 	assert.Error(suite.T(), err)
 	dummy.Name = ""
-	err = useCase.SaveData(&dummy)
+	err = useCase.SaveData(context.Background(), &dummy)
 	assert.Error(suite.T(), err)
 	dummy = brandDummies[0]
-	err = useCase.SaveData(&dummy)
+	err = useCase.SaveData(context.Background(), &dummy)
 	assert.Error(suite.T(), err)
 }
 
@@ -197,9 +199,9 @@ func (suite *BrandUseCaseTestSuite) TestSaveDataIDNotFoundFail() {
 	dummy := brandDummies[0]
 	var countBrand int64 = 0
 	suite.repoMock.On("CountByName", "Honda", "1").Return(countBrand, nil)
-	suite.repoMock.On("Get", "1").Return(nil, errors.New("not found"))
+	suite.repoMock.On("Get", "1").Return(nil, apperror.NotFound("BRAND_NOT_FOUND", "brand not found"))
 	useCase := NewBrandUseCase(suite.repoMock)
-	err := useCase.SaveData(&dummy)
+	err := useCase.SaveData(context.Background(), &dummy)
 	assert.Error(suite.T(), err)
 	assert.Equal(suite.T(), "brand with ID 1 not found", err.Error())
 }
@@ -215,7 +217,7 @@ func (suite *BrandUseCaseTestSuite) TestPaginationSuccess() {
 	suite.repoMock.On("Paging", mock.AnythingOfType("dto.RequestQueryParams")).Return(brandDm, expectedPaging, nil)
 	useCase := NewBrandUseCase(suite.repoMock)
 	requestParams := dto.RequestQueryParams{QueryParams: dto.QueryParams{Sort: "ASC"}}
-	actualBrand, actualPaging, actualError := useCase.Pagination(requestParams)
+	actualBrand, actualPaging, actualError := useCase.Pagination(context.Background(), requestParams)
 	assert.Equal(suite.T(), brandDm, actualBrand)
 	assert.Equal(suite.T(), expectedPaging, actualPaging)
 	assert.Equal(suite.T(), nil, actualError)
@@ -231,7 +233,7 @@ func (suite *BrandUseCaseTestSuite) TestPaginationFail() {
 	suite.repoMock.On("Paging", mock.AnythingOfType("dto.RequestQueryParams")).Return(nil, expectedPaging, errors.New(repositoryErrorMessage))
 	useCase := NewBrandUseCase(suite.repoMock)
 	requestParams := dto.RequestQueryParams{QueryParams: dto.QueryParams{Sort: "ABC"}}
-	_, actualPaging, actualError := useCase.Pagination(requestParams)
+	_, actualPaging, actualError := useCase.Pagination(context.Background(), requestParams)
 	assert.Equal(suite.T(), expectedPaging, actualPaging)
 	assert.Error(suite.T(), actualError)
 	assert.Equal(suite.T(), "invalid sort by: ABC", actualError.Error())
@@ -1,95 +1,127 @@
 package usecase
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
 	"github.com/fajritsaniy/golang-SHM/repository"
 	"github.com/fajritsaniy/golang-SHM/utils"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/fajritsaniy/golang-SHM/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type CustomerUseCase interface {
 	BaseUseCase[model.Customer]
 	BaseUseCaseEmailPhone[model.Customer]
-	AppendCustomerVehicle(payload *model.Customer, association any) error
+	AppendCustomerVehicle(ctx context.Context, payload *model.Customer, association any) error
+	FindByPrincipal(ctx context.Context, principal model.Principal) (*model.Customer, error)
 }
 
 type customerUseCase struct {
 	repo repository.CustomerRepository
 }
 
-func CustomerNotFoundMessage(id string) string {
-	return fmt.Sprintf("customers with ID %s not found", id)
-}
+const codeCustomerNotFound = "CUSTOMER_NOT_FOUND"
 
-func (c *customerUseCase) DeleteData(id string) error {
-	customer, err := c.FindById(id)
+func (c *customerUseCase) DeleteData(ctx context.Context, id string) error {
+	customer, err := c.FindById(ctx, id)
 	if err != nil {
-		return fmt.Errorf(CustomerNotFoundMessage(id))
+		return err
 	}
-	return c.repo.Delete(customer.ID)
+	return c.repo.Delete(ctx, customer.ID)
 }
 
-func (c *customerUseCase) FindAll() ([]model.Customer, error) {
-	return c.repo.List()
+func (c *customerUseCase) FindAll(ctx context.Context) ([]model.Customer, error) {
+	return c.repo.List(ctx)
 }
 
-func (c *customerUseCase) FindById(id string) (*model.Customer, error) {
-	customer, err := c.repo.Get(id)
+func (c *customerUseCase) FindById(ctx context.Context, id string) (*model.Customer, error) {
+	ctx, span := tracing.Start(ctx, "CustomerUseCase.FindById", attribute.String("customer.id", id))
+	defer span.End()
+
+	customer, err := c.repo.Get(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf(CustomerNotFoundMessage(id))
+		return nil, notFound(err, codeCustomerNotFound, "customer.not_found", i18n.Args{"id": id})
 	}
 	return customer, nil
 }
 
-func (c *customerUseCase) SaveData(payload *model.Customer) error {
+func (c *customerUseCase) SaveData(ctx context.Context, payload *model.Customer) error {
+	if err := payload.Validate(); err != nil {
+		return apperror.FromValidation(err)
+	}
+
 	if payload.ID != "" {
-		_, err := c.FindById(payload.ID)
+		existing, err := c.FindById(ctx, payload.ID)
 		if err != nil {
-			return fmt.Errorf(CustomerNotFoundMessage(payload.ID))
+			return err
 		}
+		// the login stays as it is, a zero credential is not saved
+		payload.UserCredentialID, payload.UserCredential = existing.UserCredentialID, model.UserCredential{}
+		return c.repo.Save(ctx, payload)
 	}
 
-	// create user credential (recommended use transactional)
-	password, err := utils.HashPassword("password")
+	// create user credential (recommended use transactional). Nobody knows
+	// the initial password, the user sets one with a password reset.
+	initial, err := utils.RandomPassword()
+	if err != nil {
+		return err
+	}
+	password, err := utils.HashPassword(initial)
 	if err != nil {
 		return err
 	}
 	userCredential := model.UserCredential{
-		UserName: payload.Email,
-		Password: password,
-		IsActive: false,
+		UserName:           payload.Email,
+		Password:           password,
+		Role:               model.RoleCustomer,
+		IsActive:           false,
+		MustChangePassword: true,
 	}
 	payload.UserCredential = userCredential
-	return c.repo.Save(payload)
+	return c.repo.Save(ctx, payload)
 }
 
-func (c *customerUseCase) SearchBy(by map[string]interface{}) ([]model.Customer, error) {
-	customers, err := c.repo.Search(by)
+func (c *customerUseCase) SearchBy(ctx context.Context, by map[string]interface{}) ([]model.Customer, error) {
+	customers, err := c.repo.Search(ctx, by)
 	if err != nil {
-		return nil, fmt.Errorf("data not found")
+		return nil, err
 	}
 	return customers, nil
 }
 
-func (c *customerUseCase) FindByEmail(email string) (*model.Customer, error) {
-	customer, err := c.repo.GetByEmail(email)
+func (c *customerUseCase) FindByEmail(ctx context.Context, email string) (*model.Customer, error) {
+	customer, err := c.repo.GetByEmail(ctx, email)
 	if err != nil {
-		return nil, fmt.Errorf("customer with email %s not found", email)
+		return nil, notFound(err, codeCustomerNotFound, "customer.email_not_found", i18n.Args{"email": email})
 	}
 	return customer, nil
 }
 
-func (c *customerUseCase) FindByPhone(phone string) (*model.Customer, error) {
-	customer, err := c.repo.GetByPhone(phone)
+func (c *customerUseCase) FindByPhone(ctx context.Context, phone string) (*model.Customer, error) {
+	customer, err := c.repo.GetByPhone(ctx, phone)
 	if err != nil {
-		return nil, fmt.Errorf("customer with phone number %s not found", phone)
+		return nil, notFound(err, codeCustomerNotFound, "customer.phone_not_found", i18n.Args{"phone": phone})
 	}
 	return customer, nil
 }
 
-func (c *customerUseCase) AppendCustomerVehicle(payload *model.Customer, association any) error {
-	return c.repo.CreateCustomerVehicle(payload, association)
+func (c *customerUseCase) AppendCustomerVehicle(ctx context.Context, payload *model.Customer, association any) (err error) {
+	ctx, span := tracing.Start(ctx, "CustomerUseCase.AppendCustomerVehicle", attribute.String("customer.id", payload.ID))
+	defer func() { tracing.End(span, err) }()
+
+	return c.repo.CreateCustomerVehicle(ctx, payload, association)
+}
+
+func (c *customerUseCase) FindByPrincipal(ctx context.Context, principal model.Principal) (*model.Customer, error) {
+	customer, err := c.repo.GetByUser(ctx, principal.UserID)
+	if err != nil {
+		return nil, notFound(err, "CUSTOMER_PROFILE_NOT_FOUND", "customer.profile_not_found", i18n.Args{"username": principal.Username})
+	}
+	customer.UserCredential.Password = ""
+	return customer, nil
 }
 
 func NewCustomerUseCase(repo repository.CustomerRepository) CustomerUseCase {
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type customerRepoMock struct {
+	mock.Mock
+	repository.CustomerRepository
+}
+
+func (c *customerRepoMock) Get(ctx context.Context, id string) (*model.Customer, error) {
+	args := c.Called(id)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), nil
+}
+
+func (c *customerRepoMock) Save(ctx context.Context, payload *model.Customer) error {
+	return c.Called(payload).Error(0)
+}
+
+type CustomerUseCaseTestSuite struct {
+	suite.Suite
+	repo     *customerRepoMock
+	useCase  CustomerUseCase
+	customer model.Customer
+}
+
+func (suite *CustomerUseCaseTestSuite) SetupTest() {
+	suite.repo = new(customerRepoMock)
+	suite.useCase = NewCustomerUseCase(suite.repo)
+	suite.customer = model.Customer{
+		FirstName:   "Budi",
+		LastName:    "Santoso",
+		Email:       "budi@example.com",
+		PhoneNumber: "081234567890",
+		Bod:         time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (suite *CustomerUseCaseTestSuite) TestSaveDataCreateSuccess() {
+	suite.repo.On("Save", &suite.customer).Return(nil)
+
+	assert.NoError(suite.T(), suite.useCase.SaveData(context.Background(), &suite.customer))
+	assert.Equal(suite.T(), "budi@example.com", suite.customer.UserCredential.UserName)
+	assert.Equal(suite.T(), model.RoleCustomer, suite.customer.UserCredential.Role)
+	assert.NotEmpty(suite.T(), suite.customer.UserCredential.Password)
+}
+
+func (suite *CustomerUseCaseTestSuite) TestSaveDataUpdateKeepsLoginSuccess() {
+	suite.repo.On("Get", "c1").Return(&model.Customer{BaseModel: model.BaseModel{ID: "c1"}, UserCredentialID: "u1"}, nil)
+	suite.repo.On("Save", &suite.customer).Return(nil)
+
+	suite.customer.ID = "c1"
+	suite.customer.UserCredential = model.UserCredential{UserName: "intruder", Role: model.RoleAdmin}
+	assert.NoError(suite.T(), suite.useCase.SaveData(context.Background(), &suite.customer))
+	assert.Equal(suite.T(), "u1", suite.customer.UserCredentialID)
+	// a zero credential is skipped on save, no second login is inserted
+	assert.Equal(suite.T(), model.UserCredential{}, suite.customer.UserCredential)
+}
+
+func TestCustomerUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(CustomerUseCaseTestSuite))
+}
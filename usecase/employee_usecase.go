@@ -1,111 +1,155 @@
 package usecase
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/fajritsaniy/golang-SHM/utils"
 
 	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
 	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/fajritsaniy/golang-SHM/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type EmployeeUseCase interface {
 	BaseUseCase[model.Employee]
 	BaseUseCaseEmailPhone[model.Employee]
-	FindAllEmployeeByManager(managerId string) ([]model.Employee, error)
+	FindAllEmployeeByManager(ctx context.Context, managerId string) ([]model.Employee, error)
+	FindByPrincipal(ctx context.Context, principal model.Principal) (*model.Employee, error)
 }
 
 type employeeUseCase struct {
 	repo repository.EmployeeRepository
 }
 
-func employeeIDNotFoundMessage(id string) string {
-	return fmt.Sprintf("employee with ID %s not found", id)
-}
+const codeEmployeeNotFound = "EMPLOYEE_NOT_FOUND"
 
-func (e *employeeUseCase) DeleteData(id string) error {
-	employee, err := e.FindById(id)
+func (e *employeeUseCase) DeleteData(ctx context.Context, id string) error {
+	employee, err := e.FindById(ctx, id)
 	if err != nil {
-		return fmt.Errorf(employeeIDNotFoundMessage(id))
+		return err
 	}
-	return e.repo.Delete(employee.ID)
+	return e.repo.Delete(ctx, employee.ID)
 }
 
-func (e *employeeUseCase) FindAll() ([]model.Employee, error) {
-	return e.repo.List()
+func (e *employeeUseCase) FindAll(ctx context.Context) ([]model.Employee, error) {
+	return e.repo.List(ctx)
 }
 
-func (e *employeeUseCase) FindById(id string) (*model.Employee, error) {
-	employee, err := e.repo.Get(id)
+func (e *employeeUseCase) FindById(ctx context.Context, id string) (*model.Employee, error) {
+	ctx, span := tracing.Start(ctx, "EmployeeUseCase.FindById", attribute.String("employee.id", id))
+	defer span.End()
+
+	employee, err := e.repo.Get(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf(employeeIDNotFoundMessage(id))
+		return nil, notFound(err, codeEmployeeNotFound, "employee.not_found", i18n.Args{"id": id})
 	}
 	return employee, nil
 }
 
-func (e *employeeUseCase) SaveData(payload *model.Employee) error {
+func (e *employeeUseCase) SaveData(ctx context.Context, payload *model.Employee) error {
+	if err := payload.Validate(); err != nil {
+		return apperror.FromValidation(err)
+	}
+
+	var existing *model.Employee
 	if payload.ID != "" {
-		_, err := e.FindById(payload.ID)
+		var err error
+		existing, err = e.FindById(ctx, payload.ID)
 		if err != nil {
-			return fmt.Errorf(employeeIDNotFoundMessage(payload.ID))
+			return err
 		}
 	}
 
-	isEmailExist, _ := e.FindByEmail(payload.Email)
-	if isEmailExist != nil && isEmailExist.Email == payload.Email {
-		return fmt.Errorf("employee with email: %v exists", payload.Email)
+	isEmailExist, _ := e.FindByEmail(ctx, payload.Email)
+	if isEmailExist != nil && isEmailExist.Email == payload.Email && isEmailExist.ID != payload.ID {
+		return apperror.Conflict("EMPLOYEE_EMAIL_TAKEN", "employee.email_taken").With(i18n.Args{"email": payload.Email})
 	}
 
-	isPhoneNumberExist, _ := e.FindByPhone(payload.PhoneNumber)
-	if isPhoneNumberExist != nil && isPhoneNumberExist.PhoneNumber == payload.PhoneNumber {
-		return fmt.Errorf("employee with phone number: %v exists", payload.PhoneNumber)
+	isPhoneNumberExist, _ := e.FindByPhone(ctx, payload.PhoneNumber)
+	if isPhoneNumberExist != nil && isPhoneNumberExist.PhoneNumber == payload.PhoneNumber && isPhoneNumberExist.ID != payload.ID {
+		return apperror.Conflict("EMPLOYEE_PHONE_TAKEN", "employee.phone_taken").With(i18n.Args{"phone": payload.PhoneNumber})
 	}
 
 	if payload.ManagerID != nil {
-		manager, _ := e.FindById(*payload.ManagerID)
+		manager, _ := e.FindById(ctx, *payload.ManagerID)
 		payload.Manager = manager
 	}
 
-	// create user credential (recommended use transactional)
-	password, err := utils.HashPassword("password")
+	if existing != nil {
+		// the login stays as it is, a zero credential is not saved and roles
+		// change through ChangeRole
+		payload.UserCredentialID, payload.UserCredential = existing.UserCredentialID, model.UserCredential{}
+		return e.repo.Save(ctx, payload)
+	}
+
+	// employees sign in as sales unless a staff role is given explicitly
+	role := payload.UserCredential.Role
+	if role == "" {
+		role = model.RoleSales
+	}
+	if role != model.RoleAdmin && role != model.RoleManager && role != model.RoleSales {
+		return invalidField("role", "employee.invalid_role", i18n.Args{"role": role})
+	}
+
+	// create user credential (recommended use transactional). Nobody knows
+	// the initial password, the user sets one with a password reset.
+	initial, err := utils.RandomPassword()
+	if err != nil {
+		return err
+	}
+	password, err := utils.HashPassword(initial)
 	if err != nil {
 		return err
 	}
 	userCredential := model.UserCredential{
-		UserName: payload.Email,
-		Password: password,
-		IsActive: false,
+		UserName:           payload.Email,
+		Password:           password,
+		Role:               role,
+		IsActive:           false,
+		MustChangePassword: true,
 	}
 	payload.UserCredential = userCredential
-	return e.repo.Save(payload)
+	return e.repo.Save(ctx, payload)
 }
 
-func (e *employeeUseCase) SearchBy(by map[string]interface{}) ([]model.Employee, error) {
-	employees, err := e.repo.Search(by)
+func (e *employeeUseCase) SearchBy(ctx context.Context, by map[string]interface{}) ([]model.Employee, error) {
+	employees, err := e.repo.Search(ctx, by)
 	if err != nil {
-		return nil, fmt.Errorf("data not found")
+		return nil, err
 	}
 	return employees, nil
 }
 
-func (e *employeeUseCase) FindByEmail(email string) (*model.Employee, error) {
-	employee, err := e.repo.GetByEmail(email)
+func (e *employeeUseCase) FindByEmail(ctx context.Context, email string) (*model.Employee, error) {
+	employee, err := e.repo.GetByEmail(ctx, email)
 	if err != nil {
-		return nil, fmt.Errorf("employee with email %s not found", email)
+		return nil, notFound(err, codeEmployeeNotFound, "employee.email_not_found", i18n.Args{"email": email})
 	}
 	return employee, nil
 }
 
-func (e *employeeUseCase) FindByPhone(phone string) (*model.Employee, error) {
-	employee, err := e.repo.GetByPhone(phone)
+func (e *employeeUseCase) FindByPhone(ctx context.Context, phone string) (*model.Employee, error) {
+	employee, err := e.repo.GetByPhone(ctx, phone)
 	if err != nil {
-		return nil, fmt.Errorf("employee with phone number %s not found", phone)
+		return nil, notFound(err, codeEmployeeNotFound, "employee.phone_not_found", i18n.Args{"phone": phone})
 	}
 	return employee, nil
 }
 
-func (e *employeeUseCase) FindAllEmployeeByManager(managerId string) ([]model.Employee, error) {
-	return e.repo.ListEmployeeByManager(managerId)
+func (e *employeeUseCase) FindAllEmployeeByManager(ctx context.Context, managerId string) ([]model.Employee, error) {
+	return e.repo.ListEmployeeByManager(ctx, managerId)
+}
+
+func (e *employeeUseCase) FindByPrincipal(ctx context.Context, principal model.Principal) (*model.Employee, error) {
+	employee, err := e.repo.GetByUser(ctx, principal.UserID)
+	if err != nil {
+		return nil, notFound(err, "EMPLOYEE_PROFILE_NOT_FOUND", "employee.profile_not_found", i18n.Args{"username": principal.Username})
+	}
+	employee.UserCredential.Password = ""
+	return employee, nil
 }
 
 func NewEmployeeUseCase(repo repository.EmployeeRepository) EmployeeUseCase {
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+)
+
+// notFound replaces the generic not-found error of a repository with one that
+// names what was looked up. Other errors are passed on unchanged.
+func notFound(err error, code string, message string, args i18n.Args) error {
+	if apperror.KindOf(err) == apperror.KindNotFound {
+		return apperror.NotFound(code, message).With(args).Wrap(err)
+	}
+	return err
+}
+
+// invalidField is a validation error about a single field.
+func invalidField(field string, message string, args i18n.Args) error {
+	return apperror.Validation(apperror.CodeValidation, message, map[string]string{field: message}).With(args)
+}
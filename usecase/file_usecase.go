@@ -1,21 +1,33 @@
 package usecase
 
 import (
-	"mime/multipart"
+	"context"
+	"io"
 
 	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/fajritsaniy/golang-SHM/utils/storage"
 )
 
 type FileUseCase interface {
-	Save(file multipart.File, fileName string) (string, error)
+	Save(ctx context.Context, key string, body io.ReadSeeker, contentType string) error
+	Open(ctx context.Context, key string) (*storage.Object, error)
+	Delete(ctx context.Context, key string) error
 }
 
 type fileUseCase struct {
 	repo repository.FileRepository
 }
 
-func (f *fileUseCase) Save(file multipart.File, fileName string) (string, error) {
-	return f.repo.Save(file, fileName)
+func (f *fileUseCase) Save(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	return f.repo.Save(ctx, key, body, contentType)
+}
+
+func (f *fileUseCase) Open(ctx context.Context, key string) (*storage.Object, error) {
+	return f.repo.Open(ctx, key)
+}
+
+func (f *fileUseCase) Delete(ctx context.Context, key string) error {
+	return f.repo.Delete(ctx, key)
 }
 
 func NewFileUseCase(repo repository.FileRepository) FileUseCase {
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+)
+
+// readinessTimeout bounds every readiness check so a hung dependency does not
+// hang the probe.
+const readinessTimeout = 2 * time.Second
+
+// HealthCheck is a dependency that has to work before the API takes traffic.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type HealthUseCase interface {
+	Readiness(ctx context.Context) dto.HealthReport
+	// Drain makes every later readiness check fail, so load balancers stop
+	// sending traffic while the server shuts down.
+	Drain()
+}
+
+type healthUseCase struct {
+	checks   []HealthCheck
+	draining atomic.Bool
+}
+
+func (h *healthUseCase) Readiness(ctx context.Context) dto.HealthReport {
+	if h.draining.Load() {
+		return dto.HealthReport{Status: dto.HealthStatusDown, Checks: map[string]string{"server": "shutting down"}}
+	}
+
+	report := dto.HealthReport{Status: dto.HealthStatusUp, Checks: map[string]string{}}
+	for _, check := range h.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+		err := check.Check(checkCtx)
+		cancel()
+		if err != nil {
+			report.Status = dto.HealthStatusDown
+			report.Checks[check.Name] = err.Error()
+			continue
+		}
+		report.Checks[check.Name] = dto.HealthStatusUp
+	}
+	return report
+}
+
+func (h *healthUseCase) Drain() {
+	h.draining.Store(true)
+}
+
+func NewHealthUseCase(checks ...HealthCheck) HealthUseCase {
+	return &healthUseCase{checks: checks}
+}
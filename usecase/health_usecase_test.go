@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fajritsaniy/golang-SHM/model/dto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthUseCaseTestSuite struct {
+	suite.Suite
+}
+
+func (suite *HealthUseCaseTestSuite) TestReadinessAllUpSuccess() {
+	useCase := NewHealthUseCase(HealthCheck{Name: "database", Check: func(ctx context.Context) error { return nil }})
+
+	report := useCase.Readiness(context.Background())
+	assert.Equal(suite.T(), dto.HealthStatusUp, report.Status)
+	assert.Equal(suite.T(), dto.HealthStatusUp, report.Checks["database"])
+}
+
+func (suite *HealthUseCaseTestSuite) TestReadinessCheckDownFail() {
+	useCase := NewHealthUseCase(
+		HealthCheck{Name: "database", Check: func(ctx context.Context) error { return errors.New("connection refused") }},
+		HealthCheck{Name: "uploads", Check: func(ctx context.Context) error { return nil }},
+	)
+
+	report := useCase.Readiness(context.Background())
+	assert.Equal(suite.T(), dto.HealthStatusDown, report.Status)
+	assert.Equal(suite.T(), "connection refused", report.Checks["database"])
+	assert.Equal(suite.T(), dto.HealthStatusUp, report.Checks["uploads"])
+}
+
+func (suite *HealthUseCaseTestSuite) TestReadinessWhileDrainingFail() {
+	useCase := NewHealthUseCase(HealthCheck{Name: "database", Check: func(ctx context.Context) error { return nil }})
+	useCase.Drain()
+
+	report := useCase.Readiness(context.Background())
+	assert.Equal(suite.T(), dto.HealthStatusDown, report.Status)
+}
+
+func TestHealthUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthUseCaseTestSuite))
+}
@@ -1,92 +1,133 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
 	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/fajritsaniy/golang-SHM/utils/logger"
+	"github.com/fajritsaniy/golang-SHM/utils/metrics"
+	"github.com/fajritsaniy/golang-SHM/utils/tracing"
+	"github.com/sirupsen/logrus"
 )
 
 type TransactionUseCase interface {
-	RegisterNewTransaction(payload *model.Transaction) error
-	FindAllTransaction() ([]model.Transaction, error)
-	FindByTransaction(id string) (model.Transaction, error)
+	RegisterNewTransaction(ctx context.Context, principal model.Principal, payload *model.Transaction) error
+	FindAllTransaction(ctx context.Context) ([]model.Transaction, error)
+	FindByTransaction(ctx context.Context, id string) (model.Transaction, error)
 }
 
 type transactionUseCase struct {
 	repo       repository.TransactionRepository
+	uow        repository.UnitOfWork
 	vehicleUC  VehicleUseCase
 	employeeUC EmployeeUseCase
 	customerUC CustomerUseCase
 }
 
-func (t *transactionUseCase) RegisterNewTransaction(payload *model.Transaction) error {
-	// Recommended use Transaction
+func (t *transactionUseCase) RegisterNewTransaction(ctx context.Context, principal model.Principal, payload *model.Transaction) (err error) {
+	ctx, span := tracing.Start(ctx, "TransactionUseCase.RegisterNewTransaction")
+	defer func() { tracing.End(span, err) }()
+
+	// sales staff always book their own sales, managers may book for others
+	if principal.Role == model.RoleSales || payload.EmployeeID == "" {
+		payload.EmployeeID = principal.EmployeeID
+	}
+	if payload.EmployeeID == "" {
+		return invalidField("employeeId", "transaction.employee_required", i18n.Args{"username": principal.Username})
+	}
+	if err := payload.Validate(); err != nil {
+		return apperror.FromValidation(err)
+	}
+
 	// get vehicle
-	vehicle, err := t.vehicleUC.FindById(payload.VehicleID)
+	vehicle, err := t.vehicleUC.FindById(ctx, payload.VehicleID)
 	if err != nil {
 		return err
 	}
 
 	// get employee
-	employee, err := t.employeeUC.FindById(payload.EmployeeID)
+	employee, err := t.employeeUC.FindById(ctx, payload.EmployeeID)
 	if err != nil {
 		return err
 	}
 
 	// get customer
-	customer, err := t.customerUC.FindById(payload.CustomerID)
+	customer, err := t.customerUC.FindById(ctx, payload.CustomerID)
 	if err != nil {
 		return err
 	}
 
-	// append customer vehicle
-	err = t.customerUC.AppendCustomerVehicle(customer, vehicle)
-	if err != nil {
-		return fmt.Errorf("failed to append customer vehicle")
-	}
-
 	// validate stock
 	if vehicle.Stock < payload.Qty {
-		return fmt.Errorf("not enough stock")
-	}
-
-	//update stock
-	err = t.vehicleUC.UpdateVehicleStock(payload.Qty, vehicle.ID)
-	if err != nil {
-		return fmt.Errorf("failed to update stock")
+		metrics.StockOutsTotal.Inc()
+		return model.ErrVehicleOutOfStock
 	}
 
-	payload.Vehicle = *vehicle
-	payload.Customer = *customer
-	payload.Employee = *employee
 	payload.TransactionDate = time.Now()
 	payload.PaymentAmount = int64(vehicle.SalePrice)
 
-	err = t.repo.Create(payload)
+	// all writes below are committed together or not at all
+	err = t.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		// append customer vehicle
+		if err := repos.CustomerRepo().CreateCustomerVehicle(ctx, customer, vehicle); err != nil {
+			return fmt.Errorf("failed to append customer vehicle: %w", err)
+		}
+
+		// update stock
+		if err := repos.VehicleRepo().UpdateStock(ctx, payload.Qty, vehicle.ID); err != nil {
+			if errors.Is(err, model.ErrVehicleOutOfStock) {
+				metrics.StockOutsTotal.Inc()
+				return err
+			}
+			return fmt.Errorf("failed to update stock: %w", err)
+		}
+
+		if err := repos.TransactionRepo().Create(ctx, payload); err != nil {
+			return fmt.Errorf("failed to save transaction: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to save transaction: %w", err)
+		logger.FromContext(ctx).WithError(err).WithField("vehicle_id", vehicle.ID).Warn("transaction rolled back")
+		return err
 	}
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"transaction_id": payload.ID,
+		"vehicle_id":     vehicle.ID,
+		"qty":            payload.Qty,
+	}).Info("transaction registered")
+	metrics.TransactionsTotal.WithLabelValues(payload.Type).Inc()
+	metrics.RevenueTotal.WithLabelValues(payload.Type).Add(float64(payload.PaymentAmount))
 
+	payload.Vehicle = *vehicle
+	payload.Customer = *customer
+	payload.Employee = *employee
 	return nil
 }
 
-func (t *transactionUseCase) FindAllTransaction() ([]model.Transaction, error) {
-	return t.repo.List()
+func (t *transactionUseCase) FindAllTransaction(ctx context.Context) ([]model.Transaction, error) {
+	return t.repo.List(ctx)
 }
 
-func (t *transactionUseCase) FindByTransaction(id string) (model.Transaction, error) {
-	return t.repo.Get(id)
+func (t *transactionUseCase) FindByTransaction(ctx context.Context, id string) (model.Transaction, error) {
+	return t.repo.Get(ctx, id)
 }
 
 func NewTransactionUseCase(
 	repo repository.TransactionRepository,
+	uow repository.UnitOfWork,
 	vehicleUC VehicleUseCase,
 	employeeUC EmployeeUseCase,
 	customerUC CustomerUseCase) TransactionUseCase {
 	return &transactionUseCase{
 		repo:       repo,
+		uow:        uow,
 		vehicleUC:  vehicleUC,
 		employeeUC: employeeUC,
 		customerUC: customerUC,
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/fajritsaniy/golang-SHM/utils/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var vehicleDummy = model.Vehicle{
+	BaseModel: model.BaseModel{ID: "v1"},
+	Model:     "Jazz",
+	Stock:     3,
+	SalePrice: 250000000,
+	Status:    "baru",
+}
+
+var employeeDummy = model.Employee{
+	BaseModel: model.BaseModel{ID: "e1"},
+	FirstName: "Budi",
+}
+
+var salesPrincipal = model.Principal{
+	UserID:     "u1",
+	Username:   "budi@mail.com",
+	Role:       model.RoleSales,
+	EmployeeID: "e1",
+}
+
+var customerDummy = model.Customer{
+	BaseModel: model.BaseModel{ID: "c1"},
+	FirstName: "Siti",
+}
+
+// use case mocks only implement the methods RegisterNewTransaction depends on
+type vehicleUseCaseMock struct {
+	mock.Mock
+	VehicleUseCase
+}
+
+func (v *vehicleUseCaseMock) FindById(ctx context.Context, id string) (*model.Vehicle, error) {
+	args := v.Called(id)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Vehicle), nil
+}
+
+type employeeUseCaseMock struct {
+	mock.Mock
+	EmployeeUseCase
+}
+
+func (e *employeeUseCaseMock) FindById(ctx context.Context, id string) (*model.Employee, error) {
+	args := e.Called(id)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Employee), nil
+}
+
+type customerUseCaseMock struct {
+	mock.Mock
+	CustomerUseCase
+}
+
+func (c *customerUseCaseMock) FindById(ctx context.Context, id string) (*model.Customer, error) {
+	args := c.Called(id)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), nil
+}
+
+type TransactionUseCaseTestSuite struct {
+	suite.Suite
+	sqlMock    sqlmock.Sqlmock
+	useCase    TransactionUseCase
+	vehicleUC  *vehicleUseCaseMock
+	employeeUC *employeeUseCaseMock
+	customerUC *customerUseCaseMock
+}
+
+func (suite *TransactionUseCaseTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.sqlMock = mock
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}))
+	assert.NoError(suite.T(), err)
+
+	suite.vehicleUC = new(vehicleUseCaseMock)
+	suite.employeeUC = new(employeeUseCaseMock)
+	suite.customerUC = new(customerUseCaseMock)
+	vehicle, employee, customer := vehicleDummy, employeeDummy, customerDummy
+	suite.vehicleUC.On("FindById", "v1").Return(&vehicle, nil)
+	suite.employeeUC.On("FindById", "e1").Return(&employee, nil)
+	suite.customerUC.On("FindById", "c1").Return(&customer, nil)
+
+	suite.useCase = NewTransactionUseCase(
+		repository.NewTransactionRepository(gormDB),
+		repository.NewUnitOfWork(gormDB),
+		suite.vehicleUC,
+		suite.employeeUC,
+		suite.customerUC,
+	)
+}
+
+func (suite *TransactionUseCaseTestSuite) newPayload() *model.Transaction {
+	return &model.Transaction{VehicleID: "v1", EmployeeID: "e1", CustomerID: "c1", Type: "offline", Qty: 1}
+}
+
+func (suite *TransactionUseCaseTestSuite) expectAppendCustomerVehicle() {
+	suite.sqlMock.ExpectExec(`UPDATE "mst_vehicle" SET "updated_at"`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlMock.ExpectQuery(`INSERT INTO "mst_customer"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("c1"))
+}
+
+// expectRegistration expects every statement of a sale that goes through.
+func (suite *TransactionUseCaseTestSuite) expectRegistration() {
+	suite.sqlMock.ExpectBegin()
+	suite.expectAppendCustomerVehicle()
+	suite.sqlMock.ExpectQuery(`INSERT INTO "customer_vehicles"`).
+		WillReturnRows(sqlmock.NewRows([]string{"vehicle_id", "customer_id"}).AddRow("v1", "c1"))
+	suite.sqlMock.ExpectExec(`UPDATE "mst_vehicle" SET "stock"`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlMock.ExpectQuery(`INSERT INTO "trx_transaction"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("t1"))
+	suite.sqlMock.ExpectCommit()
+}
+
+func (suite *TransactionUseCaseTestSuite) TestRegisterNewTransactionSuccess() {
+	suite.expectRegistration()
+
+	transactions := testutil.ToFloat64(metrics.TransactionsTotal.WithLabelValues("offline"))
+	revenue := testutil.ToFloat64(metrics.RevenueTotal.WithLabelValues("offline"))
+
+	payload := suite.newPayload()
+	err := suite.useCase.RegisterNewTransaction(context.Background(), salesPrincipal, payload)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(vehicleDummy.SalePrice), payload.PaymentAmount)
+	assert.Equal(suite.T(), transactions+1, testutil.ToFloat64(metrics.TransactionsTotal.WithLabelValues("offline")))
+	assert.Equal(suite.T(), revenue+float64(vehicleDummy.SalePrice), testutil.ToFloat64(metrics.RevenueTotal.WithLabelValues("offline")))
+	assert.Equal(suite.T(), "c1", payload.Customer.ID)
+	assert.NoError(suite.T(), suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *TransactionUseCaseTestSuite) TestRegisterNewTransactionAppendCustomerVehicleRollback() {
+	suite.sqlMock.ExpectBegin()
+	suite.expectAppendCustomerVehicle()
+	suite.sqlMock.ExpectQuery(`INSERT INTO "customer_vehicles"`).
+		WillReturnError(errors.New(repositoryErrorMessage))
+	suite.sqlMock.ExpectRollback()
+
+	err := suite.useCase.RegisterNewTransaction(context.Background(), salesPrincipal, suite.newPayload())
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "failed to append customer vehicle")
+	assert.NoError(suite.T(), suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *TransactionUseCaseTestSuite) TestRegisterNewTransactionUpdateStockRollback() {
+	suite.sqlMock.ExpectBegin()
+	suite.expectAppendCustomerVehicle()
+	suite.sqlMock.ExpectQuery(`INSERT INTO "customer_vehicles"`).
+		WillReturnRows(sqlmock.NewRows([]string{"vehicle_id", "customer_id"}).AddRow("v1", "c1"))
+	suite.sqlMock.ExpectExec(`UPDATE "mst_vehicle" SET "stock"`).
+		WillReturnError(errors.New(repositoryErrorMessage))
+	suite.sqlMock.ExpectRollback()
+
+	err := suite.useCase.RegisterNewTransaction(context.Background(), salesPrincipal, suite.newPayload())
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "failed to update stock")
+	assert.NoError(suite.T(), suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *TransactionUseCaseTestSuite) TestRegisterNewTransactionConcurrentOversellRollback() {
+	suite.sqlMock.ExpectBegin()
+	suite.expectAppendCustomerVehicle()
+	suite.sqlMock.ExpectQuery(`INSERT INTO "customer_vehicles"`).
+		WillReturnRows(sqlmock.NewRows([]string{"vehicle_id", "customer_id"}).AddRow("v1", "c1"))
+	// another sale took the remaining stock after it was read
+	suite.sqlMock.ExpectExec(`UPDATE "mst_vehicle" SET "stock"`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlMock.ExpectRollback()
+
+	err := suite.useCase.RegisterNewTransaction(context.Background(), salesPrincipal, suite.newPayload())
+	assert.ErrorIs(suite.T(), err, model.ErrVehicleOutOfStock)
+	assert.NoError(suite.T(), suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *TransactionUseCaseTestSuite) TestRegisterNewTransactionCreateRollback() {
+	suite.sqlMock.ExpectBegin()
+	suite.expectAppendCustomerVehicle()
+	suite.sqlMock.ExpectQuery(`INSERT INTO "customer_vehicles"`).
+		WillReturnRows(sqlmock.NewRows([]string{"vehicle_id", "customer_id"}).AddRow("v1", "c1"))
+	suite.sqlMock.ExpectExec(`UPDATE "mst_vehicle" SET "stock"`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlMock.ExpectQuery(`INSERT INTO "trx_transaction"`).
+		WillReturnError(errors.New(repositoryErrorMessage))
+	suite.sqlMock.ExpectRollback()
+
+	err := suite.useCase.RegisterNewTransaction(context.Background(), salesPrincipal, suite.newPayload())
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "failed to save transaction")
+	assert.NoError(suite.T(), suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *TransactionUseCaseTestSuite) TestRegisterNewTransactionNotEnoughStockFail() {
+	stockOuts := testutil.ToFloat64(metrics.StockOutsTotal)
+	payload := suite.newPayload()
+	payload.Qty = vehicleDummy.Stock + 1
+	err := suite.useCase.RegisterNewTransaction(context.Background(), salesPrincipal, payload)
+	assert.ErrorIs(suite.T(), err, model.ErrVehicleOutOfStock)
+	assert.Equal(suite.T(), stockOuts+1, testutil.ToFloat64(metrics.StockOutsTotal))
+	// no transaction is opened when validation fails
+	assert.NoError(suite.T(), suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *TransactionUseCaseTestSuite) TestRegisterNewTransactionSalesBooksOwnSale() {
+	payload := suite.newPayload()
+	payload.EmployeeID = "e2"
+	suite.employeeUC.On("FindById", "e2").Return(nil, errors.New(repositoryErrorMessage))
+	suite.expectRegistration()
+	err := suite.useCase.RegisterNewTransaction(context.Background(), salesPrincipal, payload)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "e1", payload.EmployeeID)
+	suite.employeeUC.AssertNotCalled(suite.T(), "FindById", "e2")
+	assert.NoError(suite.T(), suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *TransactionUseCaseTestSuite) TestRegisterNewTransactionManagerDefaultsToSelf() {
+	manager := model.Principal{UserID: "u2", Role: model.RoleManager, EmployeeID: "e1"}
+	payload := suite.newPayload()
+	payload.EmployeeID = ""
+	suite.expectRegistration()
+	err := suite.useCase.RegisterNewTransaction(context.Background(), manager, payload)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "e1", payload.EmployeeID)
+	assert.NoError(suite.T(), suite.sqlMock.ExpectationsWereMet())
+}
+
+func (suite *TransactionUseCaseTestSuite) TestRegisterNewTransactionWithoutEmployeeFail() {
+	admin := model.Principal{UserID: "u3", Username: "admin", Role: model.RoleAdmin}
+	payload := suite.newPayload()
+	payload.EmployeeID = ""
+	err := suite.useCase.RegisterNewTransaction(context.Background(), admin, payload)
+	assert.Error(suite.T(), err)
+	suite.vehicleUC.AssertNotCalled(suite.T(), "FindById", "v1")
+}
+
+func (suite *TransactionUseCaseTestSuite) TestRegisterNewTransactionNegativeQtyFail() {
+	payload := suite.newPayload()
+	payload.Qty = -1
+	err := suite.useCase.RegisterNewTransaction(context.Background(), salesPrincipal, payload)
+	appErr := apperror.From(err)
+	assert.Equal(suite.T(), apperror.KindValidation, appErr.Kind)
+	assert.Contains(suite.T(), appErr.Fields, "qty")
+	suite.vehicleUC.AssertNotCalled(suite.T(), "FindById", "v1")
+}
+
+func TestTransactionUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionUseCaseTestSuite))
+}
@@ -0,0 +1,328 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/fajritsaniy/golang-SHM/utils/imaging"
+	"github.com/fajritsaniy/golang-SHM/utils/logger"
+	"github.com/fajritsaniy/golang-SHM/utils/storage"
+	"github.com/google/uuid"
+)
+
+// VehicleImageUpload is one file of an upload to a gallery.
+type VehicleImageUpload struct {
+	File    io.Reader
+	Caption string
+}
+
+type VehicleImageUseCase interface {
+	FindAll(ctx context.Context, vehicleID string) ([]model.VehicleImage, error)
+	// Upload appends the images to the gallery. The first image of a vehicle
+	// becomes its primary.
+	Upload(ctx context.Context, vehicleID string, uploads []VehicleImageUpload) ([]model.VehicleImage, error)
+	// Reorder takes every image ID of the vehicle in the new order and returns
+	// the reordered gallery.
+	Reorder(ctx context.Context, vehicleID string, imageIDs []string) ([]model.VehicleImage, error)
+	SetPrimary(ctx context.Context, vehicleID string, imageID string) ([]model.VehicleImage, error)
+	// Delete removes the image, the next one in order takes over as primary.
+	Delete(ctx context.Context, vehicleID string, imageID string) error
+	// Open and OpenPrimary open an image for streaming, the caller closes its
+	// body. Open serves the original for an empty size and for a size the
+	// image is already smaller than.
+	Open(ctx context.Context, vehicleID string, imageID string, size string) (*storage.Object, error)
+	OpenPrimary(ctx context.Context, vehicleID string) (*storage.Object, error)
+}
+
+type vehicleImageUseCase struct {
+	repo        repository.VehicleImageRepository
+	vehicleRepo repository.VehicleRepository
+	uow         repository.UnitOfWork
+	fileUseCase FileUseCase
+	imageLimits imaging.Limits
+}
+
+func (v *vehicleImageUseCase) FindAll(ctx context.Context, vehicleID string) ([]model.VehicleImage, error) {
+	if err := v.checkVehicle(ctx, vehicleID); err != nil {
+		return nil, err
+	}
+	return v.repo.List(ctx, vehicleID)
+}
+
+func (v *vehicleImageUseCase) Upload(ctx context.Context, vehicleID string, uploads []VehicleImageUpload) ([]model.VehicleImage, error) {
+	if len(uploads) == 0 {
+		return nil, invalidField("images", "vehicle.image_required", nil)
+	}
+	gallery, err := v.FindAll(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	errTooMany := invalidField("images", "vehicle.too_many_images", i18n.Args{"max": model.MaxVehicleImages})
+	if len(gallery)+len(uploads) > model.MaxVehicleImages {
+		return nil, errTooMany
+	}
+
+	images := make([]model.VehicleImage, len(uploads))
+	for i, upload := range uploads {
+		images[i] = model.VehicleImage{
+			VehicleID: vehicleID,
+			Caption:   upload.Caption,
+		}
+		if err := images[i].Validate(); err != nil {
+			return nil, apperror.FromValidation(err)
+		}
+	}
+
+	// objects first, rows second: a failure leaves no row without its file
+	for i, upload := range uploads {
+		stored, err := storeImage(ctx, v.fileUseCase, v.imageLimits, "images", upload.File)
+		if err != nil {
+			removeImageObjects(ctx, v.fileUseCase, images[:i])
+			return nil, err
+		}
+		images[i].StorageKey, images[i].Renditions = stored.StorageKey, stored.Renditions
+		images[i].Width, images[i].Height = stored.Width, stored.Height
+	}
+	// the places in the gallery are taken under the lock, concurrent uploads
+	// would otherwise share a position or both become primary
+	err = v.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		imageRepo := repos.VehicleImageRepo()
+		if err := imageRepo.LockGallery(ctx, vehicleID); err != nil {
+			return err
+		}
+		gallery, err := imageRepo.List(ctx, vehicleID)
+		if err != nil {
+			return err
+		}
+		if len(gallery)+len(images) > model.MaxVehicleImages {
+			return errTooMany
+		}
+		// deletes leave gaps, so new images go after the last position
+		next, hasPrimary := 0, false
+		for _, image := range gallery {
+			next = max(next, image.Position+1)
+			hasPrimary = hasPrimary || image.IsPrimary
+		}
+		for i := range images {
+			images[i].Position = next + i
+			images[i].IsPrimary = !hasPrimary && i == 0
+			if err := imageRepo.Create(ctx, &images[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		removeImageObjects(ctx, v.fileUseCase, images)
+		return nil, err
+	}
+	return images, nil
+}
+
+func (v *vehicleImageUseCase) Reorder(ctx context.Context, vehicleID string, imageIDs []string) ([]model.VehicleImage, error) {
+	gallery, err := v.FindAll(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[string]int, len(gallery))
+	for _, image := range gallery {
+		positions[image.ID] = image.Position
+	}
+	seen := make(map[string]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		if _, ok := positions[id]; !ok || seen[id] {
+			return nil, invalidField("imageIds", "vehicle.image_order_mismatch", nil)
+		}
+		seen[id] = true
+	}
+	if len(imageIDs) != len(gallery) {
+		return nil, invalidField("imageIds", "vehicle.image_order_mismatch", nil)
+	}
+
+	err = v.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		for position, id := range imageIDs {
+			if positions[id] == position {
+				continue
+			}
+			if err := repos.VehicleImageRepo().UpdatePosition(ctx, vehicleID, id, position); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.repo.List(ctx, vehicleID)
+}
+
+func (v *vehicleImageUseCase) SetPrimary(ctx context.Context, vehicleID string, imageID string) ([]model.VehicleImage, error) {
+	err := v.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		return repos.VehicleImageRepo().SetPrimary(ctx, vehicleID, imageID)
+	})
+	if err != nil {
+		return nil, imageNotFound(err, vehicleID, imageID)
+	}
+	return v.repo.List(ctx, vehicleID)
+}
+
+func (v *vehicleImageUseCase) Delete(ctx context.Context, vehicleID string, imageID string) error {
+	image, err := v.repo.Get(ctx, vehicleID, imageID)
+	if err != nil {
+		return imageNotFound(err, vehicleID, imageID)
+	}
+	err = v.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		if err := repos.VehicleImageRepo().Delete(ctx, vehicleID, imageID); err != nil {
+			return err
+		}
+		if !image.IsPrimary {
+			return nil
+		}
+		rest, err := repos.VehicleImageRepo().List(ctx, vehicleID)
+		if err != nil || len(rest) == 0 {
+			return err
+		}
+		return repos.VehicleImageRepo().SetPrimary(ctx, vehicleID, rest[0].ID)
+	})
+	if err != nil {
+		return imageNotFound(err, vehicleID, imageID)
+	}
+	removeImageObjects(ctx, v.fileUseCase, []model.VehicleImage{*image})
+	return nil
+}
+
+func (v *vehicleImageUseCase) Open(ctx context.Context, vehicleID string, imageID string, size string) (*storage.Object, error) {
+	if size != "" && !imaging.IsSize(size) {
+		return nil, apperror.NotFound("VEHICLE_IMAGE_SIZE_NOT_FOUND", "vehicle_image.size_not_found").With(i18n.Args{"size": size})
+	}
+	image, err := v.repo.Get(ctx, vehicleID, imageID)
+	if err != nil {
+		return nil, imageNotFound(err, vehicleID, imageID)
+	}
+	key := image.StorageKey
+	if rendition, ok := image.Renditions[size]; ok {
+		key = rendition
+	}
+	return v.open(ctx, key, apperror.NotFound("VEHICLE_IMAGE_NOT_FOUND", "vehicle_image.not_found").With(i18n.Args{"id": imageID, "vehicleId": vehicleID}))
+}
+
+func (v *vehicleImageUseCase) OpenPrimary(ctx context.Context, vehicleID string) (*storage.Object, error) {
+	vehicle, err := v.vehicleRepo.Get(ctx, vehicleID)
+	if err != nil {
+		return nil, notFound(err, "VEHICLE_NOT_FOUND", "vehicle.not_found", i18n.Args{"id": vehicleID})
+	}
+	errNoImage := apperror.NotFound("VEHICLE_IMAGE_NOT_FOUND", "vehicle.image_not_found").With(i18n.Args{"id": vehicleID})
+	if vehicle.PrimaryImage == nil {
+		return nil, errNoImage
+	}
+	return v.open(ctx, vehicle.PrimaryImage.StorageKey, errNoImage)
+}
+
+// open opens the object under key, answering errMissing when the row
+// outlived its file.
+func (v *vehicleImageUseCase) open(ctx context.Context, key string, errMissing error) (*storage.Object, error) {
+	object, err := v.fileUseCase.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		logger.FromContext(ctx).WithField("key", key).Warn("vehicle image missing from storage")
+		return nil, errMissing
+	}
+	return object, err
+}
+
+func (v *vehicleImageUseCase) checkVehicle(ctx context.Context, vehicleID string) error {
+	_, err := v.vehicleRepo.Get(ctx, vehicleID)
+	return notFound(err, "VEHICLE_NOT_FOUND", "vehicle.not_found", i18n.Args{"id": vehicleID})
+}
+
+// storeImage checks an upload, strips its metadata and stores it with its
+// renditions under a fresh key, so uploads never replace each other. The
+// returned image has no vehicle or place in the gallery yet, field names the
+// form field in validation errors.
+func storeImage(ctx context.Context, fileUseCase FileUseCase, limits imaging.Limits, field string, file io.Reader) (model.VehicleImage, error) {
+	processed, err := imaging.Process(file, limits)
+	if err != nil {
+		return model.VehicleImage{}, imageError(err, field, limits)
+	}
+	base := "vehicles/" + uuid.New().String()
+	image := model.VehicleImage{
+		StorageKey: base + "." + processed.Format.Ext,
+		Width:      processed.Width,
+		Height:     processed.Height,
+	}
+	if err := fileUseCase.Save(ctx, image.StorageKey, bytes.NewReader(processed.Data), processed.Format.ContentType); err != nil {
+		return model.VehicleImage{}, err
+	}
+	for _, rendition := range processed.Renditions {
+		key := fmt.Sprintf("%s_%s.%s", base, rendition.Size.Name, rendition.Format.Ext)
+		if err := fileUseCase.Save(ctx, key, bytes.NewReader(rendition.Data), rendition.Format.ContentType); err != nil {
+			removeImageObjects(ctx, fileUseCase, []model.VehicleImage{image})
+			return model.VehicleImage{}, err
+		}
+		if image.Renditions == nil {
+			image.Renditions = map[string]string{}
+		}
+		image.Renditions[rendition.Size.Name] = key
+	}
+	return image, nil
+}
+
+func imageError(err error, field string, limits imaging.Limits) error {
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedFormat):
+		return invalidField(field, "vehicle.image_type", nil)
+	case errors.Is(err, imaging.ErrTooLarge):
+		return invalidField(field, "vehicle.image_too_large", i18n.Args{"max": limits.MaxBytes >> 20})
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return invalidField(field, "vehicle.image_too_many_pixels", i18n.Args{"max": limits.MaxPixels / 1_000_000})
+	case errors.Is(err, imaging.ErrInvalid):
+		return invalidField(field, "vehicle.image_invalid", nil)
+	}
+	return err
+}
+
+// removeImageObjects deletes the stored files of images with their
+// renditions. A file left behind only costs space, so failures are logged
+// and not returned.
+func removeImageObjects(ctx context.Context, fileUseCase FileUseCase, images []model.VehicleImage) {
+	for _, image := range images {
+		keys := []string{image.StorageKey}
+		for _, key := range image.Renditions {
+			keys = append(keys, key)
+		}
+		for _, key := range keys {
+			if key == "" {
+				continue
+			}
+			if err := fileUseCase.Delete(ctx, key); err != nil {
+				logger.FromContext(ctx).WithError(err).WithField("key", key).Warn("failed to remove image file")
+			}
+		}
+	}
+}
+
+func imageNotFound(err error, vehicleID string, imageID string) error {
+	return notFound(err, "VEHICLE_IMAGE_NOT_FOUND", "vehicle_image.not_found", i18n.Args{"id": imageID, "vehicleId": vehicleID})
+}
+
+func NewVehicleImageUseCase(
+	repo repository.VehicleImageRepository,
+	vehicleRepo repository.VehicleRepository,
+	uow repository.UnitOfWork,
+	fileUseCase FileUseCase,
+	imageLimits imaging.Limits,
+) VehicleImageUseCase {
+	return &vehicleImageUseCase{
+		repo:        repo,
+		vehicleRepo: vehicleRepo,
+		uow:         uow,
+		fileUseCase: fileUseCase,
+		imageLimits: imageLimits,
+	}
+}
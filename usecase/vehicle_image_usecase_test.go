@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
+	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/fajritsaniy/golang-SHM/utils/imaging"
+	"github.com/fajritsaniy/golang-SHM/utils/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type vehicleImageRepoMock struct {
+	mock.Mock
+}
+
+func (v *vehicleImageRepoMock) List(ctx context.Context, vehicleID string) ([]model.VehicleImage, error) {
+	args := v.Called(vehicleID)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.VehicleImage), nil
+}
+
+func (v *vehicleImageRepoMock) LockGallery(ctx context.Context, vehicleID string) error {
+	return v.Called(vehicleID).Error(0)
+}
+
+func (v *vehicleImageRepoMock) Get(ctx context.Context, vehicleID string, id string) (*model.VehicleImage, error) {
+	args := v.Called(vehicleID, id)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.VehicleImage), nil
+}
+
+func (v *vehicleImageRepoMock) Create(ctx context.Context, image *model.VehicleImage) error {
+	return v.Called(image).Error(0)
+}
+
+func (v *vehicleImageRepoMock) UpdatePosition(ctx context.Context, vehicleID string, id string, position int) error {
+	return v.Called(vehicleID, id, position).Error(0)
+}
+
+func (v *vehicleImageRepoMock) SetPrimary(ctx context.Context, vehicleID string, id string) error {
+	return v.Called(vehicleID, id).Error(0)
+}
+
+func (v *vehicleImageRepoMock) Delete(ctx context.Context, vehicleID string, id string) error {
+	return v.Called(vehicleID, id).Error(0)
+}
+
+type vehicleRepoMock struct {
+	mock.Mock
+	repository.VehicleRepository
+}
+
+func (v *vehicleRepoMock) Get(ctx context.Context, id string) (*model.Vehicle, error) {
+	args := v.Called(id)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Vehicle), nil
+}
+
+type fileUseCaseMock struct {
+	mock.Mock
+}
+
+func (f *fileUseCaseMock) Save(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	return f.Called(key, contentType).Error(0)
+}
+
+func (f *fileUseCaseMock) Open(ctx context.Context, key string) (*storage.Object, error) {
+	args := f.Called(key)
+	if args.Get(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.Object), nil
+}
+
+func (f *fileUseCaseMock) Delete(ctx context.Context, key string) error {
+	return f.Called(key).Error(0)
+}
+
+var galleryDummy = []model.VehicleImage{
+	{BaseModel: model.BaseModel{ID: "i1"}, VehicleID: "v1", Position: 0, IsPrimary: true, StorageKey: "vehicles/a.jpg"},
+	{BaseModel: model.BaseModel{ID: "i2"}, VehicleID: "v1", Position: 1, StorageKey: "vehicles/b.jpg"},
+	{BaseModel: model.BaseModel{ID: "i3"}, VehicleID: "v1", Position: 2, StorageKey: "vehicles/c.jpg"},
+}
+
+type VehicleImageUseCaseTestSuite struct {
+	suite.Suite
+	repo        *vehicleImageRepoMock
+	vehicleRepo *vehicleRepoMock
+	fileUseCase *fileUseCaseMock
+	useCase     VehicleImageUseCase
+}
+
+func (suite *VehicleImageUseCaseTestSuite) SetupTest() {
+	suite.repo = new(vehicleImageRepoMock)
+	suite.vehicleRepo = new(vehicleRepoMock)
+	suite.fileUseCase = new(fileUseCaseMock)
+	suite.useCase = NewVehicleImageUseCase(
+		suite.repo,
+		suite.vehicleRepo,
+		&unitOfWorkStub{imageRepo: suite.repo},
+		suite.fileUseCase,
+		imaging.Limits{MaxBytes: 1 << 20, MaxPixels: 1_000_000},
+	)
+	suite.vehicleRepo.On("Get", "v1").Return(&model.Vehicle{BaseModel: model.BaseModel{ID: "v1"}}, nil)
+}
+
+func (suite *VehicleImageUseCaseTestSuite) TestReorderSuccess() {
+	suite.repo.On("List", "v1").Return(append([]model.VehicleImage{}, galleryDummy...), nil)
+	suite.repo.On("UpdatePosition", "v1", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := suite.useCase.Reorder(context.Background(), "v1", []string{"i3", "i1", "i2"})
+	assert.NoError(suite.T(), err)
+	suite.repo.AssertCalled(suite.T(), "UpdatePosition", "v1", "i3", 0)
+	suite.repo.AssertCalled(suite.T(), "UpdatePosition", "v1", "i1", 1)
+	suite.repo.AssertCalled(suite.T(), "UpdatePosition", "v1", "i2", 2)
+}
+
+func (suite *VehicleImageUseCaseTestSuite) TestReorderMismatchFail() {
+	suite.repo.On("List", "v1").Return(append([]model.VehicleImage{}, galleryDummy...), nil)
+
+	orders := map[string][]string{
+		"missing image":   {"i2", "i1"},
+		"unknown image":   {"i1", "i2", "i9"},
+		"duplicate image": {"i1", "i1", "i2"},
+		"extra image":     {"i1", "i2", "i3", "i3"},
+	}
+	for name, order := range orders {
+		_, err := suite.useCase.Reorder(context.Background(), "v1", order)
+		assert.Equal(suite.T(), apperror.KindValidation, apperror.KindOf(err), name)
+	}
+	suite.repo.AssertNotCalled(suite.T(), "UpdatePosition", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *VehicleImageUseCaseTestSuite) TestDeletePrimaryPromotesNextSuccess() {
+	primary := galleryDummy[0]
+	suite.repo.On("Get", "v1", "i1").Return(&primary, nil)
+	suite.repo.On("Delete", "v1", "i1").Return(nil)
+	suite.repo.On("List", "v1").Return(append([]model.VehicleImage{}, galleryDummy[1:]...), nil)
+	suite.repo.On("SetPrimary", "v1", "i2").Return(nil)
+	suite.fileUseCase.On("Delete", "vehicles/a.jpg").Return(nil)
+
+	err := suite.useCase.Delete(context.Background(), "v1", "i1")
+	assert.NoError(suite.T(), err)
+	suite.repo.AssertCalled(suite.T(), "SetPrimary", "v1", "i2")
+	suite.fileUseCase.AssertCalled(suite.T(), "Delete", "vehicles/a.jpg")
+}
+
+func (suite *VehicleImageUseCaseTestSuite) TestDeleteOtherKeepsPrimarySuccess() {
+	other := galleryDummy[2]
+	suite.repo.On("Get", "v1", "i3").Return(&other, nil)
+	suite.repo.On("Delete", "v1", "i3").Return(nil)
+	suite.fileUseCase.On("Delete", "vehicles/c.jpg").Return(nil)
+
+	err := suite.useCase.Delete(context.Background(), "v1", "i3")
+	assert.NoError(suite.T(), err)
+	suite.repo.AssertNotCalled(suite.T(), "SetPrimary", mock.Anything, mock.Anything)
+}
+
+func (suite *VehicleImageUseCaseTestSuite) TestUploadInsertErrorRemovesFilesFail() {
+	var buf bytes.Buffer
+	assert.NoError(suite.T(), png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 200))))
+	suite.repo.On("List", "v1").Return([]model.VehicleImage{}, nil)
+	suite.repo.On("LockGallery", "v1").Return(nil)
+	suite.repo.On("Create", mock.AnythingOfType("*model.VehicleImage")).Return(errors.New(repositoryErrorMessage))
+	suite.fileUseCase.On("Save", mock.AnythingOfType("string"), "image/png").Return(nil)
+	suite.fileUseCase.On("Delete", mock.AnythingOfType("string")).Return(nil)
+
+	_, err := suite.useCase.Upload(context.Background(), "v1", []VehicleImageUpload{{File: &buf}})
+	assert.Error(suite.T(), err)
+	// the original and its thumbnail were stored, both are removed again
+	var saved, removed []string
+	for _, call := range suite.fileUseCase.Calls {
+		switch call.Method {
+		case "Save":
+			saved = append(saved, call.Arguments.String(0))
+		case "Delete":
+			removed = append(removed, call.Arguments.String(0))
+		}
+	}
+	assert.Len(suite.T(), saved, 2)
+	assert.ElementsMatch(suite.T(), saved, removed)
+}
+
+func (suite *VehicleImageUseCaseTestSuite) TestUploadPlacesUnderLockSuccess() {
+	var buf bytes.Buffer
+	assert.NoError(suite.T(), png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 100, 100))))
+	// another upload took the primary and a position after the first read,
+	// a delete left a gap at position 1
+	suite.repo.On("List", "v1").Return([]model.VehicleImage{}, nil).Once()
+	suite.repo.On("LockGallery", "v1").Return(nil)
+	suite.repo.On("List", "v1").Return([]model.VehicleImage{galleryDummy[0], galleryDummy[2]}, nil).Once()
+	suite.repo.On("Create", mock.AnythingOfType("*model.VehicleImage")).Return(nil)
+	suite.fileUseCase.On("Save", mock.AnythingOfType("string"), "image/png").Return(nil)
+
+	images, err := suite.useCase.Upload(context.Background(), "v1", []VehicleImageUpload{{File: &buf}})
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), images, 1) {
+		assert.Equal(suite.T(), 3, images[0].Position)
+		assert.False(suite.T(), images[0].IsPrimary)
+	}
+	suite.repo.AssertExpectations(suite.T())
+}
+
+func TestVehicleImageUseCaseTestSuite(t *testing.T) {
+	suite.Run(t, new(VehicleImageUseCaseTestSuite))
+}
@@ -1,95 +1,190 @@
 package usecase
 
 import (
-	"fmt"
-	"mime/multipart"
-	"strings"
+	"context"
+	"errors"
+	"io"
 
 	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
 	"github.com/fajritsaniy/golang-SHM/model/dto"
 	"github.com/fajritsaniy/golang-SHM/repository"
+	"github.com/fajritsaniy/golang-SHM/utils/i18n"
+	"github.com/fajritsaniy/golang-SHM/utils/imaging"
+	"github.com/fajritsaniy/golang-SHM/utils/metrics"
+	"github.com/fajritsaniy/golang-SHM/utils/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type VehicleUseCase interface {
 	BaseUseCase[model.Vehicle]
-	Paging(requestQueryParams dto.RequestQueryParams) ([]model.Vehicle, dto.Paging, error)
-	UpdateVehicleStock(count int, id string) error
-	UploadImage(payload *model.Vehicle, file multipart.File, fileExt string) error
+	// Paging lists the vehicles matching filter and the search words in
+	// QueryParams.Query.
+	Paging(ctx context.Context, filter dto.VehicleFilter, requestQueryParams dto.RequestQueryParams) ([]model.Vehicle, dto.Paging, error)
+	Facets(ctx context.Context, filter dto.VehicleFilter, query string) (dto.VehicleFacets, error)
+	UpdateVehicleStock(ctx context.Context, count int, id string) error
+	UploadImage(ctx context.Context, payload *model.Vehicle, file io.Reader) error
 }
 
 type vehicleUseCase struct {
 	repo         repository.VehicleRepository
+	uow          repository.UnitOfWork
 	brandUseCase BrandUseCase
 	fileUseCase  FileUseCase
+	imageLimits  imaging.Limits
 }
 
-func (v *vehicleUseCase) SearchBy(by map[string]interface{}) ([]model.Vehicle, error) {
-	return v.repo.Search(by)
+func (v *vehicleUseCase) SearchBy(ctx context.Context, by map[string]interface{}) ([]model.Vehicle, error) {
+	return v.repo.Search(ctx, by)
 }
 
-func (v *vehicleUseCase) FindAll() ([]model.Vehicle, error) {
-	return v.repo.List()
+func (v *vehicleUseCase) FindAll(ctx context.Context) ([]model.Vehicle, error) {
+	return v.repo.List(ctx)
 }
 
-func (v *vehicleUseCase) FindById(id string) (*model.Vehicle, error) {
-	vehicle, err := v.repo.Get(id)
+func (v *vehicleUseCase) FindById(ctx context.Context, id string) (*model.Vehicle, error) {
+	ctx, span := tracing.Start(ctx, "VehicleUseCase.FindById", attribute.String("vehicle.id", id))
+	defer span.End()
+
+	vehicle, err := v.repo.Get(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("vehicle with id %s not found", id)
+		return nil, notFound(err, "VEHICLE_NOT_FOUND", "vehicle.not_found", i18n.Args{"id": id})
 	}
 
 	return vehicle, nil
 }
 
-func (v *vehicleUseCase) SaveData(payload *model.Vehicle) error {
-	brand, err := v.brandUseCase.FindById(payload.BrandID)
+func (v *vehicleUseCase) SaveData(ctx context.Context, payload *model.Vehicle) error {
+	if err := v.prepare(ctx, payload); err != nil {
+		return err
+	}
+	return v.repo.Save(ctx, payload)
+}
+
+// prepare validates payload and checks that its brand and, on updates, the
+// vehicle itself exist.
+func (v *vehicleUseCase) prepare(ctx context.Context, payload *model.Vehicle) error {
+	// the gallery is changed through its own endpoints only
+	payload.Images, payload.PrimaryImage = nil, nil
+	if err := payload.Validate(); err != nil {
+		return apperror.FromValidation(err)
+	}
+
+	brand, err := v.brandUseCase.FindById(ctx, payload.BrandID)
 	if err != nil {
-		return fmt.Errorf("brand with ID %s not found", payload.ID)
+		if apperror.KindOf(err) == apperror.KindNotFound {
+			return invalidField("brandId", "brand.not_found", i18n.Args{"id": payload.BrandID})
+		}
+		return err
 	}
 	payload.BrandID = brand.ID
-	return v.repo.Save(payload)
+
+	if payload.ID != "" {
+		_, err := v.FindById(ctx, payload.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (v *vehicleUseCase) DeleteData(id string) error {
-	return v.repo.Delete(id)
+func (v *vehicleUseCase) DeleteData(ctx context.Context, id string) error {
+	return v.repo.Delete(ctx, id)
 }
 
-func (v *vehicleUseCase) UpdateVehicleStock(count int, id string) error {
-	return v.repo.UpdateStock(count, id)
+func (v *vehicleUseCase) UpdateVehicleStock(ctx context.Context, count int, id string) error {
+	err := v.repo.UpdateStock(ctx, count, id)
+	if errors.Is(err, model.ErrVehicleOutOfStock) {
+		metrics.StockOutsTotal.Inc()
+	}
+	return err
 }
 
-func (v *vehicleUseCase) Paging(requestQueryParams dto.RequestQueryParams) ([]model.Vehicle, dto.Paging, error) {
-	if !requestQueryParams.QueryParams.IsSortValid() {
-		return nil, dto.Paging{}, fmt.Errorf("invalid sort by: %s", requestQueryParams.QueryParams.Sort)
+func (v *vehicleUseCase) Paging(ctx context.Context, filter dto.VehicleFilter, requestQueryParams dto.RequestQueryParams) ([]model.Vehicle, dto.Paging, error) {
+	queryParams := requestQueryParams.QueryParams
+	if !queryParams.IsSortValid() {
+		return nil, dto.Paging{}, invalidField("sort", "query.invalid_sort", i18n.Args{"sort": queryParams.Sort})
+	}
+	if queryParams.Order != "" && !vehicleOrderColumns[queryParams.Order] {
+		return nil, dto.Paging{}, invalidField("order", "query.invalid_order", i18n.Args{"order": queryParams.Order})
+	}
+	if err := validateVehicleFilter(filter); err != nil {
+		return nil, dto.Paging{}, err
 	}
-	return v.repo.Paging(requestQueryParams)
+	return v.repo.Paging(ctx, filter, requestQueryParams)
+}
+
+func (v *vehicleUseCase) Facets(ctx context.Context, filter dto.VehicleFilter, query string) (dto.VehicleFacets, error) {
+	if err := validateVehicleFilter(filter); err != nil {
+		return dto.VehicleFacets{}, err
+	}
+	return v.repo.Facets(ctx, filter, query)
+}
 
+// vehicleOrderColumns are the columns a vehicle list can be ordered by.
+var vehicleOrderColumns = map[string]bool{
+	"id":              true,
+	"model":           true,
+	"production_year": true,
+	"color":           true,
+	"stock":           true,
+	"sale_price":      true,
+	"status":          true,
+	"created_at":      true,
 }
 
-func (v *vehicleUseCase) UploadImage(payload *model.Vehicle, file multipart.File, fileExt string) error {
-	concatName := fmt.Sprintf("%s-%s", payload.Model, payload.BrandID)
-	fileName := fmt.Sprintf("img-%s.%s", strings.ToLower(concatName), fileExt)
-	fileLocation, err := v.fileUseCase.Save(file, fileName)
+func validateVehicleFilter(filter dto.VehicleFilter) error {
+	if filter.Status != "" && filter.Status != model.VehicleStatusNew && filter.Status != model.VehicleStatusUsed {
+		return invalidField("status", "query.invalid_status", i18n.Args{"status": filter.Status})
+	}
+	if filter.MinYear > 0 && filter.MaxYear > 0 && filter.MinYear > filter.MaxYear {
+		return invalidField("maxYear", "query.invalid_range", i18n.Args{"min": filter.MinYear})
+	}
+	if filter.MinPrice > 0 && filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice {
+		return invalidField("maxPrice", "query.invalid_range", i18n.Args{"min": filter.MinPrice})
+	}
+	return nil
+}
+
+// UploadImage adds the vehicle with the file as its primary image.
+func (v *vehicleUseCase) UploadImage(ctx context.Context, payload *model.Vehicle, file io.Reader) error {
+	// always a new vehicle, existing ones get images through their gallery
+	payload.ID = ""
+	if err := v.prepare(ctx, payload); err != nil {
+		return err
+	}
+	image, err := storeImage(ctx, v.fileUseCase, v.imageLimits, "image", file)
 	if err != nil {
 		return err
 	}
-
-	payload.ImgPath = fileLocation
-	payload.UrlPath = fmt.Sprintf("/vehicles/image/%s", strings.ToLower(concatName))
-	err = v.SaveData(payload)
+	image.IsPrimary = true
+	err = v.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		if err := repos.VehicleRepo().Save(ctx, payload); err != nil {
+			return err
+		}
+		image.VehicleID = payload.ID
+		return repos.VehicleImageRepo().Create(ctx, &image)
+	})
 	if err != nil {
+		removeImageObjects(ctx, v.fileUseCase, []model.VehicleImage{image})
 		return err
 	}
+	payload.PrimaryImage = &image
 	return nil
 }
 
 func NewVehicleUseCase(
 	repo repository.VehicleRepository,
+	uow repository.UnitOfWork,
 	brandUseCase BrandUseCase,
 	fileUseCase FileUseCase,
+	imageLimits imaging.Limits,
 ) VehicleUseCase {
 	return &vehicleUseCase{
 		repo:         repo,
+		uow:          uow,
 		brandUseCase: brandUseCase,
 		fileUseCase:  fileUseCase,
+		imageLimits:  imageLimits,
 	}
 }
@@ -0,0 +1,23 @@
+package common
+
+import (
+	"fmt"
+	"os"
+)
+
+// CheckWritableDir creates dir when it is missing and proves a file can be
+// written to it.
+func CheckWritableDir(dir string) error {
+	if dir == "" {
+		return fmt.Errorf("directory is not configured")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
@@ -1,7 +1,6 @@
 package common
 
 import (
-	"log"
 	"math"
 	"os"
 	"strconv"
@@ -20,10 +19,8 @@ func GetPaginationParams(params dto.PaginationParam) dto.PaginationQuery {
 	}
 
 	if params.Limit == 0 {
-		err := LoadEnv()
-		if err != nil {
-			log.Println(err)
-		}
+		// a missing .env is fine, the variable may come from the environment
+		_ = LoadEnv()
 		n, _ := strconv.Atoi(os.Getenv("DEFAULT_ROWS_PER_PAGE"))
 		take = n
 	} else {
@@ -1,9 +1,10 @@
 package common
 
 import (
-	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/fajritsaniy/golang-SHM/model/apperror"
 	"github.com/fajritsaniy/golang-SHM/model/dto"
 	"github.com/gin-gonic/gin"
 )
@@ -11,19 +12,21 @@ import (
 func ValidateRequestQueryParams(c *gin.Context) (dto.RequestQueryParams, error) {
 	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
 	if err != nil || page <= 0 {
-		return dto.RequestQueryParams{}, fmt.Errorf("Invalid page number")
+		return dto.RequestQueryParams{}, apperror.Validation(apperror.CodeValidation, "query.invalid_page", map[string]string{"page": "validation.positive_number"})
 	}
 
 	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
 	if err != nil || limit <= 0 {
-		return dto.RequestQueryParams{}, fmt.Errorf("Invalid limit value")
+		return dto.RequestQueryParams{}, apperror.Validation(apperror.CodeValidation, "query.invalid_limit", map[string]string{"limit": "validation.positive_number"})
 	}
 
-	order := c.DefaultQuery("order", "id")
+	// no order leaves it to the list, usually by id
+	order := c.Query("order")
 	sort := c.DefaultQuery("sort", "ASC")
 
 	return dto.RequestQueryParams{
 		QueryParams: dto.QueryParams{
+			Query: strings.TrimSpace(c.Query("q")),
 			Order: order,
 			Sort:  sort,
 		},
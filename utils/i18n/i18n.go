@@ -0,0 +1,100 @@
+// Package i18n renders user facing messages in the locale of the request.
+// Messages are looked up by key in the catalog of the locale, falling back to
+// English, the source locale; text that is not a key is used as it is.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	English    = "en"
+	Indonesian = "id"
+)
+
+// Supported lists the locales with a catalog.
+var Supported = []string{Indonesian, English}
+
+// Args fill the {name} placeholders of a message.
+type Args map[string]any
+
+type localeKey struct{}
+
+// WithLocale stores the locale negotiated for a request on ctx.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext returns the locale stored on ctx, English when there is none.
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return English
+}
+
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// T renders the message key in locale.
+func T(locale string, key string, args Args) string {
+	template, ok := catalogs[locale][key]
+	if !ok {
+		template, ok = catalogs[English][key]
+	}
+	if !ok {
+		template = translatePattern(locale, key)
+	}
+	return format(template, args)
+}
+
+var placeholder = regexp.MustCompile(`\{(\w+)\}`)
+
+func format(template string, args Args) string {
+	if len(args) == 0 {
+		return template
+	}
+	return placeholder.ReplaceAllStringFunc(template, func(match string) string {
+		value, ok := args[match[1:len(match)-1]]
+		if !ok {
+			return match
+		}
+		return fmt.Sprint(value)
+	})
+}
+
+type pattern struct {
+	source *regexp.Regexp
+	target string
+}
+
+// compilePatterns turns English fmt messages such as "must be no less than
+// %v" into expressions that capture the formatted values.
+func compilePatterns(translations map[string]string) []pattern {
+	patterns := make([]pattern, 0, len(translations))
+	for source, target := range translations {
+		expr := strings.ReplaceAll(regexp.QuoteMeta(source), "%v", "(.+?)")
+		patterns = append(patterns, pattern{source: regexp.MustCompile("^" + expr + "$"), target: target})
+	}
+	return patterns
+}
+
+// translatePattern translates text that was formatted before it reached us,
+// the built-in messages of ozzo-validation. Unknown text is returned as is.
+func translatePattern(locale string, text string) string {
+	for _, p := range patterns[locale] {
+		if match := p.source.FindStringSubmatch(text); match != nil {
+			values := make([]any, len(match)-1)
+			for i, value := range match[1:] {
+				values[i] = value
+			}
+			return fmt.Sprintf(p.target, values...)
+		}
+	}
+	return text
+}
@@ -0,0 +1,50 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type I18nTestSuite struct {
+	suite.Suite
+}
+
+func (suite *I18nTestSuite) TestCatalogsHaveTheSameKeysSuccess() {
+	for _, locale := range Supported {
+		for key := range catalogs[English] {
+			assert.Contains(suite.T(), catalogs[locale], key, "%s is missing from %s", key, locale)
+		}
+		assert.Len(suite.T(), catalogs[locale], len(catalogs[English]))
+	}
+}
+
+func (suite *I18nTestSuite) TestFillsPlaceholdersSuccess() {
+	assert.Equal(suite.T(), "merek dengan ID 1 tidak ditemukan", T(Indonesian, "brand.not_found", Args{"id": "1"}))
+	assert.Equal(suite.T(), "brand with ID 1 not found", T(English, "brand.not_found", Args{"id": "1"}))
+}
+
+func (suite *I18nTestSuite) TestUnknownLocaleFallsBackToEnglishSuccess() {
+	assert.Equal(suite.T(), "not enough stock", T("fr", "vehicle.out_of_stock", nil))
+}
+
+func (suite *I18nTestSuite) TestTranslatesValidationMessagesSuccess() {
+	assert.Equal(suite.T(), "tidak boleh kosong", T(Indonesian, "cannot be blank", nil))
+	assert.Equal(suite.T(), "panjang harus antara 3 dan 30", T(Indonesian, "the length must be between 3 and 30", nil))
+	assert.Equal(suite.T(), "the length must be between 3 and 30", T(English, "the length must be between 3 and 30", nil))
+}
+
+func (suite *I18nTestSuite) TestUnknownTextIsKeptSuccess() {
+	assert.Equal(suite.T(), "something went wrong", T(Indonesian, "something went wrong", nil))
+}
+
+func (suite *I18nTestSuite) TestContextDefaultsToEnglishSuccess() {
+	assert.Equal(suite.T(), English, FromContext(context.Background()))
+	assert.Equal(suite.T(), Indonesian, FromContext(WithLocale(context.Background(), Indonesian)))
+}
+
+func TestI18nTestSuite(t *testing.T) {
+	suite.Run(t, new(I18nTestSuite))
+}
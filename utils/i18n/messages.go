@@ -0,0 +1,217 @@
+package i18n
+
+var catalogs = map[string]map[string]string{
+	English: {
+		"error.internal":                 "internal server error",
+		"error.timeout":                  "request timed out",
+		"error.route_not_found":          "route not found",
+		"error.unauthorized":             "unauthorized",
+		"error.forbidden":                "forbidden",
+		"error.password_change_required": "password change required",
+		"error.validation_failed":        "validation failed",
+		"error.invalid_body":             "invalid request body",
+		"error.signed_url_invalid":       "invalid link signature",
+		"error.signed_url_expired":       "link has expired",
+		"record.not_found":               "record not found",
+		"record.already_exists":          "record already exists",
+
+		"validation.type_mismatch":        "must be a {type}",
+		"validation.positive_number":      "must be a positive number",
+		"validation.invalid_date":         "invalid date: {value}",
+		"validation.phone_number":         "must be an Indonesian phone number, e.g. 081234567890",
+		"validation.min_age":              "must be at least 17 years old",
+		"validation.version_required":     "is required to update a vehicle",
+		"validation.self_manager":         "an employee cannot manage themselves",
+		"validation.password_is_username": "must not be the same as the username",
+
+		"query.invalid_page":   "invalid page number",
+		"query.invalid_limit":  "invalid limit value",
+		"query.invalid_sort":   "invalid sort by: {sort}",
+		"query.invalid_order":  "cannot order by: {order}",
+		"query.invalid_status": "invalid status: {status}, use baru or bekas",
+		"query.invalid_range":  "must not be less than the minimum {min}",
+
+		"audit.immutable":      "audit log entries cannot be changed",
+		"audit.to_before_from": "'to' must not be before 'from'",
+
+		"brand.not_found":  "brand with ID {id} not found",
+		"brand.name_taken": "brand with name {name} already exists",
+
+		"vehicle.not_found":             "vehicle with ID {id} not found",
+		"vehicle.out_of_stock":          "not enough stock",
+		"vehicle.version_conflict":      "vehicle has been modified by another request, reload and try again",
+		"vehicle.image_required":        "an image file is required",
+		"vehicle.image_type":            "the image must be a JPEG, PNG or WebP file",
+		"vehicle.image_too_large":       "the image must not be larger than {max} MB",
+		"vehicle.image_too_many_pixels": "the image must not have more than {max} megapixels",
+		"vehicle.image_invalid":         "the image file is damaged or incomplete",
+		"vehicle.invalid_form_data":     "invalid vehicle data",
+		"vehicle.image_not_found":       "vehicle with ID {id} has no image",
+		"vehicle.too_many_images":       "a vehicle has at most {max} images",
+		"vehicle.image_order_mismatch":  "list every image of the vehicle exactly once",
+		"vehicle_image.not_found":       "image {id} of vehicle {vehicleId} not found",
+		"vehicle_image.size_not_found":  "unknown image size {size}",
+
+		"customer.not_found":         "customer with ID {id} not found",
+		"customer.email_not_found":   "customer with email {email} not found",
+		"customer.phone_not_found":   "customer with phone number {phone} not found",
+		"customer.profile_not_found": "no customer profile for user {username}",
+
+		"employee.not_found":         "employee with ID {id} not found",
+		"employee.email_not_found":   "employee with email {email} not found",
+		"employee.phone_not_found":   "employee with phone number {phone} not found",
+		"employee.profile_not_found": "no employee profile for user {username}",
+		"employee.email_taken":       "employee with email {email} already exists",
+		"employee.phone_taken":       "employee with phone number {phone} already exists",
+		"employee.invalid_role":      "invalid employee role: {role}",
+
+		"transaction.employee_required": "employee is required, {username} is not linked to an employee",
+
+		"auth.invalid_credentials":      "invalid username or password",
+		"auth.account_locked":           "account is temporarily locked",
+		"auth.account_locked_until":     "account is temporarily locked until {until}",
+		"auth.too_many_login_attempts":  "too many failed login attempts, try again later",
+		"auth.invalid_refresh_token":    "invalid refresh token",
+		"auth.refresh_token_reused":     "refresh token has already been used",
+		"auth.invalid_reset_token":      "invalid or expired reset token",
+		"auth.password_too_short":       "password must be at least {min} characters",
+		"auth.password_unchanged":       "new password must be different from the old one",
+		"auth.old_password_incorrect":   "old password is incorrect",
+		"auth.user_not_found":           "user with ID '{id}' not found",
+		"auth.username_not_found":       "username '{username}' not found",
+		"auth.username_taken":           "username '{username}' already exists",
+		"auth.invalid_role":             "invalid role: {role}",
+		"auth.registered":               "{username} has been registered.",
+		"auth.register_with_id":         "registration creates a new account, an id cannot be given",
+		"auth.username_updated":         "username has been updated",
+		"auth.activated":                "{username} has been activated.",
+		"auth.deactivated":              "{username} has been disabled.",
+		"auth.role_changed":             "{username} is now {role}.",
+		"auth.unlocked":                 "{username} has been unlocked.",
+		"auth.logged_out":               "logged out",
+		"auth.password_changed":         "password has been changed",
+		"auth.password_reset_requested": "if the account exists, a reset token has been sent",
+		"auth.password_reset":           "password has been reset",
+
+		"notification.password_reset.subject": "Password reset",
+		"notification.password_reset.body":    "Use this token to reset your password: {token}\nIt expires at {expiresAt}.",
+	},
+	Indonesian: {
+		"error.internal":                 "terjadi kesalahan pada server",
+		"error.timeout":                  "waktu permintaan habis",
+		"error.route_not_found":          "rute tidak ditemukan",
+		"error.unauthorized":             "tidak terautentikasi",
+		"error.forbidden":                "akses ditolak",
+		"error.password_change_required": "kata sandi harus diganti terlebih dahulu",
+		"error.validation_failed":        "validasi gagal",
+		"error.invalid_body":             "isi permintaan tidak valid",
+		"error.signed_url_invalid":       "tanda tangan tautan tidak valid",
+		"error.signed_url_expired":       "tautan sudah kedaluwarsa",
+		"record.not_found":               "data tidak ditemukan",
+		"record.already_exists":          "data sudah ada",
+
+		"validation.type_mismatch":        "harus bertipe {type}",
+		"validation.positive_number":      "harus berupa bilangan positif",
+		"validation.invalid_date":         "tanggal tidak valid: {value}",
+		"validation.phone_number":         "harus berupa nomor telepon Indonesia, contoh 081234567890",
+		"validation.min_age":              "usia minimal 17 tahun",
+		"validation.version_required":     "wajib diisi untuk mengubah kendaraan",
+		"validation.self_manager":         "karyawan tidak dapat menjadi manajer dirinya sendiri",
+		"validation.password_is_username": "tidak boleh sama dengan nama pengguna",
+
+		"query.invalid_page":   "nomor halaman tidak valid",
+		"query.invalid_limit":  "nilai limit tidak valid",
+		"query.invalid_sort":   "urutan tidak valid: {sort}",
+		"query.invalid_order":  "tidak dapat mengurutkan berdasarkan: {order}",
+		"query.invalid_status": "status tidak valid: {status}, gunakan baru atau bekas",
+		"query.invalid_range":  "tidak boleh kurang dari batas minimum {min}",
+
+		"audit.immutable":      "entri log audit tidak dapat diubah",
+		"audit.to_before_from": "'to' tidak boleh sebelum 'from'",
+
+		"brand.not_found":  "merek dengan ID {id} tidak ditemukan",
+		"brand.name_taken": "merek dengan nama {name} sudah ada",
+
+		"vehicle.not_found":             "kendaraan dengan ID {id} tidak ditemukan",
+		"vehicle.out_of_stock":          "stok tidak mencukupi",
+		"vehicle.version_conflict":      "kendaraan telah diubah oleh permintaan lain, muat ulang lalu coba lagi",
+		"vehicle.image_required":        "berkas gambar wajib diunggah",
+		"vehicle.image_type":            "gambar harus berupa berkas JPEG, PNG, atau WebP",
+		"vehicle.image_too_large":       "ukuran gambar tidak boleh lebih dari {max} MB",
+		"vehicle.image_too_many_pixels": "gambar tidak boleh lebih dari {max} megapiksel",
+		"vehicle.image_invalid":         "berkas gambar rusak atau tidak lengkap",
+		"vehicle.invalid_form_data":     "data kendaraan tidak valid",
+		"vehicle.image_not_found":       "kendaraan dengan ID {id} tidak memiliki gambar",
+		"vehicle.too_many_images":       "satu kendaraan paling banyak memiliki {max} gambar",
+		"vehicle.image_order_mismatch":  "sebutkan setiap gambar kendaraan tepat satu kali",
+		"vehicle_image.not_found":       "gambar {id} dari kendaraan {vehicleId} tidak ditemukan",
+		"vehicle_image.size_not_found":  "ukuran gambar {size} tidak dikenal",
+
+		"customer.not_found":         "pelanggan dengan ID {id} tidak ditemukan",
+		"customer.email_not_found":   "pelanggan dengan email {email} tidak ditemukan",
+		"customer.phone_not_found":   "pelanggan dengan nomor telepon {phone} tidak ditemukan",
+		"customer.profile_not_found": "pengguna {username} tidak memiliki profil pelanggan",
+
+		"employee.not_found":         "karyawan dengan ID {id} tidak ditemukan",
+		"employee.email_not_found":   "karyawan dengan email {email} tidak ditemukan",
+		"employee.phone_not_found":   "karyawan dengan nomor telepon {phone} tidak ditemukan",
+		"employee.profile_not_found": "pengguna {username} tidak memiliki profil karyawan",
+		"employee.email_taken":       "karyawan dengan email {email} sudah ada",
+		"employee.phone_taken":       "karyawan dengan nomor telepon {phone} sudah ada",
+		"employee.invalid_role":      "peran karyawan tidak valid: {role}",
+
+		"transaction.employee_required": "karyawan wajib diisi, {username} tidak terhubung dengan karyawan",
+
+		"auth.invalid_credentials":      "nama pengguna atau kata sandi salah",
+		"auth.account_locked":           "akun dikunci sementara",
+		"auth.account_locked_until":     "akun dikunci sementara hingga {until}",
+		"auth.too_many_login_attempts":  "terlalu banyak percobaan login yang gagal, coba lagi nanti",
+		"auth.invalid_refresh_token":    "refresh token tidak valid",
+		"auth.refresh_token_reused":     "refresh token sudah pernah digunakan",
+		"auth.invalid_reset_token":      "token reset tidak valid atau sudah kedaluwarsa",
+		"auth.password_too_short":       "kata sandi minimal {min} karakter",
+		"auth.password_unchanged":       "kata sandi baru harus berbeda dari kata sandi lama",
+		"auth.old_password_incorrect":   "kata sandi lama salah",
+		"auth.user_not_found":           "pengguna dengan ID '{id}' tidak ditemukan",
+		"auth.username_not_found":       "nama pengguna '{username}' tidak ditemukan",
+		"auth.username_taken":           "nama pengguna '{username}' sudah digunakan",
+		"auth.invalid_role":             "peran tidak valid: {role}",
+		"auth.registered":               "{username} telah terdaftar.",
+		"auth.register_with_id":         "pendaftaran membuat akun baru, id tidak boleh diisi",
+		"auth.username_updated":         "nama pengguna telah diperbarui",
+		"auth.activated":                "{username} telah diaktifkan.",
+		"auth.deactivated":              "{username} telah dinonaktifkan.",
+		"auth.role_changed":             "{username} sekarang berperan sebagai {role}.",
+		"auth.unlocked":                 "{username} telah dibuka kuncinya.",
+		"auth.logged_out":               "berhasil keluar",
+		"auth.password_changed":         "kata sandi telah diganti",
+		"auth.password_reset_requested": "jika akun terdaftar, token reset telah dikirim",
+		"auth.password_reset":           "kata sandi telah diatur ulang",
+
+		"notification.password_reset.subject": "Atur ulang kata sandi",
+		"notification.password_reset.body":    "Gunakan token ini untuk mengatur ulang kata sandi Anda: {token}\nBerlaku hingga {expiresAt}.",
+	},
+}
+
+// patterns translate the built-in ozzo-validation messages, which arrive
+// already formatted in English.
+var patterns = map[string][]pattern{
+	Indonesian: compilePatterns(map[string]string{
+		"cannot be blank":                      "tidak boleh kosong",
+		"is required":                          "wajib diisi",
+		"must be a valid value":                "harus berupa nilai yang valid",
+		"must be in a valid format":            "formatnya tidak valid",
+		"must be a valid date":                 "harus berupa tanggal yang valid",
+		"must be a valid email address":        "harus berupa alamat email yang valid",
+		"must not be in list":                  "tidak boleh berupa nilai ini",
+		"the value must be empty":              "harus kosong",
+		"the length must be between %v and %v": "panjang harus antara %v dan %v",
+		"the length must be no less than %v":   "panjang minimal %v",
+		"the length must be no more than %v":   "panjang maksimal %v",
+		"the length must be exactly %v":        "panjang harus tepat %v",
+		"must be no less than %v":              "tidak boleh kurang dari %v",
+		"must be no greater than %v":           "tidak boleh lebih dari %v",
+		"must be greater than %v":              "harus lebih dari %v",
+		"must be less than %v":                 "harus kurang dari %v",
+	}),
+}
@@ -0,0 +1,179 @@
+// Package imaging checks uploaded pictures, strips their metadata and
+// renders the smaller sizes served in place of them.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	// registers the WebP decoder with image.Decode
+	_ "golang.org/x/image/webp"
+)
+
+type Format struct {
+	ContentType string
+	Ext         string
+}
+
+var (
+	JPEG = Format{ContentType: "image/jpeg", Ext: "jpg"}
+	PNG  = Format{ContentType: "image/png", Ext: "png"}
+	WebP = Format{ContentType: "image/webp", Ext: "webp"}
+)
+
+var formats = []Format{JPEG, PNG, WebP}
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image file too large")
+	ErrTooManyPixels     = errors.New("image dimensions too large")
+	ErrInvalid           = errors.New("invalid image")
+)
+
+// Size is a rendition no larger than Max pixels on its longer side.
+type Size struct {
+	Name string
+	Max  int
+}
+
+var Sizes = []Size{
+	{Name: "thumbnail", Max: 160},
+	{Name: "medium", Max: 640},
+	{Name: "large", Max: 1280},
+}
+
+// IsSize reports whether name is one of Sizes.
+func IsSize(name string) bool {
+	for _, size := range Sizes {
+		if size.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Limits bound an upload, MaxPixels is checked before decoding so a small
+// file cannot unpack into a huge bitmap.
+type Limits struct {
+	MaxBytes  int64
+	MaxPixels int
+}
+
+// Rendition is a smaller size of an image. Its Format is the one of the
+// upload, except for WebP whose renditions are PNG as there is no encoder.
+type Rendition struct {
+	Size   Size
+	Format Format
+	Data   []byte
+}
+
+// Image is a processed upload. Data is the original without its metadata,
+// turned upright when the EXIF orientation asked for it.
+type Image struct {
+	Format     Format
+	Width      int
+	Height     int
+	Data       []byte
+	Renditions []Rendition
+}
+
+// Sniff detects the format from the content, never from the file name.
+func Sniff(data []byte) (Format, error) {
+	contentType := http.DetectContentType(data)
+	for _, format := range formats {
+		if format.ContentType == contentType {
+			return format, nil
+		}
+	}
+	return Format{}, fmt.Errorf("%w %s", ErrUnsupportedFormat, contentType)
+}
+
+// Process reads an upload within limits and renders every size smaller than
+// the image.
+func Process(r io.Reader, limits Limits) (*Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limits.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, ErrTooLarge
+	}
+	format, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if config.Width*config.Height > limits.MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	img := toRGBA(decoded)
+
+	result := &Image{Format: format}
+	switch format {
+	case JPEG:
+		orientation, err := jpegOrientation(data)
+		if err != nil {
+			return nil, err
+		}
+		if orientation > 1 {
+			// stripping the EXIF drops the orientation, so the pixels turn
+			img = orient(img, orientation)
+			result.Data, err = encode(format, img)
+		} else {
+			result.Data, err = stripJPEG(data)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case PNG:
+		if result.Data, err = stripPNG(data); err != nil {
+			return nil, err
+		}
+	case WebP:
+		if result.Data, err = stripWebP(data); err != nil {
+			return nil, err
+		}
+	}
+	result.Width, result.Height = img.Rect.Dx(), img.Rect.Dy()
+
+	renditionFormat := format
+	if format == WebP {
+		renditionFormat = PNG
+	}
+	for _, size := range Sizes {
+		if max(result.Width, result.Height) <= size.Max {
+			continue
+		}
+		rendition, err := encode(renditionFormat, downscale(img, size.Max))
+		if err != nil {
+			return nil, err
+		}
+		result.Renditions = append(result.Renditions, Rendition{Size: size, Format: renditionFormat, Data: rendition})
+	}
+	return result, nil
+}
+
+func encode(format Format, img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if format == PNG {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	}
+	return buf.Bytes(), err
+}
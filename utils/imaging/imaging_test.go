@@ -0,0 +1,183 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImagingTestSuite struct {
+	suite.Suite
+	limits Limits
+}
+
+func (suite *ImagingTestSuite) SetupTest() {
+	suite.limits = Limits{MaxBytes: 1 << 20, MaxPixels: 4_000_000}
+}
+
+// picture is left half red and right half blue.
+func picture(width int, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+			if x >= width/2 {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// withExif inserts an APP1 segment with the orientation after the SOI
+// marker of a JPEG.
+func withExif(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := binary.BigEndian.AppendUint16([]byte{0xff, 0xe1}, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func (suite *ImagingTestSuite) TestProcessPNGRenditionsSuccess() {
+	var buf bytes.Buffer
+	assert.NoError(suite.T(), png.Encode(&buf, picture(1000, 500)))
+
+	result, err := Process(&buf, suite.limits)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), PNG, result.Format)
+	assert.Equal(suite.T(), 1000, result.Width)
+	if assert.Len(suite.T(), result.Renditions, 2) {
+		thumbnail, err := png.Decode(bytes.NewReader(result.Renditions[0].Data))
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), "thumbnail", result.Renditions[0].Size.Name)
+		assert.Equal(suite.T(), image.Rect(0, 0, 160, 80), thumbnail.Bounds())
+		r, _, b, _ := thumbnail.At(150, 40).RGBA()
+		assert.Equal(suite.T(), [2]uint32{0, 0xffff}, [2]uint32{r, b})
+		assert.Equal(suite.T(), "medium", result.Renditions[1].Size.Name)
+	}
+}
+
+func (suite *ImagingTestSuite) TestProcessJPEGOrientationSuccess() {
+	var buf bytes.Buffer
+	assert.NoError(suite.T(), jpeg.Encode(&buf, picture(40, 20), nil))
+
+	result, err := Process(bytes.NewReader(withExif(buf.Bytes(), 6)), suite.limits)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), [2]int{20, 40}, [2]int{result.Width, result.Height})
+	assert.NotContains(suite.T(), string(result.Data), "Exif")
+	upright, err := jpeg.Decode(bytes.NewReader(result.Data))
+	assert.NoError(suite.T(), err)
+	// turned clockwise the red left half ends up on top
+	r, _, _, _ := upright.At(10, 5).RGBA()
+	assert.Greater(suite.T(), r, uint32(0xc000))
+	assert.Empty(suite.T(), result.Renditions)
+}
+
+func (suite *ImagingTestSuite) TestProcessJPEGStripExifSuccess() {
+	var buf bytes.Buffer
+	assert.NoError(suite.T(), jpeg.Encode(&buf, picture(40, 20), nil))
+
+	result, err := Process(bytes.NewReader(withExif(buf.Bytes(), 1)), suite.limits)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), buf.Bytes(), result.Data)
+}
+
+// lossless writes a VP8L bitstream of one color: every prefix code holds a
+// single symbol, so the pixels themselves take no bits.
+func lossless(width int, height int, c color.RGBA) []byte {
+	var data []byte
+	var acc uint64
+	var n uint
+	write := func(value uint64, bits uint) {
+		acc |= value << n
+		for n += bits; n >= 8; n -= 8 {
+			data = append(data, byte(acc))
+			acc >>= 8
+		}
+	}
+	write(0x2f, 8)
+	write(uint64(width-1), 14)
+	write(uint64(height-1), 14)
+	write(1, 1) // alpha is used
+	write(0, 3) // version
+	write(0, 1) // no transform
+	write(0, 1) // no color cache
+	write(0, 1) // no meta prefix codes
+	for _, symbol := range []uint8{c.G, c.R, c.B, c.A, 0} {
+		write(1, 1) // simple code
+		write(0, 1) // of one symbol
+		write(1, 1) // in 8 bits
+		write(uint64(symbol), 8)
+	}
+	if n > 0 {
+		data = append(data, byte(acc))
+	}
+	return data
+}
+
+func (suite *ImagingTestSuite) TestProcessWebPStripSuccess() {
+	chunk := func(chunkType string, payload []byte) []byte {
+		data := append(binary.LittleEndian.AppendUint32([]byte(chunkType), uint32(len(payload))), payload...)
+		if len(payload)%2 == 1 {
+			data = append(data, 0)
+		}
+		return data
+	}
+	vp8x := []byte{webpFlagEXIF | webpFlagXMP, 0, 0, 0, 0x1f, 0x03, 0x00, 0xdf, 0x01, 0x00} // 800x480
+	body := append(chunk("VP8X", vp8x), chunk("EXIF", []byte("secret"))...)
+	body = append(body, chunk("XMP ", []byte("<x:xmpmeta>gps</x:xmpmeta>"))...)
+	body = append(body, chunk("VP8L", lossless(800, 480, color.RGBA{B: 255, A: 255}))...)
+	data := append(binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(len(body)+4)), "WEBP"...)
+	data = append(data, body...)
+
+	result, err := Process(bytes.NewReader(data), suite.limits)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), WebP, result.Format)
+	assert.Equal(suite.T(), [2]int{800, 480}, [2]int{result.Width, result.Height})
+	assert.NotContains(suite.T(), string(result.Data), "secret")
+	assert.NotContains(suite.T(), string(result.Data), "xmpmeta")
+	assert.Equal(suite.T(), byte(0), result.Data[20]&(webpFlagEXIF|webpFlagXMP))
+	assert.Equal(suite.T(), uint32(len(result.Data)-8), binary.LittleEndian.Uint32(result.Data[4:]))
+	if assert.Len(suite.T(), result.Renditions, 2) {
+		// there is no WebP encoder, the renditions are PNG
+		assert.Equal(suite.T(), PNG, result.Renditions[0].Format)
+		thumbnail, err := png.Decode(bytes.NewReader(result.Renditions[0].Data))
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), image.Rect(0, 0, 160, 96), thumbnail.Bounds())
+		_, _, b, _ := thumbnail.At(80, 48).RGBA()
+		assert.Equal(suite.T(), uint32(0xffff), b)
+	}
+}
+
+func (suite *ImagingTestSuite) TestProcessUnsupportedFail() {
+	_, err := Process(bytes.NewReader([]byte("GIF89a\x01\x00\x01\x00")), suite.limits)
+	assert.ErrorIs(suite.T(), err, ErrUnsupportedFormat)
+}
+
+func (suite *ImagingTestSuite) TestProcessLimitsFail() {
+	var buf bytes.Buffer
+	assert.NoError(suite.T(), png.Encode(&buf, picture(1000, 500)))
+
+	suite.limits.MaxPixels = 100_000
+	_, err := Process(bytes.NewReader(buf.Bytes()), suite.limits)
+	assert.ErrorIs(suite.T(), err, ErrTooManyPixels)
+
+	suite.limits.MaxBytes = int64(buf.Len() - 1)
+	_, err = Process(bytes.NewReader(buf.Bytes()), suite.limits)
+	assert.ErrorIs(suite.T(), err, ErrTooLarge)
+}
+
+func TestImagingTestSuite(t *testing.T) {
+	suite.Run(t, new(ImagingTestSuite))
+}
@@ -0,0 +1,198 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// metadata segments and chunks dropped from uploads: EXIF and XMP carry the
+// camera, the time and often the GPS position of the picture
+var (
+	jpegMetadataMarkers = map[byte]bool{
+		0xe1: true, // APP1, EXIF and XMP
+		0xed: true, // APP13, IPTC
+		0xfe: true, // COM
+	}
+	pngMetadataChunks = map[string]bool{
+		"eXIf": true,
+		"tEXt": true,
+		"zTXt": true,
+		"iTXt": true,
+		"tIME": true,
+	}
+	webpMetadataChunks = map[string]bool{
+		"EXIF": true,
+		"XMP ": true,
+	}
+)
+
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalid}, args...)...)
+}
+
+// walkJPEG calls visit with the marker and bytes of every segment up to the
+// image data, which is passed last as one piece under the SOS marker.
+func walkJPEG(data []byte, visit func(marker byte, segment []byte)) error {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return invalid("missing jpeg start of image")
+	}
+	visit(0xd8, data[:2])
+	for pos := 2; pos < len(data); {
+		if data[pos] != 0xff || pos+1 >= len(data) {
+			return invalid("jpeg marker expected at %d", pos)
+		}
+		marker := data[pos+1]
+		switch {
+		case marker == 0xff: // fill byte
+			pos++
+		case marker == 0xda:
+			visit(marker, data[pos:])
+			return nil
+		case marker == 0x01 || marker >= 0xd0 && marker <= 0xd7:
+			visit(marker, data[pos:pos+2])
+			pos += 2
+		default:
+			if pos+4 > len(data) {
+				return invalid("truncated jpeg segment")
+			}
+			end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+			if end > len(data) {
+				return invalid("truncated jpeg segment")
+			}
+			visit(marker, data[pos:end])
+			pos = end
+		}
+	}
+	return invalid("jpeg without image data")
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	stripped := make([]byte, 0, len(data))
+	err := walkJPEG(data, func(marker byte, segment []byte) {
+		if !jpegMetadataMarkers[marker] {
+			stripped = append(stripped, segment...)
+		}
+	})
+	return stripped, err
+}
+
+// jpegOrientation reads the EXIF orientation, 1 (upright) when there is none.
+func jpegOrientation(data []byte) (int, error) {
+	orientation := 1
+	err := walkJPEG(data, func(marker byte, segment []byte) {
+		if marker != 0xe1 || len(segment) < 4 {
+			return
+		}
+		if value, ok := exifOrientation(segment[4:]); ok {
+			orientation = value
+		}
+	})
+	return orientation, err
+}
+
+// exifOrientation looks the orientation tag up in the first IFD of an APP1
+// payload.
+func exifOrientation(payload []byte) (int, bool) {
+	tiff, ok := bytes.CutPrefix(payload, []byte("Exif\x00\x00"))
+	if !ok || len(tiff) < 8 {
+		return 0, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			return value, value >= 1 && value <= 8
+		}
+	}
+	return 0, false
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, invalid("missing png signature")
+	}
+	stripped := append(make([]byte, 0, len(data)), signature...)
+	for pos := len(signature); pos < len(data); {
+		if pos+8 > len(data) {
+			return nil, invalid("truncated png chunk")
+		}
+		// length, type, data and crc
+		end := pos + 12 + int(binary.BigEndian.Uint32(data[pos:]))
+		if end > len(data) || end < pos {
+			return nil, invalid("truncated png chunk")
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		if !pngMetadataChunks[chunkType] {
+			stripped = append(stripped, data[pos:end]...)
+		}
+		if chunkType == "IEND" {
+			break
+		}
+		pos = end
+	}
+	return stripped, nil
+}
+
+// walkWebP calls visit with the type, payload and whole bytes of every
+// chunk in the RIFF container.
+func walkWebP(data []byte, visit func(chunkType string, payload []byte, chunk []byte)) error {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return invalid("missing webp header")
+	}
+	for pos := 12; pos < len(data); {
+		if pos+8 > len(data) {
+			return invalid("truncated webp chunk")
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2
+		if end > len(data) || end < pos {
+			return invalid("truncated webp chunk")
+		}
+		visit(string(data[pos:pos+4]), data[pos+8:pos+8+size], data[pos:end])
+		pos = end
+	}
+	return nil
+}
+
+func stripWebP(data []byte) ([]byte, error) {
+	stripped := append(make([]byte, 0, len(data)), data[:min(len(data), 12)]...)
+	err := walkWebP(data, func(chunkType string, payload []byte, chunk []byte) {
+		if webpMetadataChunks[chunkType] {
+			return
+		}
+		start := len(stripped)
+		stripped = append(stripped, chunk...)
+		if chunkType == "VP8X" && len(payload) > 0 {
+			stripped[start+8] &^= webpFlagEXIF | webpFlagXMP
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+	return stripped, nil
+}
@@ -0,0 +1,93 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+)
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// downscale shrinks img to fit bound on its longer side, every pixel is the
+// average of the source pixels it covers.
+func downscale(img *image.RGBA, bound int) *image.RGBA {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	dstWidth, dstHeight := bound, bound
+	if width >= height {
+		dstHeight = (height*bound + width/2) / width
+	} else {
+		dstWidth = (width*bound + height/2) / height
+	}
+	dstWidth, dstHeight = clamp(dstWidth, width), clamp(dstHeight, height)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		y0, y1 := y*height/dstHeight, (y+1)*height/dstHeight
+		for x := 0; x < dstWidth; x++ {
+			x0, x1 := x*width/dstWidth, (x+1)*width/dstWidth
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				i := img.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					sum[0] += int(img.Pix[i])
+					sum[1] += int(img.Pix[i+1])
+					sum[2] += int(img.Pix[i+2])
+					sum[3] += int(img.Pix[i+3])
+					i += 4
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			o := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[o+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+func clamp(n int, upper int) int {
+	return min(max(n, 1), upper)
+}
+
+// orient turns img upright for an EXIF orientation from 2 to 8.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // flip horizontally
+				dx, dy = width-1-x, y
+			case 3: // rotate 180°
+				dx, dy = width-1-x, height-1-y
+			case 4: // flip vertically
+				dx, dy = x, height-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90° clockwise
+				dx, dy = height-1-y, x
+			case 7: // transverse
+				dx, dy = height-1-y, width-1-x
+			case 8: // rotate 90° counterclockwise
+				dx, dy = y, width-1-x
+			default:
+				dx, dy = x, y
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], img.Pix[img.PixOffset(x, y):img.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
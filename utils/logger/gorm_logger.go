@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type gormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewGormLogger sends GORM's output through the request-scoped logger, so SQL
+// errors and slow queries carry the request_id of the call that ran them.
+func NewGormLogger(level gormlogger.LogLevel, slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{level: level, slowThreshold: slowThreshold}
+}
+
+func (g *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *g
+	copied.level = level
+	return &copied
+}
+
+func (g *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= gormlogger.Info {
+		FromContext(ctx).Infof(msg, args...)
+	}
+}
+
+func (g *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= gormlogger.Warn {
+		FromContext(ctx).Warnf(msg, args...)
+	}
+}
+
+func (g *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= gormlogger.Error {
+		FromContext(ctx).Errorf(msg, args...)
+	}
+}
+
+func (g *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if g.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	entry := FromContext(ctx).WithFields(logrus.Fields{
+		"sql":        sql,
+		"rows":       rows,
+		FieldLatency: float64(elapsed.Microseconds()) / 1000,
+	})
+	switch {
+	case err != nil && g.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		entry.WithError(err).Error("query failed")
+	case g.slowThreshold > 0 && elapsed > g.slowThreshold && g.level >= gormlogger.Warn:
+		entry.Warn("slow query")
+	case g.level >= gormlogger.Info:
+		entry.Debug("query")
+	}
+}
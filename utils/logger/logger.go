@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Field names shared by every log line, so request logs and application logs
+// can be joined on request_id.
+const (
+	FieldRequestID = "request_id"
+	FieldUser      = "user"
+	FieldMethod    = "method"
+	FieldRoute     = "route"
+	FieldStatus    = "status"
+	FieldLatency   = "latency_ms"
+	FieldBytes     = "bytes"
+)
+
+type loggerContextKey struct{}
+
+// fallback is used by code running outside a request, such as the CLI and
+// background work, and before New has been called.
+var fallback = logrus.NewEntry(New(os.Stderr, logrus.InfoLevel))
+
+// New returns a logger that writes one JSON object per line.
+func New(output io.Writer, level logrus.Level) *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(output)
+	log.SetLevel(level)
+	log.SetFormatter(&logrus.JSONFormatter{
+		TimestampFormat: time.RFC3339Nano,
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime: "time",
+			logrus.FieldKeyMsg:  "message",
+		},
+	})
+	return log
+}
+
+// SetDefault makes log the logger FromContext returns when ctx carries none.
+func SetDefault(log *logrus.Logger) {
+	fallback = logrus.NewEntry(log)
+}
+
+// WithContext stores a request-scoped logger on ctx.
+func WithContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, entry)
+}
+
+// FromContext returns the logger stored on ctx, carrying the request fields,
+// or the default logger.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(loggerContextKey{}).(*logrus.Entry); ok {
+			return entry.WithContext(ctx)
+		}
+	}
+	return fallback.WithContext(ctx)
+}
+
+// WithFields adds fields to the logger on ctx for everything logged later in
+// the same request.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return WithContext(ctx, FromContext(ctx).WithFields(fields))
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type LoggerTestSuite struct {
+	suite.Suite
+	output *bytes.Buffer
+	ctx    context.Context
+}
+
+func (suite *LoggerTestSuite) SetupTest() {
+	suite.output = &bytes.Buffer{}
+	log := New(suite.output, logrus.DebugLevel)
+	suite.ctx = WithContext(context.Background(), log.WithField(FieldRequestID, "req-1"))
+}
+
+func (suite *LoggerTestSuite) lastLine() map[string]interface{} {
+	lines := bytes.Split(bytes.TrimSpace(suite.output.Bytes()), []byte("\n"))
+	line := map[string]interface{}{}
+	assert.NoError(suite.T(), json.Unmarshal(lines[len(lines)-1], &line))
+	return line
+}
+
+func (suite *LoggerTestSuite) TestFromContextKeepsRequestFieldsSuccess() {
+	ctx := WithFields(suite.ctx, logrus.Fields{FieldUser: "admin"})
+	FromContext(ctx).Info("stock updated")
+
+	line := suite.lastLine()
+	assert.Equal(suite.T(), "req-1", line[FieldRequestID])
+	assert.Equal(suite.T(), "admin", line[FieldUser])
+	assert.Equal(suite.T(), "stock updated", line["message"])
+}
+
+func (suite *LoggerTestSuite) TestGormLoggerFailedQuerySuccess() {
+	gormLog := NewGormLogger(gormlogger.Warn, time.Second)
+	gormLog.Trace(suite.ctx, time.Now(), func() (string, int64) {
+		return `SELECT * FROM "mst_brand"`, 0
+	}, errors.New("relation does not exist"))
+
+	line := suite.lastLine()
+	assert.Equal(suite.T(), "req-1", line[FieldRequestID])
+	assert.Equal(suite.T(), "error", line["level"])
+	assert.Equal(suite.T(), `SELECT * FROM "mst_brand"`, line["sql"])
+}
+
+func TestLoggerTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggerTestSuite))
+}
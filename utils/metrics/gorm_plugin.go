@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startTimeKey = "metrics:start_time"
+
+type gormPlugin struct{}
+
+// NewGormPlugin times every GORM statement into DBQueryDuration.
+func NewGormPlugin() gorm.Plugin {
+	return &gormPlugin{}
+}
+
+func (p *gormPlugin) Name() string {
+	return "metrics"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("metrics:before_create", p.start); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("metrics:after_create", p.observe("create")); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("metrics:before_query", p.start); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("metrics:after_query", p.observe("query")); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("metrics:before_update", p.start); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("metrics:after_update", p.observe("update")); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("metrics:before_delete", p.start); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register("metrics:after_delete", p.observe("delete")); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("metrics:before_row", p.start); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("metrics:after_row", p.observe("row")); err != nil {
+		return err
+	}
+	if err := db.Callback().Raw().Before("gorm:raw").Register("metrics:before_raw", p.start); err != nil {
+		return err
+	}
+	return db.Callback().Raw().After("gorm:raw").Register("metrics:after_raw", p.observe("raw"))
+}
+
+func (p *gormPlugin) start(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+func (p *gormPlugin) observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		status := "ok"
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			status = "error"
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		DBQueryDuration.WithLabelValues(operation, table, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "shm"
+
+// Registry holds every collector served on /metrics. A private registry keeps
+// metrics from imported libraries out unless they are added here.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route template, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DeprecatedRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_deprecated_requests_total",
+		Help:      "Requests to deprecated routes, by method and route template.",
+	}, []string{"method", "route"})
+
+	HTTPRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests currently being served.",
+	})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of GORM statements by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "status"})
+
+	TransactionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_total",
+		Help:      "Vehicle sales registered, by transaction type (online/offline).",
+	}, []string{"type"})
+
+	RevenueTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "revenue_total",
+		Help:      "Sum of PaymentAmount of registered transactions, by transaction type.",
+	}, []string{"type"})
+
+	StockOutsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "vehicle_stock_outs_total",
+		Help:      "Stock updates and sales rejected because the vehicle did not have enough stock.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		HTTPRequestsInFlight,
+		DeprecatedRequestsTotal,
+		DBQueryDuration,
+		TransactionsTotal,
+		RevenueTotal,
+		StockOutsTotal,
+	)
+}
+
+// RegisterDB exports the pool statistics of db (open, idle and in-use
+// connections, waits) under the given database name.
+func RegisterDB(db *sql.DB, name string) error {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, name))
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Message struct {
+	Recipient string
+	Subject   string
+	Body      string
+}
+
+// Notifier delivers messages to users. The log and file notifiers are meant
+// for local development; a mail or SMS notifier can be plugged in instead.
+type Notifier interface {
+	Send(message Message) error
+}
+
+type logNotifier struct {
+	log *logrus.Logger
+}
+
+func (l *logNotifier) Send(message Message) error {
+	l.log.WithFields(logrus.Fields{
+		"recipient": message.Recipient,
+		"subject":   message.Subject,
+	}).Info(message.Body)
+	return nil
+}
+
+func NewLogNotifier(log *logrus.Logger) Notifier {
+	return &logNotifier{log: log}
+}
+
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (f *fileNotifier) Send(message Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "[%s] To: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), message.Recipient, message.Subject, message.Body)
+	return err
+}
+
+func NewFileNotifier(path string) Notifier {
+	return &fileNotifier{path: path}
+}
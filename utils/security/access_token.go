@@ -1,39 +1,72 @@
 package security
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/fajritsaniy/golang-SHM/config"
 	"github.com/fajritsaniy/golang-SHM/model"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 type AccessToken interface {
-	CreateAccessToken(cred *model.UserCredential) (string, error)
-	VerifyAccessToken(tokenString string) (jwt.MapClaims, error)
+	CreateAccessToken(principal model.Principal) (SignedToken, error)
+	CreateRefreshToken(principal model.Principal, familyID string) (SignedToken, error)
+	VerifyAccessToken(ctx context.Context, tokenString string) (jwt.MapClaims, error)
+	VerifyRefreshToken(tokenString string) (jwt.MapClaims, error)
+}
+
+// RevocationStore reports whether a token ID (jti) was revoked before it
+// expired, e.g. on logout.
+type RevocationStore interface {
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+type SignedToken struct {
+	Token     string
+	TokenID   string
+	ExpiresAt time.Time
 }
 
 type accessToken struct {
-	cfg config.TokenConfig
+	cfg         config.TokenConfig
+	revocations RevocationStore
 }
 
-func NewAccessToken(config config.TokenConfig) AccessToken {
+func NewAccessToken(config config.TokenConfig, revocations RevocationStore) AccessToken {
 	return &accessToken{
-		cfg: config,
+		cfg:         config,
+		revocations: revocations,
 	}
 }
 
-func (t *accessToken) CreateAccessToken(cred *model.UserCredential) (string, error) {
+func (t *accessToken) CreateAccessToken(principal model.Principal) (SignedToken, error) {
+	return t.sign(principal, AccessTokenType, "", t.cfg.AccessTokenLifeTime)
+}
+
+func (t *accessToken) CreateRefreshToken(principal model.Principal, familyID string) (SignedToken, error) {
+	return t.sign(principal, RefreshTokenType, familyID, t.cfg.RefreshTokenLifeTime)
+}
+
+func (t *accessToken) sign(principal model.Principal, tokenType string, familyID string, lifeTime time.Duration) (SignedToken, error) {
 	now := time.Now().UTC()
-	end := now.Add(t.cfg.AccessTokenLifeTime)
+	end := now.Add(lifeTime)
 	claims := MyClaims{
 		StandardClaims: jwt.StandardClaims{
-			Issuer: t.cfg.ApplicationName,
+			Issuer:  t.cfg.ApplicationName,
+			Id:      uuid.New().String(),
+			Subject: principal.UserID,
 		},
-		Username: cred.UserName,
-		Email:    cred.UserName,
+		Username:           principal.Username,
+		Email:              principal.Username,
+		Role:               principal.Role,
+		EmployeeID:         principal.EmployeeID,
+		CustomerID:         principal.CustomerID,
+		MustChangePassword: principal.MustChangePassword,
+		TokenType:          tokenType,
+		FamilyID:           familyID,
 	}
 	claims.IssuedAt = now.Unix()
 	claims.ExpiresAt = end.Unix()
@@ -41,10 +74,35 @@ func (t *accessToken) CreateAccessToken(cred *model.UserCredential) (string, err
 		t.cfg.JwtSigningMethod,
 		claims,
 	)
-	return token.SignedString([]byte(t.cfg.JwtSignatureKey))
+	tokenString, err := token.SignedString([]byte(t.cfg.JwtSignatureKey))
+	if err != nil {
+		return SignedToken{}, err
+	}
+	return SignedToken{Token: tokenString, TokenID: claims.Id, ExpiresAt: end}, nil
+}
+
+func (t *accessToken) VerifyAccessToken(ctx context.Context, tokenString string) (jwt.MapClaims, error) {
+	claims, err := t.verify(tokenString, AccessTokenType)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenID, _ := claims["jti"].(string)
+	revoked, err := t.revocations.IsTokenRevoked(ctx, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("token has been revoked")
+	}
+	return claims, nil
+}
+
+func (t *accessToken) VerifyRefreshToken(tokenString string) (jwt.MapClaims, error) {
+	return t.verify(tokenString, RefreshTokenType)
 }
 
-func (t *accessToken) VerifyAccessToken(tokenString string) (jwt.MapClaims, error) {
+func (t *accessToken) verify(tokenString string, tokenType string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if method, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("signing method invalid")
@@ -54,10 +112,28 @@ func (t *accessToken) VerifyAccessToken(tokenString string) (jwt.MapClaims, erro
 
 		return []byte(t.cfg.JwtSignatureKey), nil
 	})
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid || claims["iss"] != t.cfg.ApplicationName {
-		log.Println("Token Invalid")
+	if err != nil {
 		return nil, err
 	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || claims["iss"] != t.cfg.ApplicationName || claims["TokenType"] != tokenType {
+		return nil, fmt.Errorf("invalid %s token", tokenType)
+	}
 	return claims, nil
 }
+
+// PrincipalFromClaims maps verified token claims to the caller they describe.
+func PrincipalFromClaims(claims jwt.MapClaims) model.Principal {
+	principal := model.Principal{}
+	principal.UserID, _ = claims["sub"].(string)
+	principal.Username, _ = claims["Username"].(string)
+	principal.Role, _ = claims["Role"].(string)
+	principal.EmployeeID, _ = claims["EmployeeID"].(string)
+	principal.CustomerID, _ = claims["CustomerID"].(string)
+	principal.MustChangePassword, _ = claims["MustChangePassword"].(bool)
+	principal.TokenID, _ = claims["jti"].(string)
+	if exp, ok := claims["exp"].(float64); ok {
+		principal.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	return principal
+}
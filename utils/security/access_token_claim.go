@@ -2,8 +2,19 @@ package security
 
 import "github.com/golang-jwt/jwt"
 
+const (
+	AccessTokenType  = "access"
+	RefreshTokenType = "refresh"
+)
+
 type MyClaims struct {
 	jwt.StandardClaims
-	Username string `json:"Username"`
-	Email    string `json:"Email"`
+	Username           string `json:"Username"`
+	Email              string `json:"Email"`
+	Role               string `json:"Role"`
+	EmployeeID         string `json:"EmployeeID,omitempty"`
+	CustomerID         string `json:"CustomerID,omitempty"`
+	MustChangePassword bool   `json:"MustChangePassword,omitempty"`
+	TokenType          string `json:"TokenType"`
+	FamilyID           string `json:"FamilyID,omitempty"`
 }
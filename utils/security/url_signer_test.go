@@ -0,0 +1,53 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type URLSignerTestSuite struct {
+	suite.Suite
+	now    time.Time
+	signer *urlSigner
+}
+
+func (suite *URLSignerTestSuite) SetupTest() {
+	suite.now = time.Date(2026, time.October, 17, 10, 5, 0, 0, time.UTC)
+	suite.signer = NewURLSigner("secret", time.Hour).(*urlSigner)
+	suite.signer.now = func() time.Time { return suite.now }
+}
+
+func (suite *URLSignerTestSuite) TestVerifySuccess() {
+	query := suite.signer.Sign("/vehicles/1/image")
+	assert.NoError(suite.T(), suite.signer.Verify("/vehicles/1/image", query))
+}
+
+func (suite *URLSignerTestSuite) TestSignStableWithinStepSuccess() {
+	first := suite.signer.Sign("/vehicles/1/image")
+	suite.now = suite.now.Add(5 * time.Minute)
+	assert.Equal(suite.T(), first, suite.signer.Sign("/vehicles/1/image"))
+}
+
+func (suite *URLSignerTestSuite) TestVerifyOtherPathFail() {
+	query := suite.signer.Sign("/vehicles/1/image")
+	assert.ErrorIs(suite.T(), suite.signer.Verify("/vehicles/2/image", query), ErrURLSignatureInvalid)
+}
+
+func (suite *URLSignerTestSuite) TestVerifyTamperedExpiryFail() {
+	query := suite.signer.Sign("/vehicles/1/image")
+	query.Set(ExpiresParam, "4102444800")
+	assert.ErrorIs(suite.T(), suite.signer.Verify("/vehicles/1/image", query), ErrURLSignatureInvalid)
+}
+
+func (suite *URLSignerTestSuite) TestVerifyExpiredFail() {
+	query := suite.signer.Sign("/vehicles/1/image")
+	suite.now = suite.now.Add(2 * time.Hour)
+	assert.ErrorIs(suite.T(), suite.signer.Verify("/vehicles/1/image", query), ErrURLExpired)
+}
+
+func TestURLSignerTestSuite(t *testing.T) {
+	suite.Run(t, new(URLSignerTestSuite))
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fajritsaniy/golang-SHM/utils/common"
+)
+
+type localStorage struct {
+	root string
+}
+
+func (l *localStorage) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file and renames it over the old object, so
+// readers never see a half written file and a smaller replacement leaves
+// nothing of the old content behind.
+func (l *localStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	location, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(location)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), location)
+}
+
+func (l *localStorage) Open(ctx context.Context, key string) (*Object, error) {
+	location, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(location)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, ErrNotFound
+	}
+	return &Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: contentTypeOf(key, ""),
+		ModTime:     info.ModTime(),
+		ETag:        fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()),
+		Body:        file,
+	}, nil
+}
+
+func (l *localStorage) Delete(ctx context.Context, key string) error {
+	location, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(location); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *localStorage) Ping(ctx context.Context) error {
+	return common.CheckWritableDir(l.root)
+}
+
+// NewLocal keeps objects as files below root, one directory per key prefix.
+func NewLocal(root string) Storage {
+	return &localStorage{root: root}
+}
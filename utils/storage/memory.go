@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+type memoryObject struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+	etag        string
+}
+
+type memoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+func (m *memoryStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = memoryObject{
+		data:        data,
+		contentType: contentTypeOf(key, contentType),
+		modTime:     time.Now(),
+		etag:        fmt.Sprintf(`"%x"`, md5.Sum(data)),
+	}
+	return nil
+}
+
+func (m *memoryStorage) Open(ctx context.Context, key string) (*Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	object, ok := m.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	// data is never written after Put, readers can share it
+	return &Object{
+		Key:         key,
+		Size:        int64(len(object.data)),
+		ContentType: object.contentType,
+		ModTime:     object.modTime,
+		ETag:        object.etag,
+		Body:        nopCloser{bytes.NewReader(object.data)},
+	}, nil
+}
+
+func (m *memoryStorage) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memoryStorage) Ping(ctx context.Context) error {
+	return nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// NewMemory keeps objects in memory, for tests and throwaway setups. Every
+// call returns an empty store.
+func NewMemory() Storage {
+	return &memoryStorage{objects: map[string]memoryObject{}}
+}
@@ -0,0 +1,356 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/config"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	amzDateFormat    = "20060102T150405Z"
+	defaultS3Region  = "us-east-1"
+)
+
+// s3Storage talks to the S3 REST API directly, signing requests with
+// Signature Version 4. It only needs single object calls, which keeps it
+// small enough not to pull in an SDK.
+type s3Storage struct {
+	client    *http.Client
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	now       func() time.Time
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	if size < 0 {
+		// S3 needs the length upfront
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		body, size = bytes.NewReader(data), int64(len(data))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentTypeOf(key, contentType))
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *s3Storage) Open(ctx context.Context, key string) (*Object, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &Object{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: contentTypeOf(key, resp.Header.Get("Content-Type")),
+		ModTime:     modTime,
+		ETag:        resp.Header.Get("ETag"),
+		Body:        &s3Reader{ctx: ctx, storage: s, key: key, size: resp.ContentLength},
+	}, nil
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Ping checks that the bucket exists and the credentials are accepted.
+func (s *s3Storage) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.bucketURL(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *s3Storage) bucketURL() string {
+	endpoint := *s.endpoint
+	if s.pathStyle {
+		endpoint.Path = "/" + s.bucket
+	} else {
+		endpoint.Host = s.bucket + "." + endpoint.Host
+		endpoint.Path = "/"
+	}
+	return endpoint.String()
+}
+
+func (s *s3Storage) objectURL(key string) string {
+	return strings.TrimSuffix(s.bucketURL(), "/") + "/" + escapePath(key)
+}
+
+// do signs and sends req. Answers outside 2xx are errors, 404 is
+// ErrNotFound.
+func (s *s3Storage) do(req *http.Request) (*http.Response, error) {
+	s.sign(req)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, fmt.Errorf("s3 %s %s: %s %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(detail)))
+}
+
+// sign adds the Signature Version 4 Authorization header. The payload is not
+// hashed, so bodies are streamed instead of read twice.
+func (s *s3Storage) sign(req *http.Request) {
+	now := s.now().UTC()
+	amzDate := now.Format(amzDateFormat)
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") || name == "content-type" || name == "content-md5" || name == "range" {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{signingAlgorithm, amzDate, scope, hexSHA256(canonicalRequest)}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, s.accessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, escape(key, true)+"="+escape(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func escapePath(key string) string {
+	return escape(key, false)
+}
+
+// escape percent-encodes everything but the unreserved characters of RFC
+// 3986, as Signature Version 4 expects. Slashes are kept in paths.
+func escape(value string, encodeSlash bool) string {
+	var escaped strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '.', b == '_', b == '~', b == '/' && !encodeSlash:
+			escaped.WriteByte(b)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// s3Reader reads an object with ranged GETs. Nothing is requested until the
+// first Read, and a Seek drops the open response, so serving a range only
+// transfers that range.
+type s3Reader struct {
+	ctx     context.Context
+	storage *s3Storage
+	key     string
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+}
+
+func (r *s3Reader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, r.storage.objectURL(r.key), nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(r.offset, 10)+"-")
+		resp, err := r.storage.do(req)
+		if err != nil {
+			return 0, err
+		}
+		r.body = resp.Body
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *s3Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("s3 %s: negative position", r.key)
+	}
+	if offset != r.offset {
+		r.Close()
+		r.offset = offset
+	}
+	return offset, nil
+}
+
+func (r *s3Reader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+// NewS3 stores objects in an S3 compatible bucket, AWS or e.g. MinIO.
+func NewS3(cfg config.S3Config) (Storage, error) {
+	var missing []string
+	for name, value := range map[string]string{
+		"S3_ENDPOINT":          cfg.Endpoint,
+		"S3_BUCKET":            cfg.Bucket,
+		"S3_ACCESS_KEY_ID":     cfg.AccessKeyID,
+		"S3_SECRET_ACCESS_KEY": cfg.SecretAccessKey,
+	} {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("the s3 storage needs %s", strings.Join(missing, ", "))
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3_ENDPOINT %q, expected e.g. https://s3.amazonaws.com", cfg.Endpoint)
+	}
+	region := cfg.Region
+	if region == "" {
+		region = defaultS3Region
+	}
+	return &s3Storage{
+		client:    &http.Client{Timeout: 5 * time.Minute},
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKeyID,
+		secretKey: cfg.SecretAccessKey,
+		pathStyle: cfg.PathStyle,
+		now:       time.Now,
+	}, nil
+}
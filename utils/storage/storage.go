@@ -0,0 +1,100 @@
+// Package storage keeps uploaded files in an object store. Objects are
+// addressed by slash separated keys such as "vehicles/<id>.jpg" that mean the
+// same on every backend, so records can store the key and survive a move
+// from local disk to S3.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/fajritsaniy/golang-SHM/config"
+)
+
+const (
+	DriverLocal  = "local"
+	DriverMemory = "memory"
+	DriverS3     = "s3"
+
+	defaultContentType = "application/octet-stream"
+)
+
+var (
+	ErrNotFound   = errors.New("storage: object not found")
+	ErrInvalidKey = errors.New("storage: invalid object key")
+)
+
+// Object is a stored file opened for reading.
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+	// ETag changes whenever the content does, quoted as in the HTTP header.
+	ETag string
+	// Body can seek, so a range is read without reading what comes before it.
+	Body io.ReadSeekCloser
+}
+
+type Storage interface {
+	// Put stores body under key, replacing what was there. Size is the length
+	// of body, or -1 when unknown.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Open returns ErrNotFound for a key nothing is stored under. The caller
+	// closes the body.
+	Open(ctx context.Context, key string) (*Object, error)
+	// Delete removes the object, a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// Ping checks that the backend is reachable, for the readiness probe. The
+	// local storage checks that its directory is writable, S3 only that the
+	// bucket exists and the credentials are accepted.
+	Ping(ctx context.Context) error
+}
+
+// New returns the backend chosen by cfg.StorageDriver, local by default.
+func New(cfg config.FileConfig) (Storage, error) {
+	switch cfg.StorageDriver {
+	case "", DriverLocal:
+		if cfg.UploadLocation == "" {
+			return nil, fmt.Errorf("UPLOAD_LOCATION is required for the local storage")
+		}
+		return NewLocal(cfg.UploadLocation), nil
+	case DriverMemory:
+		return NewMemory(), nil
+	case DriverS3:
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// ValidateKey rejects keys that are empty, absolute or climb out of the
+// store with "..".
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.HasSuffix(key, "/") || path.Clean(key) != key {
+		return fmt.Errorf("%w %q", ErrInvalidKey, key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." || segment == "." {
+			return fmt.Errorf("%w %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// contentTypeOf falls back to the type of the key extension.
+func contentTypeOf(key string, contentType string) string {
+	if contentType != "" {
+		return contentType
+	}
+	if byExtension := mime.TypeByExtension(path.Ext(key)); byExtension != "" {
+		return byExtension
+	}
+	return defaultContentType
+}
@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+type gormPlugin struct{}
+
+// NewGormPlugin turns every GORM statement into a span under the span on the
+// statement context, so repositories have to use db.WithContext(ctx).
+func NewGormPlugin() gorm.Plugin {
+	return &gormPlugin{}
+}
+
+func (p *gormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tracing:before_create", p.start("create")); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("tracing:after_create", p.end); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tracing:before_query", p.start("select")); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("tracing:after_query", p.end); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tracing:before_update", p.start("update")); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("tracing:after_update", p.end); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("tracing:before_delete", p.start("delete")); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register("tracing:after_delete", p.end); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tracing:before_row", p.start("row")); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("tracing:after_row", p.end); err != nil {
+		return err
+	}
+	if err := db.Callback().Raw().Before("gorm:raw").Register("tracing:before_raw", p.start("raw")); err != nil {
+		return err
+	}
+	return db.Callback().Raw().After("gorm:raw").Register("tracing:after_raw", p.end)
+}
+
+func (p *gormPlugin) start(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			// no request span to hang it from, skip rather than start a new trace
+			return
+		}
+		name := "db." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperation(operation),
+				semconv.DBSQLTable(db.Statement.Table),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *gormPlugin) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(
+		semconv.DBStatement(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fajritsaniy/golang-SHM/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type GormPluginTestSuite struct {
+	suite.Suite
+	DB       *gorm.DB
+	mock     sqlmock.Sqlmock
+	recorder *tracetest.SpanRecorder
+}
+
+func (suite *GormPluginTestSuite) SetupTest() {
+	db, mock, err := sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.mock = mock
+	suite.DB, err = gorm.Open(postgres.New(postgres.Config{Conn: db}))
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.DB.Use(NewGormPlugin()))
+
+	suite.recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(suite.recorder)))
+}
+
+func (suite *GormPluginTestSuite) TestQueryIsChildSpanSuccess() {
+	suite.mock.ExpectQuery(`SELECT \* FROM "mst_brand"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "Honda"))
+
+	ctx, parent := Start(context.Background(), "BrandRepository.Get")
+	var brand model.Brand
+	err := suite.DB.WithContext(ctx).First(&brand, "id=?", "1").Error
+	parent.End()
+	assert.NoError(suite.T(), err)
+
+	spans := suite.recorder.Ended()
+	assert.Len(suite.T(), spans, 2)
+	assert.Equal(suite.T(), "db.select mst_brand", spans[0].Name())
+	assert.Equal(suite.T(), parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func (suite *GormPluginTestSuite) TestQueryWithoutSpanIsNotTracedSuccess() {
+	suite.mock.ExpectQuery(`SELECT \* FROM "mst_brand"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "Honda"))
+
+	var brand model.Brand
+	err := suite.DB.First(&brand, "id=?", "1").Error
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.recorder.Ended())
+}
+
+func TestGormPluginTestSuite(t *testing.T) {
+	suite.Run(t, new(GormPluginTestSuite))
+}